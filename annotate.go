@@ -0,0 +1,234 @@
+package json
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// MarshalAnnotated marshals the value (see Marshal) as indented JSONC
+// (JSON with comments) guided by the JSON Schema to make generated
+// example and configuration documents self-documenting. Object keys are
+// ordered as the properties of the schema (followed by any others,
+// sorted) and every value that is deprecated, must be one of an enum,
+// or is not in the schema at all is annotated with a trailing comment:
+//
+//     {
+//       "name": "web",
+//       "level": "info", // one of: "debug", "info", "error"
+//       "legacy": true, // deprecated
+//       "extra": 1 // unknown
+//     }
+//
+// References ($ref to #/...) and the properties of allOf are followed.
+// Keys not in the properties (of schemas with any) are unknown unless
+// allowed by an additionalProperties schema (and are not allowed if it
+// is false).
+func MarshalAnnotated(v any, schema []byte) ([]byte, error) {
+	g, err := generic(v)
+	if err != nil {
+		return nil, err
+	}
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		return nil, err
+	}
+	a := newAnnotator(schema, s)
+	a.value(g, s, Path{}, ``, 0, false)
+	return a.buf.Bytes(), nil
+}
+
+// newAnnotator returns an annotator for the (valid) schema and its
+// generic form.
+func newAnnotator(schema []byte, s any) *annotator {
+	sp := &spanner{buf: schema, vals: map[string]span{}, keys: map[string]span{}}
+	if len(schema) >= 3 && string(schema[:3]) == "\xEF\xBB\xBF" {
+		sp.i = 3
+	}
+	sp.value(Path{})
+	return &annotator{root: s, keys: sp.keys}
+}
+
+// annotator writes values annotated by their schemas (see
+// MarshalAnnotated). The key spans of the schema give the order of
+// its properties.
+type annotator struct {
+	buf   bytes.Buffer
+	root  any
+	keys  map[string]span
+	plain bool // order keys only (no annotations)
+}
+
+// resolve returns the schema (and its Path within the root) after
+// following any references.
+func (a *annotator) resolve(s any, at Path) (map[string]any, Path) {
+	for n := 0; n < maxDepth; n++ {
+		m, is := s.(map[string]any)
+		if !is {
+			return nil, at
+		}
+		ref, is := m[`$ref`].(string)
+		if !is || !strings.HasPrefix(ref, `#`) {
+			return m, at
+		}
+		p, err := resolvePointer(a.root, ref[1:])
+		if err != nil {
+			return m, at
+		}
+		target, err := getIn(a.root, p)
+		if err != nil {
+			return m, at
+		}
+		s, at = target, p
+	}
+	return nil, at
+}
+
+// properties returns the properties of the schema (including those of
+// allOf) in the order they appear in the schema.
+func (a *annotator) properties(s map[string]any, at Path) ([]string, map[string]any, map[string]Path) {
+	var names []string
+	props := map[string]any{}
+	paths := map[string]Path{}
+	var collect func(s map[string]any, at Path, depth int)
+	collect = func(s map[string]any, at Path, depth int) {
+		if s == nil || depth > maxDepth {
+			return
+		}
+		if m, is := s[`properties`].(map[string]any); is {
+			pp := at.Key(`properties`)
+			keys := sortedAnyKeys(m)
+			sort.SliceStable(keys, func(i, j int) bool {
+				return a.keys[pp.Key(keys[i]).String()].start < a.keys[pp.Key(keys[j]).String()].start
+			})
+			for _, k := range keys {
+				if _, has := props[k]; !has {
+					names = append(names, k)
+				}
+				props[k] = m[k]
+				paths[k] = pp.Key(k)
+			}
+		}
+		if all, is := s[`allOf`].([]any); is {
+			for i, sub := range all {
+				rs, rp := a.resolve(sub, at.Key(`allOf`).Index(i))
+				collect(rs, rp, depth+1)
+			}
+		}
+	}
+	collect(s, at, 0)
+	return names, props, paths
+}
+
+// note returns the annotation (if any) of the value for the schema.
+func (a *annotator) note(s map[string]any) string {
+	if a.plain {
+		return ``
+	}
+	var notes []string
+	if s[`deprecated`] == true {
+		notes = append(notes, `deprecated`)
+	}
+	if enum, is := s[`enum`].([]any); is {
+		vals := make([]string, len(enum))
+		for i, e := range enum {
+			vals[i] = This{e}.String()
+		}
+		notes = append(notes, `one of: `+strings.Join(vals, `, `))
+	}
+	return strings.Join(notes, `; `)
+}
+
+// value writes the value (with the schema at the Path) indented to the
+// depth, followed by a comma (if not last) and any annotation.
+func (a *annotator) value(v, schema any, at Path, note string, depth int, comma bool) {
+	s, at := a.resolve(schema, at)
+	if n := a.note(s); n != `` {
+		if note != `` {
+			note += `; `
+		}
+		note += n
+	}
+	end := func() {
+		if comma {
+			a.buf.WriteByte(',')
+		}
+		if note != `` {
+			a.buf.WriteString(` // ` + note)
+		}
+	}
+	indent := strings.Repeat(`  `, depth+1)
+
+	switch t := v.(type) {
+
+	case map[string]any:
+		if len(t) == 0 {
+			break
+		}
+		a.buf.WriteByte('{')
+		if note != `` {
+			a.buf.WriteString(` // ` + note)
+		}
+		names, props, paths := a.properties(s, at)
+		keys := make([]string, 0, len(t))
+		for _, k := range names {
+			if _, has := t[k]; has {
+				keys = append(keys, k)
+			}
+		}
+		for _, k := range sortedAnyKeys(t) {
+			if _, has := props[k]; !has {
+				keys = append(keys, k)
+			}
+		}
+		for i, k := range keys {
+			a.buf.WriteString("\n" + indent + quote(k) + `: `)
+			sub, subat, subnote := props[k], paths[k], ``
+			if _, has := props[k]; !has {
+				sub, subat = nil, nil
+				switch add := s[`additionalProperties`].(type) {
+				case nil:
+					if len(props) > 0 && !a.plain {
+						subnote = `unknown`
+					}
+				case map[string]any:
+					sub, subat = add, at.Key(`additionalProperties`)
+				case bool:
+					if add {
+						break
+					}
+					if !a.plain {
+						subnote = `unknown (not allowed)`
+					}
+				}
+			}
+			a.value(t[k], sub, subat, subnote, depth+1, i < len(keys)-1)
+		}
+		a.buf.WriteString("\n" + indent[2:] + `}`)
+		if comma {
+			a.buf.WriteByte(',')
+		}
+		return
+
+	case []any:
+		if len(t) == 0 {
+			break
+		}
+		a.buf.WriteByte('[')
+		if note != `` {
+			a.buf.WriteString(` // ` + note)
+		}
+		for i, item := range t {
+			a.buf.WriteString("\n" + indent)
+			a.value(item, s[`items`], at.Key(`items`), ``, depth+1, i < len(t)-1)
+		}
+		a.buf.WriteString("\n" + indent[2:] + `]`)
+		if comma {
+			a.buf.WriteByte(',')
+		}
+		return
+
+	}
+	a.buf.WriteString(This{v}.String())
+	end()
+}
@@ -0,0 +1,52 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMarshalAnnotated() {
+	schema := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name":   {"type": "string"},
+	    "level":  {"enum": ["debug", "info", "error"]},
+	    "legacy": {"type": "boolean", "deprecated": true},
+	    "server": {"$ref": "#/$defs/server"}
+	  },
+	  "$defs": {
+	    "server": {
+	      "properties": {
+	        "port": {"type": "integer"},
+	        "host": {"type": "string"}
+	      },
+	      "additionalProperties": false
+	    }
+	  }
+	}`)
+
+	config := map[string]any{
+		"extra":  1,
+		"legacy": true,
+		"level":  "info",
+		"name":   "web",
+		"server": map[string]any{"host": "localhost", "port": 80, "tls": true},
+	}
+
+	out, err := json.MarshalAnnotated(config, schema)
+	fmt.Println(string(out), err)
+
+	// Output:
+	// {
+	//   "name": "web",
+	//   "level": "info", // one of: "debug", "info", "error"
+	//   "legacy": true, // deprecated
+	//   "server": {
+	//     "port": 80,
+	//     "host": "localhost",
+	//     "tls": true // unknown (not allowed)
+	//   },
+	//   "extra": 1 // unknown
+	// } <nil>
+}
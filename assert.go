@@ -0,0 +1,129 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AssertError contains every failure found by AssertResponse.
+type AssertError struct {
+	Failures []string `json:"failures"`
+}
+
+// Error fulfills the error interface.
+func (e *AssertError) Error() string {
+	return `assertions failed: ` + strings.Join(e.Failures, `; `)
+}
+
+// AssertResponse evaluates every query expression (a Path or jq
+// expression, see QueryEach) against the decoded response and compares
+// the single value selected to the expected value, returning an
+// *AssertError with every failure (or nil if all pass) for concise
+// integration tests of JSON APIs:
+//
+//     err := json.AssertResponse(res, map[string]any{
+//       `.items | length`: 3,
+//       `.status`:         `ok`,
+//       `.id`:             regexp.MustCompile(`^[0-9a-f]{8}$`),
+//     })
+//
+// The response may be an *http.Response (whose body is read and
+// replaced so that it can be read again), JSON as a string or []byte,
+// or anything else that can be marshaled. Expected values are compared
+// in generic form (so 3 equals 3.0) except for a *regexp.Regexp, which
+// must match the value (as JSON unless a string).
+func AssertResponse(res any, want map[string]any) error {
+	v, err := assertValue(res)
+	if err != nil {
+		return err
+	}
+	queries := make([]string, 0, len(want))
+	for q := range want {
+		queries = append(queries, q)
+	}
+	sort.Strings(queries)
+	var fails []string
+	for _, q := range queries {
+		if err := assertOne(strings.TrimSpace(q), v, want[q]); err != nil {
+			fails = append(fails, err.Error())
+		}
+	}
+	if fails != nil {
+		return &AssertError{fails}
+	}
+	return nil
+}
+
+// assertValue returns the response in generic form.
+func assertValue(res any) (any, error) {
+	var buf []byte
+	switch t := res.(type) {
+	case *http.Response:
+		b, err := io.ReadAll(t.Body)
+		t.Body.Close()
+		t.Body = io.NopCloser(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		buf = b
+	case string:
+		buf = []byte(t)
+	case []byte:
+		buf = t
+	default:
+		return generic(res)
+	}
+	var v any
+	err := Unmarshal(buf, &v)
+	return v, err
+}
+
+// assertOne returns an error if the single value selected by the query
+// does not equal (or match) the expected value.
+func assertOne(query string, v, want any) error {
+	got, err := selectOne(query, v)
+	if err != nil {
+		return err
+	}
+	if re, is := want.(*regexp.Regexp); is {
+		s, is := got.(string)
+		if !is {
+			s = This{got}.String()
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf(`%s is %s, does not match %s`, query, This{got}, re)
+		}
+		return nil
+	}
+	g, err := generic(want)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(got, g) {
+		return fmt.Errorf(`%s is %s, expected %s`, query, This{got}, This{g})
+	}
+	return nil
+}
+
+// selectOne returns the single value selected by the query (a Path or
+// jq expression).
+func selectOne(query string, v any) (any, error) {
+	sel, err := compileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := sel(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 1 {
+		return nil, fmt.Errorf(`%s selects %d values (must be exactly one)`, query, len(vals))
+	}
+	return vals[0], nil
+}
@@ -0,0 +1,41 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"regexp"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleAssertResponse() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprint(w, `{"status":"ok","id":"1f2e3d4c","items":[1,2]}`)
+		}))
+	defer svr.Close()
+
+	res, err := _http.Get(svr.URL)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	err = json.AssertResponse(res, map[string]any{
+		` .items | length`: 3,
+		`.status`:          "ok",
+		`.id`:              regexp.MustCompile(`^[0-9a-f]{8}$`),
+		`.missing`:         nil,
+		`.items[]`:         1,
+	})
+	for _, f := range err.(*json.AssertError).Failures {
+		fmt.Println(f)
+	}
+
+	fmt.Println(json.AssertResponse(`{"a":[1.0]}`, map[string]any{`.a[0]`: 1}))
+
+	// Output:
+	// .items | length is 2, expected 3
+	// .items[] selects 2 values (must be exactly one)
+	// <nil>
+}
@@ -0,0 +1,57 @@
+package json_test
+
+import (
+	"context"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"sync"
+	"time"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleFetchBatch() {
+	var mu sync.Mutex
+	var active, most int
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			mu.Lock()
+			active++
+			if active > most {
+				most = active
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			if r.URL.Path == `/items/13` {
+				w.WriteHeader(404)
+				return
+			}
+			fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+		}))
+	defer svr.Close()
+
+	items := make([]struct{ Path string }, 20)
+	reqs := make([]*json.Request, len(items))
+	for i := range reqs {
+		reqs[i] = &json.Request{
+			URL:  fmt.Sprintf(`%s/items/%d`, svr.URL, i),
+			Into: &items[i],
+		}
+	}
+
+	results, stats := json.FetchBatch(context.Background(), reqs, 4)
+	fmt.Println(items[3].Path, results[13].Err, results[13].Response.StatusCode)
+	fmt.Println(stats.Requests, stats.Failed, stats.Hosts)
+	fmt.Println(most <= 4, stats.Conns <= 4, stats.Conns+stats.Reused)
+	fmt.Println(stats.Wall < stats.Total, stats.Slowest >= 10*time.Millisecond)
+
+	// Output:
+	// /items/3 404 Not Found 404
+	// 20 1 1
+	// true true 20
+	// true true
+}
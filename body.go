@@ -0,0 +1,117 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// OnBody (empty by default) are package global hooks called in order
+// (before the OnBody of the Request) with the raw body of every
+// successful response (see FetchResponse) each returning it rewritten
+// for the next before it is validated and unmarshaled so that the
+// quirks of real endpoints are removed without any io plumbing. The
+// Body of the Response is the result. Any error is returned as is.
+// Every one of the hooks provided returns the body untouched when it
+// does not have the quirk it removes:
+//
+//     json.OnBody = append(json.OnBody,
+//       json.ToUTF8,       // byte order marks and UTF-16 and UTF-32
+//       json.StripXSSI,    // )]}' and the rest
+//       json.UnwrapJSONP,  // callback({...});
+//       json.UnwrapString, // "{\"double\":\"encoded\"}"
+//     )
+//
+var OnBody []func(buf []byte) ([]byte, error)
+
+// xssiPrefixes are the prefixes added by servers to keep JSON from
+// being run as a script (cross-site script inclusion).
+var xssiPrefixes = [][]byte{
+	[]byte(`)]}'`),
+	[]byte(`while(1);`),
+	[]byte(`for(;;);`),
+	[]byte(`&&&START&&&`),
+}
+
+// StripXSSI removes any prefix added to protect against cross-site
+// script inclusion (XSSI) such as the )]}' (with an optional comma)
+// of Google and Angular or the while(1); of others.
+func StripXSSI(buf []byte) ([]byte, error) {
+	trimmed := bytes.TrimLeft(buf, " \t\r\n")
+	for _, p := range xssiPrefixes {
+		if bytes.HasPrefix(trimmed, p) {
+			rest := trimmed[len(p):]
+			if p[0] == ')' && len(rest) > 0 && rest[0] == ',' {
+				rest = rest[1:]
+			}
+			return bytes.TrimLeft(rest, " \t\r\n"), nil
+		}
+	}
+	return buf, nil
+}
+
+// UnwrapJSONP returns the JSON passed to the callback of a JSONP
+// response (callback({...}); with an optional /**/ before it). The
+// body is returned as is if it does not start with a JavaScript name
+// (with dots) followed by an opening parenthesis.
+func UnwrapJSONP(buf []byte) ([]byte, error) {
+	s := bytes.TrimSpace(buf)
+	s = bytes.TrimSpace(bytes.TrimPrefix(s, []byte(`/**/`)))
+	n := 0
+	for n < len(s) && isJSONPName(s[n], n == 0) {
+		n++
+	}
+	name := s[:n]
+	s = bytes.TrimLeft(s[n:], " \t\r\n")
+	if n == 0 || len(s) == 0 || s[0] != '(' {
+		return buf, nil
+	}
+	s = bytes.TrimSpace(bytes.TrimSuffix(s, []byte(`;`)))
+	if len(s) < 2 || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf(`JSONP callback %s not closed`, name)
+	}
+	return bytes.TrimSpace(s[1 : len(s)-1]), nil
+}
+
+// isJSONPName returns true if the byte can be part of the callback name
+// (or its first byte).
+func isJSONPName(b byte, first bool) bool {
+	switch {
+	case b == '_' || b == '$' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z':
+		return true
+	case first:
+		return false
+	}
+	return b == '.' || '0' <= b && b <= '9'
+}
+
+// UnwrapString returns the content of a response that is entirely
+// a JSON string (JSON double-encoded by the server) so that it is
+// unmarshaled as the JSON it contains. Anything else is returned as is.
+func UnwrapString(buf []byte) ([]byte, error) {
+	s := bytes.TrimSpace(buf)
+	if len(s) == 0 || s[0] != '"' {
+		return buf, nil
+	}
+	var str string
+	if err := Unmarshal(s, &str); err != nil {
+		return nil, err
+	}
+	return []byte(str), nil
+}
+
+// body returns the body after every OnBody hook (those of the package
+// then those of the Request).
+func (it *Request) body(buf []byte) ([]byte, error) {
+	var err error
+	for _, hook := range OnBody {
+		if buf, err = hook(buf); err != nil {
+			return nil, err
+		}
+	}
+	for _, hook := range it.OnBody {
+		if buf, err = hook(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
@@ -0,0 +1,57 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleOnBody() {
+	bodies := map[string]string{
+		`/xssi`:   ")]}',\n{\"id\":1}",
+		`/jsonp`:  `/**/handle.result({"id":2});`,
+		`/double`: `"{\"id\":3}"`,
+		`/bom`:    "\xEF\xBB\xBF" + `{"id":4}`,
+		`/plain`:  `{"id":5}`,
+	}
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprint(w, bodies[r.URL.Path])
+		}))
+	defer svr.Close()
+
+	hooks := []func([]byte) ([]byte, error){
+		json.ToUTF8, json.StripXSSI, json.UnwrapJSONP, json.UnwrapString,
+	}
+	for _, path := range []string{`/xssi`, `/jsonp`, `/double`, `/bom`, `/plain`} {
+		var got struct{ ID int }
+		err := json.Fetch(&json.Request{URL: svr.URL + path, Into: &got, OnBody: hooks})
+		fmt.Println(path, got.ID, err)
+	}
+
+	// Output:
+	// /xssi 1 <nil>
+	// /jsonp 2 <nil>
+	// /double 3 <nil>
+	// /bom 4 <nil>
+	// /plain 5 <nil>
+}
+
+func ExampleUnwrapJSONP() {
+	for _, body := range []string{
+		`cb({"a":1})`,
+		` jQuery123.cb_2 ( [1,2] ) ; `,
+		`{"not":"jsonp"}`,
+		`cb({"a":1}`,
+	} {
+		buf, err := json.UnwrapJSONP([]byte(body))
+		fmt.Printf("%q %v\n", buf, err)
+	}
+	// Output:
+	// "{\"a\":1}" <nil>
+	// "[1,2]" <nil>
+	// "{\"not\":\"jsonp\"}" <nil>
+	// "" JSONP callback cb not closed
+}
@@ -0,0 +1,45 @@
+package json
+
+import (
+	"fmt"
+	"time"
+)
+
+// Cache is the Store used by GetCached. It keeps everything in memory
+// by default. Set it to a Store with a Dir (within os.UserCacheDir, for
+// example) to keep the cache between runs of a command-line tool.
+var Cache = new(Store)
+
+// Validator is implemented by types that can check their own data. It
+// is observed by GetCached.
+type Validator interface {
+	Validate() error
+}
+
+// GetCached returns the data saved in the Cache under the key if it was
+// saved less than ttl ago. Otherwise, the data is fetched from the URL
+// (see Fetch), validated (it must unmarshal into T and, if T or *T is
+// a Validator, pass Validate), saved in the Cache under the key (only
+// if valid), and returned. This is the most common pattern for
+// command-line tools hitting rate-limited APIs:
+//
+//     repos, err := json.GetCached[[]Repo](`repos`, url, time.Hour)
+//
+func GetCached[T any](key, url string, ttl time.Duration) (T, error) {
+	var v T
+	if at, err := Cache.GetKey(key, &v); err == nil && time.Since(at) < ttl {
+		return v, nil
+	}
+	var fresh T
+	if err := Fetch(&Request{URL: url, Into: &fresh}); err != nil {
+		return fresh, err
+	}
+	var check any = &fresh
+	if val, is := check.(Validator); is {
+		if err := val.Validate(); err != nil {
+			return fresh, fmt.Errorf(`invalid response from %s: %w`, url, err)
+		}
+	}
+	_, err := Cache.PutKey(key, fresh)
+	return fresh, err
+}
@@ -0,0 +1,63 @@
+package json_test
+
+import (
+	"errors"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"time"
+
+	json "github.com/rwxrob/json"
+)
+
+type repo struct {
+	Name string `json:"name"`
+}
+
+func (r repo) Validate() error {
+	if r.Name == "" {
+		return errors.New(`missing name`)
+	}
+	return nil
+}
+
+func ExampleGetCached() {
+	hits := 0
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			hits++
+			if r.URL.Path == `/x` {
+				fmt.Fprint(w, `{}`)
+				return
+			}
+			fmt.Fprintf(w, `{"name":"json","hits":%d}`, hits)
+		}))
+	defer svr.Close()
+
+	orig := json.Cache
+	defer func() { json.Cache = orig }()
+	json.Cache = new(json.Store)
+
+	r, err := json.GetCached[repo](`repo`, svr.URL, time.Hour)
+	fmt.Println(r.Name, err, hits)
+	r, err = json.GetCached[repo](`repo`, svr.URL, time.Hour)
+	fmt.Println(r.Name, err, hits)
+	m, err := json.GetCached[map[string]any](`repo`, svr.URL, 0)
+	fmt.Println(m["hits"], err, hits)
+
+	// invalid responses are never cached
+	_, err = json.GetCached[repo](`other`, svr.URL+`/x`, time.Hour)
+	fmt.Println(errors.Unwrap(err), hits)
+	_, err = json.GetCached[repo](`other`, svr.URL+`/x`, time.Hour)
+	fmt.Println(errors.Unwrap(err), hits)
+	_, err = json.GetCached[repo](`bad`, `http://`, time.Hour)
+	fmt.Println(err != nil)
+
+	// Output:
+	// json <nil> 1
+	// json <nil> 1
+	// 2 <nil> 2
+	// missing name 3
+	// missing name 4
+	// true
+}
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Canonical returns the canonical form of the value as JSON in the
+// style of RFC 8785 (JCS): object keys sorted, no insignificant
+// whitespace, and all numbers as IEEE 754 doubles in their shortest
+// form. Any two values that are semantically the same JSON have the
+// exact same canonical form (no matter the Go types, key order, or
+// formatting), which makes it suitable for hashing and signing.
+// Strings and []byte are assumed to be JSON already.
+func Canonical(v any) ([]byte, error) {
+	var g any
+	var err error
+	switch t := v.(type) {
+	case string:
+		err = Unmarshal([]byte(t), &g)
+	case []byte:
+		err = Unmarshal(t, &g)
+	default:
+		g, err = generic(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return marshal(DefaultEngine, g)
+}
+
+// CanonicalHash returns the SHA-256 hash of the Canonical form of the
+// value.
+func CanonicalHash(v any) ([32]byte, error) {
+	buf, err := Canonical(v)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(buf), nil
+}
+
+// CanonicalETag returns a strong HTTP ETag (quoted) from the
+// CanonicalHash of the value so that the same data always has the same
+// ETag no matter how it happens to be encoded (see ETagHandler).
+func CanonicalETag(v any) (string, error) {
+	sum, err := CanonicalHash(v)
+	if err != nil {
+		return "", err
+	}
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`, nil
+}
@@ -0,0 +1,52 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleCanonical() {
+	a, _ := json.Canonical(`{ "b": [1.0, 2e3], "a": "x" }`)
+	b, _ := json.Canonical(struct {
+		A string `json:"a"`
+		B []int  `json:"b"`
+	}{"x", []int{1, 2000}})
+	fmt.Println(string(a))
+	fmt.Println(string(a) == string(b))
+
+	tag1, _ := json.CanonicalETag(`{"b":[1,2000],"a":"x"}`)
+	tag2, _ := json.CanonicalETag(b)
+	fmt.Println(tag1 == tag2, len(tag1))
+
+	// Output:
+	// {"a":"x","b":[1,2000]}
+	// true
+	// true 45
+}
+
+func ExampleETagHandler() {
+	svr := ht.NewServer(json.ETagHandler(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			fmt.Fprint(w, `{"status": "ok"}`)
+		})))
+	defer svr.Close()
+
+	res, _ := _http.Get(svr.URL)
+	res.Body.Close()
+	tag := res.Header.Get(`ETag`)
+	fmt.Println(res.StatusCode, tag != "")
+
+	req, _ := _http.NewRequest(`GET`, svr.URL, nil)
+	req.Header.Set(`If-None-Match`, tag)
+	res, _ = _http.DefaultClient.Do(req)
+	res.Body.Close()
+	fmt.Println(res.StatusCode)
+
+	// Output:
+	// 200 true
+	// 304
+}
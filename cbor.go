@@ -0,0 +1,459 @@
+package json
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	RegisterTranscoder(`application/cbor`, Transcoder{MarshalCBOR, UnmarshalCBOR})
+}
+
+// MarshalCBOR marshals the value as JSON first (so that the usual json
+// struct tags and MarshalJSON methods are observed) and then converts
+// that to CBOR (RFC 8949) for compact binary payloads from the same
+// structures. The encoding is deterministic (core deterministic
+// encoding): map keys are sorted, integers and lengths are as short as
+// possible, floats are 32 bits when that is exact, and integers beyond
+// 64 bits are bignums. Byte slices are base64 strings (as in JSON).
+func MarshalCBOR(v any) ([]byte, error) {
+	buf, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var g any
+	if err := dec.Decode(&g); err != nil {
+		return nil, err
+	}
+	out := new(bytes.Buffer)
+	if err := cborValue(out, g); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// UnmarshalCBOR converts the CBOR to JSON and then unmarshals it into
+// the value (passed by pointer) so that the usual json struct tags are
+// observed. Byte strings become base64 strings (and so decode into
+// []byte), map keys that are integers become strings, tagged values
+// are their content, undefined is null, and bignums are numbers
+// (exactly). Map keys of any other type and the floats NaN and
+// Infinity are errors since JSON has no way to write them.
+func UnmarshalCBOR(buf []byte, v any) error {
+	d := &cborDecoder{buf: buf}
+	g, err := d.value(0)
+	if err != nil {
+		return err
+	}
+	if d.i != len(buf) {
+		return fmt.Errorf(`cbor: %d bytes after top-level value`, len(buf)-d.i)
+	}
+	js, err := Marshal(g)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(js, v)
+}
+
+// CBOR returns the value as CBOR (see MarshalCBOR).
+func (s This) CBOR() ([]byte, error) { return MarshalCBOR(s.This) }
+
+// CBOR returns the value as CBOR (see MarshalCBOR).
+func (g *Guard[T]) CBOR() ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return MarshalCBOR(g.v)
+}
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNeg    = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// cborHead writes the initial byte(s) of an item of the major type with
+// the argument in the fewest bytes.
+func cborHead(out *bytes.Buffer, major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		out.WriteByte(m | byte(n))
+	case n <= math.MaxUint8:
+		out.Write([]byte{m | 24, byte(n)})
+	case n <= math.MaxUint16:
+		out.WriteByte(m | 25)
+		out.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		out.WriteByte(m | 26)
+		out.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		out.WriteByte(m | 27)
+		out.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+// cborValue writes the value (in generic form with json.Number).
+func cborValue(out *bytes.Buffer, v any) error {
+	switch t := v.(type) {
+	case nil:
+		out.WriteByte(0xf6)
+	case bool:
+		if t {
+			out.WriteByte(0xf5)
+		} else {
+			out.WriteByte(0xf4)
+		}
+	case string:
+		cborHead(out, cborText, uint64(len(t)))
+		out.WriteString(t)
+	case json.Number:
+		return cborNumber(out, t)
+	case []any:
+		cborHead(out, cborArray, uint64(len(t)))
+		for _, item := range t {
+			if err := cborValue(out, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		cborHead(out, cborMap, uint64(len(t)))
+		for _, k := range keys {
+			cborHead(out, cborText, uint64(len(k)))
+			out.WriteString(k)
+			if err := cborValue(out, t[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf(`cbor: unsupported type %T`, v)
+	}
+	return nil
+}
+
+// cborNumber writes the number as an integer (or bignum) if it is one
+// and a float otherwise.
+func cborNumber(out *bytes.Buffer, n json.Number) error {
+	s := string(n)
+	if !strings.ContainsAny(s, `.eE`) {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if i >= 0 {
+				cborHead(out, cborUint, uint64(i))
+			} else {
+				cborHead(out, cborNeg, uint64(-(i + 1)))
+			}
+			return nil
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			cborHead(out, cborUint, u)
+			return nil
+		}
+		b, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return fmt.Errorf(`cbor: invalid number %q`, s)
+		}
+		tag := uint64(2)
+		if b.Sign() < 0 {
+			tag = 3
+			b.Neg(b).Sub(b, big.NewInt(1))
+		}
+		cborHead(out, cborTag, tag)
+		mag := b.Bytes()
+		cborHead(out, cborBytes, uint64(len(mag)))
+		out.Write(mag)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf(`cbor: invalid number %q`, s)
+	}
+	if f32 := float32(f); float64(f32) == f {
+		out.WriteByte(0xfa)
+		out.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+		return nil
+	}
+	out.WriteByte(0xfb)
+	out.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	return nil
+}
+
+// cborDecoder is the state of UnmarshalCBOR decoding into generic form
+// (with json.Number for every number).
+type cborDecoder struct {
+	buf []byte
+	i   int
+}
+
+const cborBreak = 0xff
+
+func (d *cborDecoder) errorf(format string, args ...any) error {
+	return fmt.Errorf(`cbor: `+format+` at offset %d`, append(args, d.i)...)
+}
+
+// head returns the major type and argument of the next item (and true
+// if of indefinite length).
+func (d *cborDecoder) head() (byte, uint64, bool, error) {
+	if d.i >= len(d.buf) {
+		return 0, 0, false, d.errorf(`unexpected end`)
+	}
+	b := d.buf[d.i]
+	d.i++
+	major, info := b>>5, b&0x1f
+	size := 0
+	switch {
+	case info < 24:
+		return major, uint64(info), false, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31 && major >= cborBytes && major <= cborMap || b == cborBreak:
+		return major, 0, true, nil
+	default:
+		d.i--
+		return 0, 0, false, d.errorf(`invalid initial byte 0x%02x`, b)
+	}
+	if d.i+size > len(d.buf) {
+		return 0, 0, false, d.errorf(`unexpected end`)
+	}
+	var n uint64
+	for _, c := range d.buf[d.i : d.i+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.i += size
+	return major, n, false, nil
+}
+
+// length returns the argument as a length checking that at least that
+// many bytes (of at least min each) remain so that no input can cause
+// a huge allocation.
+func (d *cborDecoder) length(n uint64, min int) (int, error) {
+	if n > uint64(len(d.buf)-d.i)/uint64(min) {
+		return 0, d.errorf(`length %d beyond end`, n)
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, d.errorf(`nesting too deep`)
+	}
+	start := d.i
+	major, n, indef, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+
+	case cborUint:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+
+	case cborNeg:
+		b := new(big.Int).SetUint64(n)
+		return json.Number(b.Neg(b).Sub(b, big.NewInt(1)).String()), nil
+
+	case cborBytes, cborText:
+		s, err := d.str(major, n, indef)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborBytes {
+			return base64.StdEncoding.EncodeToString(s), nil
+		}
+		if !utf8.Valid(s) {
+			d.i = start
+			return nil, d.errorf(`invalid UTF-8 in text string`)
+		}
+		return string(s), nil
+
+	case cborArray:
+		size := 0
+		if !indef {
+			if size, err = d.length(n, 1); err != nil {
+				return nil, err
+			}
+		}
+		list := []any{}
+		for k := 0; indef || k < size; k++ {
+			if indef && d.brk() {
+				break
+			}
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+
+	case cborMap:
+		if !indef {
+			if _, err := d.length(n, 2); err != nil {
+				return nil, err
+			}
+		}
+		m := map[string]any{}
+		for k := 0; indef || k < int(n); k++ {
+			if indef && d.brk() {
+				break
+			}
+			key, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			var ks string
+			switch t := key.(type) {
+			case string:
+				ks = t
+			case json.Number:
+				ks = string(t)
+			default:
+				return nil, d.errorf(`map key of type %T`, key)
+			}
+			if m[ks], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case cborTag:
+		content, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if n == 2 || n == 3 {
+			raw, ok := content.(string)
+			mag, derr := base64.StdEncoding.DecodeString(raw)
+			if !ok || derr != nil {
+				return nil, d.errorf(`bignum is not a byte string`)
+			}
+			b := new(big.Int).SetBytes(mag)
+			if n == 3 {
+				b.Neg(b).Sub(b, big.NewInt(1))
+			}
+			return json.Number(b.String()), nil
+		}
+		return content, nil
+
+	}
+	return d.simple(start, n)
+}
+
+// simple returns the value of the simple value or float at the start.
+func (d *cborDecoder) simple(start int, n uint64) (any, error) {
+	var f float64
+	switch info := d.buf[start] & 0x1f; {
+	case info == 20:
+		return false, nil
+	case info == 21:
+		return true, nil
+	case info == 22 || info == 23:
+		return nil, nil
+	case info == 25:
+		f = float16(uint16(n))
+	case info == 26:
+		f = float64(math.Float32frombits(uint32(n)))
+	case info == 27:
+		f = math.Float64frombits(n)
+	default:
+		d.i = start
+		return nil, d.errorf(`unsupported simple value`)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		d.i = start
+		return nil, d.errorf(`%v has no JSON equivalent`, f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// str returns the bytes of the (possibly chunked) byte or text string.
+func (d *cborDecoder) str(major byte, n uint64, indef bool) ([]byte, error) {
+	if !indef {
+		size, err := d.length(n, 1)
+		if err != nil {
+			return nil, err
+		}
+		s := d.buf[d.i : d.i+size]
+		d.i += size
+		return s, nil
+	}
+	var s []byte
+	for !d.brk() {
+		m, cn, cindef, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || cindef {
+			return nil, d.errorf(`invalid chunk of indefinite-length string`)
+		}
+		chunk, err := d.str(major, cn, false)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+	return s, nil
+}
+
+// brk consumes the break that ends an indefinite-length item and
+// returns true if it is next.
+func (d *cborDecoder) brk() bool {
+	if d.i < len(d.buf) && d.buf[d.i] == cborBreak {
+		d.i++
+		return true
+	}
+	return false
+}
+
+// float16 returns the value of the half-precision float bits.
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,59 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMarshalCBOR() {
+	type Reading struct {
+		Sensor string  `json:"sensor"`
+		Value  float64 `json:"value"`
+		Seq    int     `json:"seq"`
+		OK     bool    `json:"ok"`
+	}
+	buf, err := json.MarshalCBOR(Reading{"t1", 21.5, 1000, true})
+	fmt.Printf("% x %v\n", buf, err)
+
+	var r Reading
+	err = json.UnmarshalCBOR(buf, &r)
+	fmt.Println(r, err)
+
+	// Output:
+	// a4 62 6f 6b f5 63 73 65 71 19 03 e8 65 76 61 6c 75 65 fa 41 ac 00 00 66 73 65 6e 73 6f 72 62 74 31 <nil>
+	// {t1 21.5 1000 true} <nil>
+}
+
+func ExampleUnmarshalCBOR() {
+	var v any
+
+	// indefinite-length map with an integer key and a byte string
+	err := json.UnmarshalCBOR([]byte{0xbf, 0x01, 0x42, 0x68, 0x69, 0x61, 0x78, 0xf9, 0x3c, 0x00, 0xff}, &v)
+	fmt.Println(v, err)
+
+	// 2^64 as a bignum
+	var d json.Decimal
+	err = json.UnmarshalCBOR([]byte{0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, &d)
+	fmt.Println(d, err)
+
+	err = json.UnmarshalCBOR([]byte{0x9a, 0xff, 0xff, 0xff, 0xff}, &v)
+	fmt.Println(err)
+
+	// array length beyond what int holds
+	err = json.UnmarshalCBOR([]byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, &v)
+	fmt.Println(err)
+
+	// Output:
+	// map[1:aGk= x:1] <nil>
+	// 18446744073709551616 <nil>
+	// cbor: length 4294967295 beyond end at offset 5
+	// cbor: length 18446744073709551615 beyond end at offset 9
+}
+
+func ExampleThis_CBOR() {
+	buf, err := json.This{[]any{-1, "a", nil, 1.1}}.CBOR()
+	fmt.Printf("% x %v\n", buf, err)
+	// Output:
+	// 84 20 61 61 f6 fb 3f f1 99 99 99 99 99 9a <nil>
+}
@@ -0,0 +1,104 @@
+package json
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Theme is the ANSI terminal escape sequence (color, bold, and so on)
+// for each TokenKind (see Highlight). Kinds missing from it (usually
+// TokenPunct) are not colored.
+type Theme map[TokenKind]string
+
+const colorReset = "\033[0m"
+
+// Themes are the themes provided by name (for flags and configuration).
+// Add to it or use any Theme as the ColorTheme.
+var Themes = map[string]Theme{
+	`default`: { // dark backgrounds
+		TokenKey:     "\033[34m",
+		TokenString:  "\033[32m",
+		TokenNumber:  "\033[36m",
+		TokenLiteral: "\033[35m",
+		TokenInvalid: "\033[31m",
+	},
+	`light`: {
+		TokenKey:     "\033[34;1m",
+		TokenString:  "\033[32;2m",
+		TokenNumber:  "\033[35m",
+		TokenLiteral: "\033[31m",
+		TokenInvalid: "\033[41m",
+	},
+	`mono`: { // no colors, only weight
+		TokenKey:     "\033[1m",
+		TokenLiteral: "\033[3m",
+		TokenInvalid: "\033[4m",
+	},
+}
+
+// ColorTheme is the Theme of StringColor and PrintColor.
+var ColorTheme = Themes[`default`]
+
+// UseColor decides if PrintColor colors its output to the file
+// (os.Stdout). It is ColorTerminal by default. Set it to a function
+// returning true (or false) to force color on (or off).
+var UseColor = ColorTerminal
+
+// ColorTerminal returns true if the file is a terminal (character
+// device) that wants color: the NO_COLOR environment variable (see
+// no-color.org) is empty and TERM is not dumb.
+func ColorTerminal(f *os.File) bool {
+	if os.Getenv(`NO_COLOR`) != "" || os.Getenv(`TERM`) == `dumb` {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorize returns the JSON with every token (see Highlight) wrapped in
+// the escape sequence of its kind (if any) and a reset.
+func (t Theme) Colorize(buf []byte) []byte {
+	var b strings.Builder
+	last := 0
+	for _, s := range Highlight(buf) {
+		color, has := t[s.Kind]
+		if !has {
+			continue
+		}
+		b.Write(buf[last:s.Start])
+		b.WriteString(color + string(buf[s.Start:s.End]) + colorReset)
+		last = s.End
+	}
+	b.Write(buf[last:])
+	return []byte(b.String())
+}
+
+// StringColor returns the JSON indented with two spaces and colored
+// with the ColorTheme (whatever the output) for readable interactive
+// debugging. Any error is logged (see String).
+func (s This) StringColor() string {
+	buf, err := MarshalIndent(s.This, "", "  ")
+	if err != nil {
+		log.Print(err)
+		return ""
+	}
+	return string(ColorTheme.Colorize(buf))
+}
+
+// PrintColor prints the JSON indented with two spaces (adding a line
+// return) and colored with the ColorTheme when UseColor decides that
+// os.Stdout wants it (a terminal by default) so that it is still plain
+// JSON when piped or redirected. Any error is logged (see Print).
+func (s This) PrintColor() {
+	buf, err := MarshalIndent(s.This, "", "  ")
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	if UseColor(os.Stdout) {
+		buf = ColorTheme.Colorize(buf)
+	}
+	fmt.Println(string(buf))
+}
@@ -0,0 +1,35 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleThis_StringColor() {
+	defer func(t json.Theme) { json.ColorTheme = t }(json.ColorTheme)
+	json.ColorTheme = json.Theme{
+		json.TokenKey:    "<k>",
+		json.TokenNumber: "<n>",
+	}
+	out := json.This{map[string]any{"id": 1, "name": "x"}}.StringColor()
+	fmt.Println(strings.ReplaceAll(out, "\033[0m", "</>"))
+	// Output:
+	// {
+	//   <k>"id"</>: <n>1</>,
+	//   <k>"name"</>: "x"
+	// }
+}
+
+func ExampleThis_PrintColor() {
+	defer func(f func(*os.File) bool) { json.UseColor = f }(json.UseColor)
+	json.UseColor = func(*os.File) bool { return false } // piped
+	json.This{[]any{true, nil}}.PrintColor()
+	// Output:
+	// [
+	//   true,
+	//   null
+	// ]
+}
@@ -0,0 +1,274 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Schema is a JSON Schema parsed (with every pattern compiled) once so
+// that validating many values does no parsing at all (see ValidateSchema
+// and GenerateCompiled).
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+// CompileSchema returns the parsed JSON Schema or an error if it is
+// not valid JSON or has an invalid pattern.
+func CompileSchema(schema []byte) (*Schema, error) {
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		return nil, err
+	}
+	return NewSchema(s)
+}
+
+// MustCompileSchema calls CompileSchema and panics on error (for
+// package level variables).
+func MustCompileSchema(schema []byte) *Schema {
+	s, err := CompileSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// NewSchema returns the Schema for the JSON Schema already in generic
+// form (map[string]any, etc.) which must not be changed afterward.
+func NewSchema(schema any) (*Schema, error) {
+	s := &Schema{root: schema, patterns: map[string]*regexp.Regexp{}}
+	if err := s.compile(schema, 0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustNewSchema calls NewSchema and panics on error (for package level
+// variables, see GenerateCompiled).
+func MustNewSchema(schema any) *Schema {
+	s, err := NewSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// compile compiles every pattern of the schema (skipping values that
+// are data rather than schemas).
+func (s *Schema) compile(v any, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf(`schema nesting too deep`)
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		for k, sub := range t {
+			switch k {
+			case `const`, `enum`, `default`, `example`, `examples`:
+				continue
+			case `pattern`:
+				if pat, is := sub.(string); is {
+					re, err := regexp.Compile(pat)
+					if err != nil {
+						return fmt.Errorf(`invalid pattern: %v`, err)
+					}
+					s.patterns[pat] = re
+					continue
+				}
+			}
+			if err := s.compile(sub, depth+1); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, sub := range t {
+			if err := s.compile(sub, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate is the same as ValidateSchema using the compiled Schema.
+func (s *Schema) Validate(v any) error {
+	var g any
+	var err error
+	switch t := v.(type) {
+	case string:
+		err = Unmarshal([]byte(t), &g)
+	case []byte:
+		err = Unmarshal(t, &g)
+	default:
+		g, err = generic(v)
+	}
+	if err != nil {
+		return err
+	}
+	sv := &schemaValidator{root: s.root, patterns: s.patterns}
+	sv.check(s.root, g, Path{})
+	if len(sv.violations) == 0 {
+		return nil
+	}
+	return &SchemaError{sv.violations}
+}
+
+// JSON returns the Schema as compact JSON (keys sorted).
+func (s *Schema) JSON() ([]byte, error) { return Marshal(s.root) }
+
+// Query is a query expression (a Path or, if not, a jq expression)
+// parsed once to be selected against any number of values.
+type Query struct {
+	expr   string
+	path   Path
+	isPath bool
+	sel    func(v any) ([]any, error)
+}
+
+// CompileQuery returns the parsed query expression (see QueryEach).
+func CompileQuery(expr string) (*Query, error) {
+	if p, err := ParsePath(expr); err == nil {
+		return PathQuery(p), nil
+	}
+	q, err := ParseJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{expr: expr, sel: q.Select}, nil
+}
+
+// MustCompileQuery calls CompileQuery and panics on error (for package
+// level variables).
+func MustCompileQuery(expr string) *Query {
+	q, err := CompileQuery(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// PathQuery returns the Query selecting the Path (see GenerateCompiled).
+func PathQuery(p Path) *Query {
+	return &Query{expr: p.String(), path: p, isPath: true, sel: p.Select}
+}
+
+// String fulfills the fmt.Stringer interface returning the expression.
+func (q *Query) String() string { return q.expr }
+
+// Select returns every value matching the Query.
+func (q *Query) Select(v any) ([]any, error) { return q.sel(v) }
+
+// GenerateCompiled returns formatted Go source code (for the named
+// package in the directory) with a variable for each of the JSON Schema
+// files (relative to the directory and keyed by variable name) and
+// query expressions (also keyed by variable name) so that programs
+// neither parse them at startup nor need any files beside the binary.
+// Every schema file is embedded (with go:embed as the variable name
+// followed by JSON) and its variable is a *Schema built from a Go
+// literal of its parsed form:
+//
+//     //go:embed user.schema.json
+//     var UserSchemaJSON []byte
+//
+//     var UserSchema = json.MustNewSchema(map[string]any{ ... })
+//
+// Path queries are built step by step with PathQuery. The jq
+// expressions are compiled into functions, which cannot be written as
+// literals, and are therefore parsed during package initialization
+// (after being checked by GenerateCompiled). Typically called from a
+// small program run by go:generate.
+func GenerateCompiled(pkg, dir string, schemas, queries map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by json.GenerateCompiled; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	if len(schemas) > 0 {
+		b.WriteString("\t_ \"embed\"\n\n")
+	}
+	fmt.Fprintf(&b, "\tjson %q\n)\n\n", `github.com/rwxrob/json`)
+
+	for _, name := range sortedStringKeys(schemas) {
+		file := schemas[name]
+		buf, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		var s any
+		if err := Unmarshal(buf, &s); err != nil {
+			return nil, fmt.Errorf(`%s: %w`, file, err)
+		}
+		if _, err := NewSchema(s); err != nil {
+			return nil, fmt.Errorf(`%s: %w`, file, err)
+		}
+		fmt.Fprintf(&b, "// %sJSON is the content of %s.\n//\n", name, file)
+		fmt.Fprintf(&b, "//go:embed %s\nvar %sJSON []byte\n\n", filepath.ToSlash(file), name)
+		fmt.Fprintf(&b, "// %s is the compiled %s.\n", name, file)
+		fmt.Fprintf(&b, "var %s = json.MustNewSchema(%s)\n\n", name, goLiteral(s))
+	}
+
+	for _, name := range sortedStringKeys(queries) {
+		q, err := CompileQuery(queries[name])
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, name, err)
+		}
+		fmt.Fprintf(&b, "// %s is the compiled query %s.\n", name, q)
+		if !q.isPath {
+			fmt.Fprintf(&b, "var %s = json.MustCompileQuery(%s)\n\n", name, strconv.Quote(q.expr))
+			continue
+		}
+		expr := `json.Path{}`
+		for _, step := range q.path {
+			switch {
+			case step.Each:
+				expr += `.Each()`
+			case step.Index != nil:
+				expr += `.Index(` + strconv.Itoa(*step.Index) + `)`
+			default:
+				expr += `.Key(` + strconv.Quote(step.Key) + `)`
+			}
+		}
+		fmt.Fprintf(&b, "var %s = json.PathQuery(%s)\n\n", name, expr)
+	}
+	return format.Source(b.Bytes())
+}
+
+// goLiteral returns the Go literal of the value (in generic form) with
+// numbers always float64 (as when unmarshaled).
+func goLiteral(v any) string {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			return `map[string]any{}`
+		}
+		var b strings.Builder
+		b.WriteString("map[string]any{\n")
+		for _, k := range sortedAnyKeys(t) {
+			b.WriteString(strconv.Quote(k) + `: ` + goLiteral(t[k]) + ",\n")
+		}
+		b.WriteString(`}`)
+		return b.String()
+	case []any:
+		if len(t) == 0 {
+			return `[]any{}`
+		}
+		var b strings.Builder
+		b.WriteString("[]any{\n")
+		for _, item := range t {
+			b.WriteString(goLiteral(item) + ",\n")
+		}
+		b.WriteString(`}`)
+		return b.String()
+	case string:
+		return strconv.Quote(t)
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return `float64(` + strconv.FormatFloat(t, 'g', -1, 64) + `)`
+	}
+	return `nil`
+}
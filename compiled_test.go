@@ -0,0 +1,95 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleCompileSchema() {
+	schema := json.MustCompileSchema([]byte(`{
+		"type": "object",
+		"properties": {"id": {"type": "string", "pattern": "^[a-z]+$"}},
+		"required": ["id"]
+	}`))
+	fmt.Println(schema.Validate(`{"id":"abc"}`))
+	fmt.Println(schema.Validate(`{"id":"ABC"}`))
+	fmt.Println(schema.Validate(map[string]any{}))
+
+	_, err := json.CompileSchema([]byte(`{"pattern": "("}`))
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// schema violations: .id: does not match pattern ^[a-z]+$
+	// schema violations: .id: required
+	// invalid pattern: error parsing regexp: missing closing ): `(`
+}
+
+func ExampleCompileQuery() {
+	doc := map[string]any{`items`: []any{
+		map[string]any{`name`: `a`, `qty`: 1.0},
+		map[string]any{`name`: `b`, `qty`: 3.0},
+	}}
+	for _, expr := range []string{`.items[].name`, `.items[] | select(.qty > 2) | .name`} {
+		q := json.MustCompileQuery(expr)
+		fmt.Println(q.Select(doc))
+	}
+	// Output:
+	// [a b] <nil>
+	// [b] <nil>
+}
+
+func ExampleGenerateCompiled() {
+	dir, _ := os.MkdirTemp("", "compiled")
+	defer os.RemoveAll(dir)
+	os.WriteFile(filepath.Join(dir, "user.schema.json"),
+		[]byte(`{"type":"object","required":["id"],"properties":{"id":{"type":"integer","minimum":1}}}`), 0644)
+
+	src, err := json.GenerateCompiled(`users`, dir,
+		map[string]string{`UserSchema`: `user.schema.json`},
+		map[string]string{`UserIDs`: `.users[].id`, `Admins`: `.users[] | select(.admin)`},
+	)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(string(src))
+
+	// Output:
+	// // Code generated by json.GenerateCompiled; DO NOT EDIT.
+	//
+	// package users
+	//
+	// import (
+	// 	_ "embed"
+	//
+	// 	json "github.com/rwxrob/json"
+	// )
+	//
+	// // UserSchemaJSON is the content of user.schema.json.
+	// //
+	// //go:embed user.schema.json
+	// var UserSchemaJSON []byte
+	//
+	// // UserSchema is the compiled user.schema.json.
+	// var UserSchema = json.MustNewSchema(map[string]any{
+	// 	"properties": map[string]any{
+	// 		"id": map[string]any{
+	// 			"minimum": float64(1),
+	// 			"type":    "integer",
+	// 		},
+	// 	},
+	// 	"required": []any{
+	// 		"id",
+	// 	},
+	// 	"type": "object",
+	// })
+	//
+	// // Admins is the compiled query .users[] | select(.admin).
+	// var Admins = json.MustCompileQuery(".users[] | select(.admin)")
+	//
+	// // UserIDs is the compiled query .users[].id.
+	// var UserIDs = json.PathQuery(json.Path{}.Key("users").Each().Key("id"))
+}
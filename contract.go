@@ -0,0 +1,298 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Contract is an http.RoundTripper for contract testing (like Pact)
+// meant to be used as the Transport of the Client in tests:
+//
+//     json.Client = &http.Client{Transport: &json.Contract{
+//       File:   `testdata/users.json`,
+//       Record: os.Getenv(`RECORD`) != "",
+//     }}
+//
+// When recording, every request is sent with the Transport (or
+// http.DefaultTransport) and the request and response saved as an
+// Interaction to the File. Otherwise, the recorded responses are served
+// instead (no network) but only after verifying that the outgoing
+// request still has the same method, path, and body shape (every key
+// path and the type of its value) as the one recorded. Any drift fails
+// the request with a *ContractError. Interactions are matched by method
+// and path and served in the order recorded (the last repeating).
+type Contract struct {
+	File      string
+	Record    bool
+	Transport http.RoundTripper
+
+	mu     sync.Mutex
+	loaded bool
+	recs   []Interaction
+	served map[int]bool
+}
+
+// Interaction is a single recorded request and response of a Contract.
+type Interaction struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Shape   map[string]string `json:"shape,omitempty"` // request body
+	Status  int               `json:"status"`
+	Header  map[string]string `json:"header,omitempty"`
+	Body    string            `json:"body"`
+	ReqBody string            `json:"reqbody,omitempty"`
+}
+
+// ContractError is returned (wrapped in a *url.Error by the Client)
+// when a request does not match the recorded Interaction.
+type ContractError struct {
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// Error fulfills the error interface.
+func (e *ContractError) Error() string {
+	s := fmt.Sprintf(`%s %s breaks contract:`, e.Method, e.Path)
+	for _, v := range e.Violations {
+		if v.Path != `` {
+			s += ` ` + v.Path + `:`
+		}
+		s += ` ` + v.Msg + `;`
+	}
+	return s[:len(s)-1]
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Contract) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	shape := bodyShape(req.Header.Get(`Content-Type`), body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	if c.Record {
+		return c.record(req, body, shape)
+	}
+
+	i, rec := c.match(req.Method, req.URL.Path)
+	if rec == nil {
+		return nil, &ContractError{req.Method, req.URL.Path, []SchemaViolation{{Msg: `no recorded interaction`}}}
+	}
+	var vs []SchemaViolation
+	for _, k := range setDiff(mapKeys(rec.Shape), mapKeys(shape)) {
+		vs = append(vs, SchemaViolation{k, `missing from request body`})
+	}
+	for _, k := range setDiff(mapKeys(shape), mapKeys(rec.Shape)) {
+		vs = append(vs, SchemaViolation{k, `not in recorded request body`})
+	}
+	for _, k := range sortedStringKeys(shape) {
+		if t, has := rec.Shape[k]; has && t != shape[k] {
+			vs = append(vs, SchemaViolation{k, fmt.Sprintf(`expected %s but got %s`, t, shape[k])})
+		}
+	}
+	if vs != nil {
+		return nil, &ContractError{req.Method, req.URL.Path, vs}
+	}
+	c.served[i] = true
+
+	res := &http.Response{
+		Status:        strconv.Itoa(rec.Status) + ` ` + http.StatusText(rec.Status),
+		StatusCode:    rec.Status,
+		Proto:         `HTTP/1.1`,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}
+	for k, v := range rec.Header {
+		res.Header.Set(k, v)
+	}
+	return res, nil
+}
+
+// Unused returns the recorded interactions that have not been served
+// (in order) so that tests can also fail when expected requests are no
+// longer made.
+func (c *Contract) Unused() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.load() != nil {
+		return nil
+	}
+	var out []Interaction
+	for i, rec := range c.recs {
+		if !c.served[i] {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// match returns the index and Interaction to serve for the method and
+// path (nil if none).
+func (c *Contract) match(method, path string) (int, *Interaction) {
+	last := -1
+	for i := range c.recs {
+		if c.recs[i].Method != method || c.recs[i].Path != path {
+			continue
+		}
+		if !c.served[i] {
+			return i, &c.recs[i]
+		}
+		last = i
+	}
+	if last < 0 {
+		return last, nil
+	}
+	return last, &c.recs[last]
+}
+
+// record sends the request and saves the interaction.
+func (c *Contract) record(req *http.Request, body []byte, shape map[string]string) (*http.Response, error) {
+	tr := c.Transport
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	res, err := tr.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(buf))
+
+	rec := Interaction{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   req.URL.RawQuery,
+		Shape:   shape,
+		Status:  res.StatusCode,
+		Body:    string(buf),
+		ReqBody: string(body),
+	}
+	if ct := res.Header.Get(`Content-Type`); ct != `` {
+		rec.Header = map[string]string{`Content-Type`: ct}
+	}
+	c.recs = append(c.recs, rec)
+	c.served[len(c.recs)-1] = true
+	return res, c.save()
+}
+
+// load loads the recorded interactions (once) unless recording, which
+// always starts over.
+func (c *Contract) load() error {
+	if c.loaded {
+		return nil
+	}
+	c.served = map[int]bool{}
+	if !c.Record {
+		buf, err := os.ReadFile(c.File)
+		if err != nil {
+			return err
+		}
+		if err := Unmarshal(buf, &c.recs); err != nil {
+			return err
+		}
+	}
+	c.loaded = true
+	return nil
+}
+
+// save saves the recorded interactions (atomically).
+func (c *Contract) save() error {
+	buf, err := MarshalIndent(c.recs, ``, `  `)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.File), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.File+`.tmp`, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(c.File+`.tmp`, c.File)
+}
+
+// bodyShape returns every key path of the body (a JSON document or
+// form) with the JSON type of its value (integers being numbers).
+// Nothing is returned for empty bodies.
+func bodyShape(contentType string, body []byte) map[string]string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	shape := map[string]string{}
+	if mt, _, _ := mime.ParseMediaType(contentType); mt == `application/x-www-form-urlencoded` {
+		form, err := url.ParseQuery(string(body))
+		if err == nil {
+			for k := range form {
+				shape[Path{}.Key(k).String()] = `string`
+			}
+			return shape
+		}
+	}
+	var v any
+	if Unmarshal(body, &v) != nil {
+		shape[`.`] = `text`
+		return shape
+	}
+	shapeOf(v, Path{}, shape)
+	return shape
+}
+
+func shapeOf(v any, p Path, shape map[string]string) {
+	t := jsonType(v)
+	if t == `integer` {
+		t = `number`
+	}
+	shape[p.String()] = t
+	switch c := v.(type) {
+	case map[string]any:
+		for k, cv := range c {
+			shapeOf(cv, p.Key(k), shape)
+		}
+	case []any:
+		for _, cv := range c {
+			shapeOf(cv, p.Each(), shape)
+		}
+	}
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := mapKeys(m)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package json_test
+
+import (
+	"errors"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleContract() {
+	dir, _ := os.MkdirTemp("", "contract")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "users.json")
+
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			fmt.Fprintf(w, `{"id":1,"method":%q}`, r.Method)
+		}))
+
+	orig := json.Client
+	defer func() { json.Client = orig }()
+
+	// record once against the real server
+	json.Client = &_http.Client{Transport: &json.Contract{File: file, Record: true}}
+	var out map[string]any
+	err := json.Fetch(&json.Request{
+		Method: `POST`,
+		URL:    svr.URL + `/users`,
+		Body:   url.Values{"name": {"ann"}},
+		Into:   &out,
+	})
+	fmt.Println(err, out["method"])
+	svr.Close()
+
+	// replay (server is gone) verifying requests
+	c := &json.Contract{File: file}
+	json.Client = &_http.Client{Transport: c}
+	fmt.Println(len(c.Unused()))
+
+	out = nil
+	err = json.Fetch(&json.Request{
+		Method: `POST`,
+		URL:    svr.URL + `/users`,
+		Body:   url.Values{"name": {"bob"}},
+		Into:   &out,
+	})
+	fmt.Println(err, out["method"], len(c.Unused()))
+
+	err = json.Fetch(&json.Request{
+		Method: `POST`,
+		URL:    svr.URL + `/users`,
+		Body:   url.Values{"nick": {"bob"}},
+		Into:   &out,
+	})
+	var cerr *json.ContractError
+	fmt.Println(errors.As(err, &cerr))
+	for _, v := range cerr.Violations {
+		fmt.Println(v.Path, v.Msg)
+	}
+
+	err = json.Fetch(&json.Request{URL: svr.URL + `/groups`, Into: &out})
+	fmt.Println(errors.As(err, &cerr), cerr.Violations[0].Msg)
+
+	// Output:
+	// <nil> POST
+	// 1
+	// <nil> POST 0
+	// true
+	// .name missing from request body
+	// .nick not in recorded request body
+	// true no recorded interaction
+}
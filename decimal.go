@@ -0,0 +1,333 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number (an arbitrarily large integer and
+// the number of digits after the decimal point) for money and anything
+// else that must never pass through a float64. The zero value is 0.
+// Decimals marshal as JSON strings ("12.30") with exactly as many
+// digits after the point as their scale and unmarshal from strings or
+// numbers (exactly as written, never through a float64). There is
+// deliberately no way to make or get a float64.
+//
+// Decimal fields (or *Decimal) may have the scale and round tag options
+// to round (see RoundingMode) to a fixed number of digits when marshaled
+// and unmarshaled (always, whatever the DefaultEngine). The same options
+// on a float32 or float64 field are an error (use Decimal instead):
+//
+//     type Invoice struct {
+//       Total Decimal `json:"total,scale=2"`               // halfeven
+//       Tax   Decimal `json:"tax,scale=2,round=halfup"`
+//       Rate  Decimal `json:"rate,scale=4,round=down"`
+//     }
+//
+type Decimal struct {
+	n     *big.Int // unscaled value (nil is 0), never changed once set
+	scale int
+}
+
+// RoundingMode is how Decimal.Round (and the round tag option) drops
+// digits.
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // to nearest, ties to even (banker's)
+	RoundHalfUp                       // to nearest, ties away from zero
+	RoundDown                         // toward zero (truncate)
+	RoundUp                           // away from zero
+)
+
+// roundingModes are the values of the round tag option.
+var roundingModes = map[string]RoundingMode{
+	`halfeven`: RoundHalfEven,
+	`halfup`:   RoundHalfUp,
+	`down`:     RoundDown,
+	`up`:       RoundUp,
+}
+
+// maxDecimalExp limits the exponent of parsed decimals (1e1000000000
+// would otherwise need gigabytes).
+const maxDecimalExp = 10000
+
+// NewDecimal returns the Decimal of the unscaled value with the scale
+// (digits after the point) so that NewDecimal(1234, 2) is 12.34.
+// A negative scale multiplies by a power of ten.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	d := Decimal{big.NewInt(unscaled), scale}
+	if scale < 0 {
+		d = d.Round(0, RoundDown)
+	}
+	return d
+}
+
+// ParseDecimal parses a decimal number in JSON number syntax (with an
+// optional leading + and surrounding space) keeping every digit after
+// the point (so the scale of "1.50" is 2). Exponents become scale:
+// "1.5e-3" is 0.0015.
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimPrefix(strings.TrimSpace(s), `+`)
+	if !validNumber(str) {
+		return Decimal{}, fmt.Errorf(`invalid decimal: %q`, s)
+	}
+	mant, exp := str, 0
+	if i := strings.IndexAny(str, `eE`); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(str[i+1:], `+`))
+		if err != nil || e > maxDecimalExp || e < -maxDecimalExp {
+			return Decimal{}, fmt.Errorf(`decimal exponent out of range: %q`, s)
+		}
+		mant, exp = str[:i], e
+	}
+	whole, frac, _ := strings.Cut(mant, `.`)
+	n, _ := new(big.Int).SetString(whole+frac, 10)
+	d := Decimal{n, len(frac) - exp}
+	if d.scale < 0 {
+		d = d.Round(0, RoundDown)
+	}
+	return d, nil
+}
+
+// MustParseDecimal is the same as ParseDecimal but panics on any error.
+// Use it only for constants, tests, and examples.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// int returns the unscaled value (never nil).
+func (d Decimal) int() *big.Int {
+	if d.n == nil {
+		return new(big.Int)
+	}
+	return d.n
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int { return d.scale }
+
+// Sign returns -1, 0, or +1.
+func (d Decimal) Sign() int { return d.int().Sign() }
+
+// Cmp returns -1, 0, or +1 if the Decimal is less than, equal to, or
+// greater than the other (whatever their scales).
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+// Add returns the exact sum with the larger of the two scales.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{new(big.Int).Add(a, b), maxScale(d, o)}
+}
+
+// Sub returns the exact difference with the larger of the two scales.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{new(big.Int).Sub(a, b), maxScale(d, o)}
+}
+
+// Mul returns the exact product with the sum of the two scales (use
+// Round to bring it back to that of the currency).
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{new(big.Int).Mul(d.int(), o.int()), d.scale + o.scale}
+}
+
+// Round returns the Decimal with exactly the scale dropping any extra
+// digits by the RoundingMode (or adding zeros).
+func (d Decimal) Round(scale int, mode RoundingMode) Decimal {
+	n := d.int()
+	if scale >= d.scale {
+		return Decimal{new(big.Int).Mul(n, pow10(scale-d.scale)), scale}
+	}
+	div := pow10(d.scale - scale)
+	q, r := new(big.Int).QuoRem(n, div, new(big.Int))
+	if r.Sign() == 0 {
+		return Decimal{q, scale}
+	}
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	var up bool
+	switch cmp := half.Cmp(div); mode {
+	case RoundHalfEven:
+		up = cmp > 0 || cmp == 0 && q.Bit(0) == 1
+	case RoundHalfUp:
+		up = cmp >= 0
+	case RoundUp:
+		up = true
+	}
+	if up {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return Decimal{q, scale}
+}
+
+// String fulfills the fmt.Stringer interface with every digit after the
+// point (see Scale) and no exponent.
+func (d Decimal) String() string {
+	s := d.int().String()
+	if d.scale <= 0 {
+		return s
+	}
+	neg := s[0] == '-'
+	s = strings.TrimPrefix(s, `-`)
+	if len(s) <= d.scale {
+		s = strings.Repeat(`0`, d.scale-len(s)+1) + s
+	}
+	s = s[:len(s)-d.scale] + `.` + s[len(s)-d.scale:]
+	if neg {
+		s = `-` + s
+	}
+	return s
+}
+
+// MarshalJSON fulfills the json.Marshaler interface with the String as
+// a JSON string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface from a string
+// or number (see ParseDecimal). Null leaves the Decimal untouched.
+func (d *Decimal) UnmarshalJSON(buf []byte) error {
+	s := string(buf)
+	if s == `null` {
+		return nil
+	}
+	if len(s) > 1 && s[0] == '"' {
+		if err := Unmarshal(buf, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// align returns the unscaled values of both at the larger scale.
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	s := maxScale(a, b)
+	return a.Round(s, RoundDown).int(), b.Round(s, RoundDown).int()
+}
+
+func maxScale(a, b Decimal) int {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+var decimalType = reflect.TypeOf(Decimal{})
+
+// decimalTag is the scale and round tag options of a field (see
+// Decimal).
+type decimalTag struct {
+	scale int
+	mode  RoundingMode
+	err   error // invalid option
+}
+
+// decimalOpts returns the scale and round tag options (if any) for the
+// field named.
+func decimalOpts(name, opts string) *decimalTag {
+	scale, hasScale := optValue(opts, `scale`)
+	round, hasRound := optValue(opts, `round`)
+	if !hasScale && !hasRound {
+		return nil
+	}
+	t := new(decimalTag)
+	var err error
+	if !hasScale {
+		t.err = fmt.Errorf(`json: round tag option of field %s without scale`, name)
+	} else if t.scale, err = strconv.Atoi(scale); err != nil || t.scale < 0 {
+		t.err = fmt.Errorf(`json: invalid scale tag option of field %s: %q`, name, scale)
+	}
+	if hasRound {
+		mode, ok := roundingModes[round]
+		if !ok {
+			t.err = fmt.Errorf(`json: invalid round tag option of field %s: %q`, name, round)
+		}
+		t.mode = mode
+	}
+	return t
+}
+
+// applies returns true if the options apply to the type of the field
+// (Decimal, *Decimal, or any float for the error).
+func (t *decimalTag) applies(ft reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch ft.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		return ft.Elem() == decimalType
+	}
+	return ft == decimalType
+}
+
+// check returns an error for invalid options or a float field.
+func (t *decimalTag) check(name string, ft reflect.Type) error {
+	if t.err != nil {
+		return t.err
+	}
+	if k := ft.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+		return fmt.Errorf(`json: scale of float field %s (use Decimal)`, name)
+	}
+	return nil
+}
+
+// encode returns the field value rounded as a JSON string (false if it
+// is nil).
+func (t *decimalTag) encode(name string, fv reflect.Value) (string, bool, error) {
+	if err := t.check(name, fv.Type()); err != nil {
+		return "", false, err
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return "", false, nil
+		}
+		fv = fv.Elem()
+	}
+	return `"` + fv.Interface().(Decimal).Round(t.scale, t.mode).String() + `"`, true, nil
+}
+
+// decode returns the value (in generic form with json.Number) of the
+// field rounded as a string (as is if not a decimal for UnmarshalJSON
+// to report).
+func (t *decimalTag) decode(name string, ft reflect.Type, v any) (any, error) {
+	if err := t.check(name, ft); err != nil {
+		return nil, err
+	}
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case json.Number:
+		s = string(x)
+	default:
+		return v, nil
+	}
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return v, nil
+	}
+	return d.Round(t.scale, t.mode).String(), nil
+}
@@ -0,0 +1,56 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDecimal() {
+	type Invoice struct {
+		Total json.Decimal  `json:"total,scale=2"`
+		Tax   json.Decimal  `json:"tax,scale=2,round=halfup"`
+		Rate  json.Decimal  `json:"rate"`
+		Due   *json.Decimal `json:"due,scale=2,omitempty"`
+	}
+
+	var inv Invoice
+	err := json.Unmarshal([]byte(`{"total":"10.125","tax":0.125,"rate":0.0725}`), &inv)
+	fmt.Println(err, inv.Total, inv.Tax, inv.Rate)
+
+	inv.Total = inv.Total.Add(json.MustParseDecimal("0.005"))
+	inv.Tax = inv.Total.Mul(inv.Rate)
+	fmt.Println(inv.Tax)
+	fmt.Println(string(json.MustMarshal(inv)))
+
+	type Bad struct {
+		Total float64 `json:"total,scale=2"`
+	}
+	_, err = json.Marshal(Bad{1.5})
+	fmt.Println(err)
+
+	// Output:
+	// <nil> 10.12 0.13 0.0725
+	// 0.7340625
+	// {"total":"10.12","tax":"0.73","rate":"0.0725"}
+	// json: scale of float field total (use Decimal)
+}
+
+func ExampleDecimal_Round() {
+	for _, s := range []string{"2.5", "3.5", "-2.5", "2.51", "1e2"} {
+		d := json.MustParseDecimal(s)
+		fmt.Println(s,
+			d.Round(0, json.RoundHalfEven),
+			d.Round(0, json.RoundHalfUp),
+			d.Round(0, json.RoundDown),
+			d.Round(0, json.RoundUp),
+			d.Round(2, json.RoundHalfEven),
+		)
+	}
+	// Output:
+	// 2.5 2 3 2 3 2.50
+	// 3.5 4 4 3 4 3.50
+	// -2.5 -2 -3 -2 -3 -2.50
+	// 2.51 3 3 2 3 2.51
+	// 1e2 100 100 100 100 100.00
+}
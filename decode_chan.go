@@ -0,0 +1,122 @@
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecodeToChan decodes every item of a top-level JSON array (or every
+// value of JSON Lines, NDJSON, or any concatenated JSON values) from the
+// reader as it is read, sending each to the returned value channel so
+// that downstream stages can start processing before the read is
+// complete. Each item is decoded with Unmarshal (observing every
+// package policy). The value channel is closed when done and the error
+// channel then receives the first error (if any) and is closed as well.
+// The value channel must be drained (or the reader closed) to avoid
+// leaking the decoding goroutine. Progress is reported to OnProgress
+// (if set).
+//
+//     items, errs := json.DecodeToChan[Item](resp.Body)
+//     for it := range items {
+//       process(it)
+//     }
+//     if err := <-errs; err != nil {
+//       return err
+//     }
+//
+func DecodeToChan[T any](r io.Reader) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errs := make(chan error, 1)
+	pr := newProgressReader(r, "", -1)
+	go func() {
+		defer close(errs)
+		defer close(out)
+		defer pr.done()
+		if err := decodeToChan(pr, out); err != nil {
+			errs <- err
+		}
+	}()
+	return out, errs
+}
+
+func decodeToChan[T any](pr *progressReader, out chan<- T) error {
+	items, err := newItemReader(pr)
+	if err != nil {
+		return err
+	}
+	for n := 0; ; n++ {
+		raw, err := items.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var v T
+		if err := Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf(`item %d: %w`, n, err)
+		}
+		out <- v
+		pr.item()
+	}
+}
+
+// itemReader reads every item of a top-level array (or every value of
+// JSON Lines or concatenated values) one at a time.
+type itemReader struct {
+	dec   *json.Decoder
+	array bool
+	empty bool
+}
+
+// newItemReader returns a new itemReader after reading just enough to
+// know if the input is an array.
+func newItemReader(r io.Reader) (*itemReader, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\xEF\xBB\xBF" {
+		br.Discard(3)
+	}
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return &itemReader{empty: true}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			br.UnreadByte()
+			it := &itemReader{dec: json.NewDecoder(br), array: b == '['}
+			if it.array {
+				if _, err := it.dec.Token(); err != nil {
+					return nil, err
+				}
+			}
+			return it, nil
+		}
+	}
+}
+
+// next returns the next raw item or io.EOF when there are no more.
+func (it *itemReader) next() (json.RawMessage, error) {
+	if it.empty {
+		return nil, io.EOF
+	}
+	if it.array && !it.dec.More() {
+		if _, err := it.dec.Token(); err != nil {
+			return nil, err
+		}
+		it.empty = true
+		return nil, io.EOF
+	}
+	var raw json.RawMessage
+	if err := it.dec.Decode(&raw); err != nil {
+		if err == io.EOF && it.array {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return raw, nil
+}
@@ -0,0 +1,35 @@
+package json_test
+
+import (
+	"fmt"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDecodeToChan() {
+	type Item struct {
+		ID int `json:"id"`
+	}
+
+	items, errs := json.DecodeToChan[Item](strings.NewReader(`[{"id":1}, {"id":2}]`))
+	for it := range items {
+		fmt.Println(it.ID)
+	}
+	fmt.Println(<-errs)
+
+	lines := "{\"id\":3}\n{\"id\":4}\n{\"id\":\"five\"}\n{\"id\":6}\n"
+	items, errs = json.DecodeToChan[Item](strings.NewReader(lines))
+	for it := range items {
+		fmt.Println(it.ID)
+	}
+	fmt.Println(<-errs)
+
+	// Output:
+	// 1
+	// 2
+	// <nil>
+	// 3
+	// 4
+	// item 2: json: cannot unmarshal string into Go struct field Item.id of type int
+}
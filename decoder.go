@@ -0,0 +1,118 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Token is a JSON token (see Decoder.Token) exactly as from the
+// encoding/json package: Delim, bool, float64, Number, string, or nil.
+type Token = json.Token
+
+// Delim is an array or object delimiter: [ ] { or }.
+type Delim = json.Delim
+
+// Decoder reads and decodes JSON values from a stream (large files,
+// network responses, JSON Lines, etc.) without reading everything into
+// memory first. It mirrors the encoding/json.Decoder but every value
+// is decoded with Unmarshal (observing every package policy such as
+// InvalidUTF8 and the UnmarshalJSON methods of AsJSON types) and a
+// leading byte order mark (BOM) is skipped. Values decoded into This
+// (or any AsJSON type) re-encode with the package defaults (minimal
+// escaping). Combine Token and More with Decode to stream the items of
+// a huge array one at a time:
+//
+//     dec := json.NewDecoder(f)
+//     dec.Token() // [
+//     for dec.More() {
+//       var it Item
+//       if err := dec.Decode(&it); err != nil {
+//         return err
+//       }
+//     }
+//     dec.Token() // ]
+//
+type Decoder struct {
+	br       *bufio.Reader
+	dec      *json.Decoder
+	started  bool
+	number   bool
+	disallow bool
+}
+
+// NewDecoder returns a new Decoder reading from the reader (which is
+// buffered and may be read beyond the values requested).
+func NewDecoder(r io.Reader) *Decoder {
+	br := bufio.NewReader(r)
+	return &Decoder{br: br, dec: json.NewDecoder(br)}
+}
+
+// start skips the BOM (if any) before the first read.
+func (d *Decoder) start() {
+	if d.started {
+		return
+	}
+	d.started = true
+	if bom, _ := d.br.Peek(3); string(bom) == "\xEF\xBB\xBF" {
+		d.br.Discard(3)
+	}
+}
+
+// Decode reads the next JSON value from the stream and unmarshals it
+// into the value (passed by pointer). It returns io.EOF when there are
+// no more values.
+func (d *Decoder) Decode(v any) error {
+	d.start()
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	if !d.number && !d.disallow {
+		return Unmarshal(raw, v)
+	}
+	dec := DefaultEngine.NewDecoder(bytes.NewReader(raw))
+	if d.number {
+		dec.UseNumber()
+	}
+	if d.disallow {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(v)
+}
+
+// More returns true if there is another item in the current array or
+// object being read (or another value in the stream).
+func (d *Decoder) More() bool {
+	d.start()
+	return d.dec.More()
+}
+
+// Token returns the next JSON token in the stream (see Token) or
+// io.EOF at the end.
+func (d *Decoder) Token() (Token, error) {
+	d.start()
+	return d.dec.Token()
+}
+
+// Buffered returns a reader of the data remaining in the buffers of the
+// Decoder followed by the rest of the stream (since the reader is
+// buffered as well, see NewDecoder).
+func (d *Decoder) Buffered() io.Reader {
+	return io.MultiReader(d.dec.Buffered(), d.br)
+}
+
+// InputOffset returns the offset in bytes of the current position in
+// the stream (after any BOM).
+func (d *Decoder) InputOffset() int64 { return d.dec.InputOffset() }
+
+// UseNumber causes numbers decoded into an interface to be json.Number
+// (encoding/json) instead of float64 (using the DefaultEngine
+// directly).
+func (d *Decoder) UseNumber() { d.number = true }
+
+// DisallowUnknownFields causes an error when decoding an object with
+// keys not matching any field of the destination struct (using the
+// DefaultEngine directly).
+func (d *Decoder) DisallowUnknownFields() { d.disallow = true }
@@ -0,0 +1,67 @@
+package json_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDecoder() {
+	in := "\xEF\xBB\xBF" + `{"items": [{"name": "<a>"}, {"name": "b&c"}], "n": 1}`
+	dec := json.NewDecoder(strings.NewReader(in))
+
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if t == "items" {
+			break
+		}
+	}
+	dec.Token() // [
+	for dec.More() {
+		var it json.This
+		if err := dec.Decode(&it); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(it)
+	}
+	t, _ := dec.Token()
+	fmt.Println(t == json.Delim(']'))
+
+	// Output:
+	// {"name":"<a>"}
+	// {"name":"b&c"}
+	// true
+}
+
+func ExampleDecoder_lines() {
+	dec := json.NewDecoder(strings.NewReader("{\"n\":1}\n{\"n\":2.5}\n"))
+	dec.UseNumber()
+	for dec.More() {
+		var v map[string]any
+		dec.Decode(&v)
+		fmt.Printf("%T %v\n", v["n"], v["n"])
+	}
+	// Output:
+	// json.Number 1
+	// json.Number 2.5
+}
+
+func ExampleDecoder_Buffered() {
+	trailing := strings.Repeat(`x`, 5000)
+	dec := json.NewDecoder(strings.NewReader(`{"a":1} ` + trailing))
+	var v map[string]int
+	fmt.Println(dec.Decode(&v), v)
+	rest, _ := io.ReadAll(dec.Buffered())
+	fmt.Println(len(rest), strings.TrimSpace(string(rest)) == trailing)
+
+	// Output:
+	// <nil> map[a:1]
+	// 5001 true
+}
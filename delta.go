@@ -0,0 +1,201 @@
+package json
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// DeltaIdentity are the object keys (tried in order) that identify an
+// object in an array (see DeltaEncode) even when its other values
+// change, an "id", for example. Objects without any are identified by
+// their entire value.
+var DeltaIdentity []string
+
+// DeltaEncode returns the compact delta between the old and new JSON
+// documents as a JSON Patch (RFC 6902) so that storing or transmitting
+// successive versions of large documents (state snapshots, for example)
+// costs only what changed (see DeltaApply). Objects are compared key by
+// key and arrays item by item (after their common beginning and end)
+// with only the smallest changed values replaced. Objects and arrays
+// found elsewhere in the new array (by DeltaIdentity or entire value)
+// are moved rather than removed and added again, which keeps the delta
+// of a reordered list small.
+func DeltaEncode(old, new []byte) ([]byte, error) {
+	var a, b any
+	if err := Unmarshal(old, &a); err != nil {
+		return nil, err
+	}
+	if err := Unmarshal(new, &b); err != nil {
+		return nil, err
+	}
+	ops := diffOps(a, b, ``, nil)
+	if ops == nil {
+		ops = []PatchOp{}
+	}
+	return Marshal(ops)
+}
+
+// DeltaApply applies the delta (from DeltaEncode or any JSON Patch) to
+// the old JSON document returning the new one (compact, keys sorted).
+func DeltaApply(old, delta []byte) ([]byte, error) {
+	var ops []PatchOp
+	if err := Unmarshal(delta, &ops); err != nil {
+		return nil, err
+	}
+	d := new(Document)
+	if err := Unmarshal(old, &d.root); err != nil {
+		return nil, err
+	}
+	if _, _, err := d.applyAll(ops); err != nil {
+		return nil, err
+	}
+	return Marshal(d.root)
+}
+
+// diffOps appends the operations that change a into b (both in generic
+// form) at the JSON Pointer.
+func diffOps(a, b any, ptr string, ops []PatchOp) []PatchOp {
+	switch at := a.(type) {
+
+	case map[string]any:
+		bt, is := b.(map[string]any)
+		if !is {
+			break
+		}
+		keys := make([]string, 0, len(at)+len(bt))
+		for k := range at {
+			keys = append(keys, k)
+		}
+		for k := range bt {
+			if _, has := at[k]; !has {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kp := ptr + `/` + pointerEscaper.Replace(k)
+			av, ina := at[k]
+			bv, inb := bt[k]
+			switch {
+			case !inb:
+				ops = append(ops, PatchOp{Op: `remove`, Path: kp})
+			case !ina:
+				ops = append(ops, PatchOp{Op: `add`, Path: kp, Value: bv})
+			default:
+				ops = diffOps(av, bv, kp, ops)
+			}
+		}
+		return ops
+
+	case []any:
+		bt, is := b.([]any)
+		if !is {
+			break
+		}
+		p := 0
+		for p < len(at) && p < len(bt) && reflect.DeepEqual(at[p], bt[p]) {
+			p++
+		}
+		s := 0
+		for s < len(at)-p && s < len(bt)-p &&
+			reflect.DeepEqual(at[len(at)-1-s], bt[len(bt)-1-s]) {
+			s++
+		}
+		ops = diffItems(at[p:len(at)-s], bt[p:len(bt)-s], ptr, p, ops)
+		return ops
+
+	}
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, PatchOp{Op: `replace`, Path: ptr, Value: b})
+	}
+	return ops
+}
+
+// diffItems appends the operations that change the items of array a
+// into those of b (starting at index p of the array at the JSON
+// Pointer). Objects and arrays in both (see DeltaIdentity) are moved
+// into place and then changed. Everything else is changed in place,
+// with any extra removed from (or added to) the end.
+func diffItems(a, b []any, ptr string, p int, ops []PatchOp) []PatchOp {
+	at := func(i int) string { return ptr + `/` + strconv.Itoa(p+i) }
+
+	// match items of b to those of a with the same identity
+	ids := map[string]int{}
+	for _, v := range a {
+		if id, ok := deltaID(v); ok {
+			ids[id]++
+		}
+	}
+	wanted := map[string]int{}
+	for _, v := range b {
+		if id, ok := deltaID(v); ok && ids[id] > 0 {
+			ids[id]--
+			wanted[id]++
+		}
+	}
+	type item struct {
+		v  any
+		id string // empty if free (no match in b)
+	}
+	cur := make([]item, 0, len(a))
+	for _, v := range a {
+		id, ok := deltaID(v)
+		if !ok || wanted[id] == 0 {
+			id = ``
+		} else {
+			wanted[id]--
+		}
+		cur = append(cur, item{v, id})
+	}
+
+	for i, v := range b {
+		id, ok := deltaID(v)
+		j := -1
+		if ok {
+			for n := i; n < len(cur); n++ {
+				if cur[n].id == id {
+					j = n
+					break
+				}
+			}
+		}
+		switch {
+		case j > i:
+			ops = append(ops, PatchOp{Op: `move`, From: at(j), Path: at(i)})
+			moved := cur[j]
+			copy(cur[i+1:j+1], cur[i:j])
+			cur[i] = moved
+		case j < 0 && (i >= len(cur) || cur[i].id != ``):
+			ops = append(ops, PatchOp{Op: `add`, Path: at(i), Value: v})
+			cur = append(cur, item{})
+			copy(cur[i+1:], cur[i:])
+			cur[i] = item{v, id}
+			continue
+		}
+		ops = diffOps(cur[i].v, v, at(i), ops)
+		cur[i].id = id
+	}
+	for n := len(cur) - 1; n >= len(b); n-- {
+		ops = append(ops, PatchOp{Op: `remove`, Path: at(len(b))})
+	}
+	return ops
+}
+
+// deltaID returns the identity of an object (see DeltaIdentity) or
+// array. Scalars have none since replacing one costs no more than
+// moving it.
+func deltaID(v any) (string, bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		for _, k := range DeltaIdentity {
+			if id, has := t[k]; has {
+				return `key:` + k + `=` + This{id}.String(), true
+			}
+		}
+	case []any:
+	default:
+		return ``, false
+	}
+	return `value:` + This{v}.String(), true
+}
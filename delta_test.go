@@ -0,0 +1,44 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDeltaEncode() {
+	v1 := []byte(`{"name":"app","hosts":["a","b","c","d"],"limits":{"cpu":1,"mem":512},"old":true}`)
+	v2 := []byte(`{"name":"app","hosts":["a","x","d","e"],"limits":{"cpu":2,"mem":512},"new/key":1}`)
+
+	delta, _ := json.DeltaEncode(v1, v2)
+	fmt.Println(string(delta))
+
+	got, err := json.DeltaApply(v1, delta)
+	fmt.Println(string(got), err)
+
+	same, _ := json.DeltaEncode(v1, v1)
+	fmt.Println(string(same))
+
+	// Output:
+	// [{"op":"replace","path":"/hosts/1","value":"x"},{"op":"replace","path":"/hosts/2","value":"d"},{"op":"replace","path":"/hosts/3","value":"e"},{"op":"replace","path":"/limits/cpu","value":2},{"op":"add","path":"/new~1key","value":1},{"op":"remove","path":"/old"}]
+	// {"hosts":["a","x","d","e"],"limits":{"cpu":2,"mem":512},"name":"app","new/key":1} <nil>
+	// []
+}
+
+func ExampleDeltaIdentity() {
+	json.DeltaIdentity = []string{`id`}
+	defer func() { json.DeltaIdentity = nil }()
+
+	v1 := []byte(`{"users":[{"id":1,"name":"ann","bio":"..."},{"id":2,"name":"bob","bio":"..."},{"id":3,"name":"cat","bio":"..."}]}`)
+	v2 := []byte(`{"users":[{"id":3,"name":"cat","bio":"..."},{"id":1,"name":"ann","bio":"..."},{"id":2,"name":"rob","bio":"..."},{"id":4,"name":"dan"}]}`)
+
+	delta, _ := json.DeltaEncode(v1, v2)
+	fmt.Println(string(delta))
+
+	got, err := json.DeltaApply(v1, delta)
+	fmt.Println(string(got), err)
+
+	// Output:
+	// [{"op":"move","path":"/users/0","from":"/users/2"},{"op":"replace","path":"/users/2/name","value":"rob"},{"op":"add","path":"/users/3","value":{"id":4,"name":"dan"}}]
+	// {"users":[{"bio":"...","id":3,"name":"cat"},{"bio":"...","id":1,"name":"ann"},{"bio":"...","id":2,"name":"rob"},{"id":4,"name":"dan"}]} <nil>
+}
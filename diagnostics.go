@@ -0,0 +1,273 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Severities of a Diagnostic (the same as the Language Server Protocol).
+const (
+	SeverityError   = 1
+	SeverityWarning = 2
+	SeverityInfo    = 3
+	SeverityHint    = 4
+)
+
+// Position is a zero-based line and character (in UTF-16 code units) in
+// a document the same as the Language Server Protocol. Offset is the
+// byte offset (not part of the protocol).
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+	Offset    int `json:"-"`
+}
+
+// Range is the part of a document from Start up to (but not including)
+// End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is a single problem found in a document by Diagnostics in
+// the same shape as the Language Server Protocol so that it can be sent
+// as is in textDocument/publishDiagnostics notifications. Path (not
+// part of the protocol) is that of the value (see Path).
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+	Path     string `json:"-"`
+}
+
+// Diagnostics returns every problem with the JSON document for editor
+// plugins (see Diagnostic): a syntax error (after which nothing else is
+// checked), duplicate keys (warnings), and (if the schema is not nil)
+// every schema violation (see ValidateSchema) with the range of the
+// offending value (or key for those not allowed, or closest existing
+// parent for those missing). A leading byte order mark (BOM) is
+// ignored. No problems returns an empty slice.
+func Diagnostics(buf []byte, schema []byte) []Diagnostic {
+	out := []Diagnostic{}
+	lines := newLineIndex(buf)
+	diag := func(sev int, s span, p, format string, args ...any) {
+		out = append(out, Diagnostic{
+			Range:    Range{lines.position(s.start), lines.position(s.end)},
+			Severity: sev,
+			Source:   `json`,
+			Message:  fmt.Sprintf(format, args...),
+			Path:     p,
+		})
+	}
+
+	start := 0
+	if len(buf) >= 3 && string(buf[:3]) == "\xEF\xBB\xBF" {
+		start = 3
+	}
+	var v any
+	if err := json.Unmarshal(buf[start:], &v); err != nil {
+		var serr *json.SyntaxError
+		switch {
+		case errors.As(err, &serr):
+			at := start + int(serr.Offset)
+			if at > start {
+				at--
+			}
+			diag(SeverityError, span{at, at + 1}, ``, `%v`, serr)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			diag(SeverityError, span{len(buf), len(buf)}, ``, `unexpected end of JSON input`)
+		default:
+			diag(SeverityError, span{start, len(buf)}, ``, `%v`, err)
+		}
+		return out
+	}
+
+	sp := &spanner{buf: buf, i: start, vals: map[string]span{}, keys: map[string]span{}}
+	sp.value(Path{})
+	for _, d := range sp.dups {
+		diag(SeverityWarning, d.key, d.path, `duplicate key %s (last one wins)`, quote(d.name))
+	}
+
+	if schema == nil {
+		return out
+	}
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		diag(SeverityError, span{start, start}, ``, `invalid schema: %v`, err)
+		return out
+	}
+	err := validate(s, s, v)
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		return out
+	}
+	for _, viol := range serr.Violations {
+		diag(SeverityError, sp.find(viol.Path, viol.Msg == `not allowed`), viol.Path, `%s`, viol.Msg)
+	}
+	return out
+}
+
+// span is a range of bytes.
+type span struct{ start, end int }
+
+type dupKey struct {
+	name string
+	path string
+	key  span
+}
+
+// spanner records the span of every value (and key) of valid JSON by
+// Path.
+type spanner struct {
+	buf  []byte
+	i    int
+	vals map[string]span
+	keys map[string]span
+	dups []dupKey
+}
+
+func (sp *spanner) ws() {
+	for sp.i < len(sp.buf) {
+		switch sp.buf[sp.i] {
+		case ' ', '\t', '\r', '\n':
+			sp.i++
+		default:
+			return
+		}
+	}
+}
+
+// str skips a string (starting at the quote) returning its body.
+func (sp *spanner) str() string {
+	start := sp.i
+	sp.i++
+	for sp.i < len(sp.buf) && sp.buf[sp.i] != '"' {
+		if sp.buf[sp.i] == '\\' {
+			sp.i++
+		}
+		sp.i++
+	}
+	sp.i++
+	return string(sp.buf[start+1 : sp.i-1])
+}
+
+func (sp *spanner) value(p Path) {
+	sp.ws()
+	start := sp.i
+	switch sp.buf[sp.i] {
+	case '{':
+		sp.i++
+		seen := map[string]bool{}
+		for {
+			sp.ws()
+			if sp.buf[sp.i] == '}' {
+				sp.i++
+				break
+			}
+			kstart := sp.i
+			raw := sp.str()
+			name, _ := Unescape(raw)
+			kp := p.Key(name)
+			ks := span{kstart, sp.i}
+			if seen[name] {
+				sp.dups = append(sp.dups, dupKey{name, kp.String(), ks})
+			}
+			seen[name] = true
+			sp.keys[kp.String()] = ks
+			sp.ws()
+			sp.i++ // :
+			sp.value(kp)
+			sp.ws()
+			if sp.buf[sp.i] == ',' {
+				sp.i++
+			}
+		}
+	case '[':
+		sp.i++
+		for n := 0; ; n++ {
+			sp.ws()
+			if sp.buf[sp.i] == ']' {
+				sp.i++
+				break
+			}
+			sp.value(p.Index(n))
+			sp.ws()
+			if sp.buf[sp.i] == ',' {
+				sp.i++
+			}
+		}
+	case '"':
+		sp.str()
+	default:
+		for sp.i < len(sp.buf) {
+			c := sp.buf[sp.i]
+			if c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+				break
+			}
+			sp.i++
+		}
+	}
+	sp.vals[p.String()] = span{start, sp.i}
+}
+
+// find returns the span of the value (or key) with the path or of its
+// closest existing parent.
+func (sp *spanner) find(path string, key bool) span {
+	if key {
+		if s, has := sp.keys[path]; has {
+			return s
+		}
+	}
+	p, err := ParsePath(path)
+	if err != nil {
+		return sp.vals[`.`]
+	}
+	for {
+		if s, has := sp.vals[p.String()]; has {
+			return s
+		}
+		if len(p) == 0 {
+			return span{}
+		}
+		p = p[:len(p)-1]
+	}
+}
+
+// lineIndex converts byte offsets into Positions.
+type lineIndex struct {
+	buf    []byte
+	starts []int // byte offset of every line
+}
+
+func newLineIndex(buf []byte) *lineIndex {
+	x := &lineIndex{buf: buf, starts: []int{0}}
+	for i, b := range buf {
+		if b == '\n' {
+			x.starts = append(x.starts, i+1)
+		}
+	}
+	return x
+}
+
+// position returns the Position of the byte offset.
+func (x *lineIndex) position(off int) Position {
+	line := 0
+	for line+1 < len(x.starts) && x.starts[line+1] <= off {
+		line++
+	}
+	char := 0
+	for i := x.starts[line]; i < off && i < len(x.buf); {
+		r, size := utf8.DecodeRune(x.buf[i:])
+		if r >= 0x10000 {
+			char += 2
+		} else {
+			char++
+		}
+		i += size
+	}
+	return Position{line, char, off}
+}
@@ -0,0 +1,40 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDiagnostics() {
+	schema := []byte(`{
+	  "type": "object",
+	  "required": ["name"],
+	  "properties": {"port": {"type": "integer", "maximum": 65535}},
+	  "additionalProperties": false
+	}`)
+	doc := []byte("{\n  \"port\": 80,\n  \"port\": 99999,\n  \"💢\": true\n}")
+
+	for _, d := range json.Diagnostics(doc, schema) {
+		r := d.Range
+		fmt.Printf("%d:%d-%d:%d %d %s %s\n", r.Start.Line, r.Start.Character,
+			r.End.Line, r.End.Character, d.Severity, d.Path, d.Message)
+	}
+
+	d := json.Diagnostics([]byte("{\n  \"a\": tru\n}"), nil)
+	fmt.Println(d[0].Range.Start.Line, d[0].Range.Start.Character, d[0].Message)
+
+	buf, _ := json.Marshal(json.Diagnostics([]byte(`[1,]`), nil))
+	fmt.Println(string(buf))
+
+	fmt.Println(len(json.Diagnostics([]byte(`{"a":1}`), nil)))
+
+	// Output:
+	// 2:2-2:8 2 .port duplicate key "port" (last one wins)
+	// 0:0-4:1 1 .name required
+	// 2:10-2:15 1 .port greater than maximum 65535
+	// 3:2-3:6 1 ."💢" not allowed
+	// 1 10 invalid character '\n' in literal true (expecting 'e')
+	// [{"range":{"start":{"line":0,"character":3},"end":{"line":0,"character":4}},"severity":1,"source":"json","message":"invalid character ']' looking for beginning of value"}]
+	// 0
+}
@@ -0,0 +1,172 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DiffKind is the kind of a Difference (see Diff).
+type DiffKind int
+
+const (
+	DiffChanged DiffKind = iota // value (or its type) is different
+	DiffAdded                   // only in the second document
+	DiffRemoved                 // only in the first document
+)
+
+// String fulfills the fmt.Stringer interface.
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return `added`
+	case DiffRemoved:
+		return `removed`
+	}
+	return `changed`
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface (so that
+// Differences marshal with the kind as a string).
+func (k DiffKind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// Difference is a single place where two documents diverge (see Diff)
+// with the values (in generic form) of the first (Old) and second
+// (New) documents at the Path (nil when removed or added).
+type Difference struct {
+	Path Path     `json:"path"`
+	Kind DiffKind `json:"kind"`
+	Old  any      `json:"old,omitempty"`
+	New  any      `json:"new,omitempty"`
+}
+
+// String fulfills the fmt.Stringer interface with the Difference on
+// a single line (see Differences).
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return `+ ` + d.Path.String() + `: ` + This{d.New}.String()
+	case DiffRemoved:
+		return `- ` + d.Path.String() + `: ` + This{d.Old}.String()
+	}
+	return `~ ` + d.Path.String() + `: ` + This{d.Old}.String() + ` -> ` + This{d.New}.String()
+}
+
+// Differences are the differences returned by Diff.
+type Differences []Difference
+
+const diffChanged = "\033[33m"
+
+// Text returns the differences one per line (see Difference.String)
+// for showing exactly where two documents diverge in test failures and
+// terminals, with added (green), removed (red), and changed (yellow)
+// lines in ANSI terminal colors (if color):
+//
+//     ~ .spec.replicas: 2 -> 3
+//     + .spec.labels.env: "prod"
+//     - .spec.tags[2]: "old"
+//
+func (ds Differences) Text(color bool) string {
+	var b strings.Builder
+	for _, d := range ds {
+		line := d.String()
+		if color {
+			c := diffChanged
+			switch d.Kind {
+			case DiffAdded:
+				c = unifiedAdd
+			case DiffRemoved:
+				c = unifiedDel
+			}
+			line = c + line + unifiedReset
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// String fulfills the fmt.Stringer interface (see Text).
+func (ds Differences) String() string { return ds.Text(false) }
+
+// Diff returns every Difference between the two JSON documents (empty
+// if they are equal) in document order (keys sorted so that their
+// order never matters). Objects are compared key by key and arrays
+// item by item after skipping any items equal at the beginning and end
+// (so that inserting or removing one is a single Difference) with any
+// extra items of either added or removed. Everything else (including
+// values of different types) is changed if not equal. See DiffUnified
+// for a rendering of the entire documents instead.
+func Diff(a, b []byte) (Differences, error) {
+	var av, bv any
+	if err := Unmarshal(a, &av); err != nil {
+		return nil, err
+	}
+	if err := Unmarshal(b, &bv); err != nil {
+		return nil, err
+	}
+	return diffValues(av, bv, Path{}, nil), nil
+}
+
+// diffValues appends the differences between the values (in generic
+// form) at the Path.
+func diffValues(a, b any, p Path, ds Differences) Differences {
+	switch at := a.(type) {
+
+	case map[string]any:
+		bt, is := b.(map[string]any)
+		if !is {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range at {
+			keys[k] = true
+		}
+		for k := range bt {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			av, ina := at[k]
+			bv, inb := bt[k]
+			switch {
+			case !inb:
+				ds = append(ds, Difference{p.Key(k), DiffRemoved, av, nil})
+			case !ina:
+				ds = append(ds, Difference{p.Key(k), DiffAdded, nil, bv})
+			default:
+				ds = diffValues(av, bv, p.Key(k), ds)
+			}
+		}
+		return ds
+
+	case []any:
+		bt, is := b.([]any)
+		if !is {
+			break
+		}
+		pre := 0
+		for pre < len(at) && pre < len(bt) && reflect.DeepEqual(at[pre], bt[pre]) {
+			pre++
+		}
+		suf := 0
+		for suf < len(at)-pre && suf < len(bt)-pre &&
+			reflect.DeepEqual(at[len(at)-1-suf], bt[len(bt)-1-suf]) {
+			suf++
+		}
+		am, bm := at[pre:len(at)-suf], bt[pre:len(bt)-suf]
+		for i := 0; i < len(am) || i < len(bm); i++ {
+			switch {
+			case i >= len(bm):
+				ds = append(ds, Difference{p.Index(pre + i), DiffRemoved, am[i], nil})
+			case i >= len(am):
+				ds = append(ds, Difference{p.Index(pre + i), DiffAdded, nil, bm[i]})
+			default:
+				ds = diffValues(am[i], bm[i], p.Index(pre+i), ds)
+			}
+		}
+		return ds
+
+	}
+	if !reflect.DeepEqual(a, b) {
+		ds = append(ds, Difference{p, DiffChanged, a, b})
+	}
+	return ds
+}
@@ -0,0 +1,41 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDiff() {
+	a := []byte(`{
+		"name": "web",
+		"spec": {"replicas": 2, "tags": ["a", "b", "old", "z"], "port": 80},
+		"debug": true
+	}`)
+	b := []byte(`{
+		"spec": {"tags": ["a", "b", "z"], "replicas": 3, "port": "80", "labels": {"env": "prod"}},
+		"name": "web"
+	}`)
+
+	ds, err := json.Diff(a, b)
+	fmt.Println(err)
+	fmt.Print(ds)
+
+	fmt.Println(ds[0].Path, ds[0].Kind, ds[0].Old)
+	buf, _ := json.Marshal(ds[1])
+	fmt.Println(string(buf))
+
+	same, _ := json.Diff([]byte(`{"a":[1,2]}`), []byte(`{ "a": [1, 2] }`))
+	fmt.Println(len(same))
+
+	// Output:
+	// <nil>
+	// - .debug: true
+	// + .spec.labels: {"env":"prod"}
+	// ~ .spec.port: 80 -> "80"
+	// ~ .spec.replicas: 2 -> 3
+	// - .spec.tags[2]: "old"
+	// .debug removed true
+	// {"path":".spec.labels","kind":"added","new":{"env":"prod"}}
+	// 0
+}
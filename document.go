@@ -0,0 +1,379 @@
+package json
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Document is a mutable JSON document held in generic form
+// (map[string]any, []any, string, float64, bool, and nil) that is
+// edited by Path expressions and that notifies subscribers of every
+// change affecting the part of the document they care about (see
+// Subscribe). Every change is journaled as JSON Patch operations for
+// Undo, Redo, and History (see JournalMax). Documents are safe for
+// concurrent use. The zero value is an empty (null) document.
+type Document struct {
+	mu     sync.Mutex
+	root   any
+	subs   []*subscription
+	nsub   int
+	done   []journalEntry // for Undo (see journal.go)
+	undone []journalEntry // for Redo
+}
+
+// Change describes a single change to a Document using the operation
+// names from JSON Patch (RFC 6902).
+type Change struct {
+	Op    string // add, replace, or remove
+	Path  Path   // normalized (no negative indexes)
+	Value any    // the new value (nil for remove)
+}
+
+type subscription struct {
+	id   int
+	path Path
+	fn   func(Change)
+}
+
+// NewDocument returns a new Document from any value that can be
+// marshaled into JSON (the value is converted into generic form).
+func NewDocument(v any) (*Document, error) {
+	root, err := generic(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{root: root}, nil
+}
+
+// generic converts any value into its generic decoded JSON form.
+func generic(v any) (any, error) {
+	buf, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var g any
+	err = Unmarshal(buf, &g)
+	return g, err
+}
+
+// Value returns the entire document in generic form. It must not be
+// modified (and may be modified by later changes to the Document).
+func (d *Document) Value() any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root
+}
+
+// MarshalJSON fulfills the encoding/json.Marshaler interface.
+func (d *Document) MarshalJSON() ([]byte, error) { return Marshal(d.Value()) }
+
+// Get returns the single value at the path (see Path). Paths selecting
+// more than one value ([]) are an error.
+func (d *Document) Get(path string) (any, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return getIn(d.root, p)
+}
+
+// getIn returns the single value at the path within cur.
+func getIn(cur any, p Path) (any, error) {
+	vals, err := p.Select(cur)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 1 {
+		return nil, fmt.Errorf(`path selects %d values: %v`, len(vals), p)
+	}
+	return vals[0], nil
+}
+
+// Set sets the value at the path (which may not contain []) creating
+// any missing objects along the way. Setting the index just past the
+// end of an array appends to it.
+func (d *Document) Set(path string, v any) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	val, err := generic(v)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	old, oerr := getIn(d.root, p)
+	k := missingAt(d.root, p)
+	norm := Path{}
+	root, op, err := setIn(d.root, p, val, &norm)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.root = root
+	if op == `replace` && oerr == nil {
+		d.record(journalEntry{
+			do:   []PatchOp{{Op: `replace`, Path: pointerOf(norm), Value: val}},
+			undo: []PatchOp{{Op: `replace`, Path: pointerOf(norm), Value: old}},
+		})
+	} else {
+		added, _ := getIn(d.root, norm[:k])
+		d.record(journalEntry{
+			do:   []PatchOp{{Op: `add`, Path: pointerOf(norm[:k]), Value: added}},
+			undo: []PatchOp{{Op: `remove`, Path: pointerOf(norm[:k])}},
+		})
+	}
+	d.notify(Change{op, norm, val})
+	return nil
+}
+
+// missingAt returns the number of steps of the path to the first value
+// that does not exist within cur (which Set creates).
+func missingAt(cur any, p Path) int {
+	if len(p) == 0 {
+		return 0
+	}
+	for k, step := range p[:len(p)-1] {
+		switch t := cur.(type) {
+		case map[string]any:
+			c, has := t[step.Key]
+			if !has {
+				return k + 1
+			}
+			cur = c
+		case []any:
+			i := absIndex(*step.Index, len(t))
+			if i < 0 || i >= len(t) {
+				return k + 1
+			}
+			cur = t[i]
+		default:
+			return k + 1
+		}
+	}
+	return len(p)
+}
+
+// Delete removes the value at the path (which may not contain []).
+// Removing an array item shifts the items after it. Deleting the entire
+// document (.) leaves it null.
+func (d *Document) Delete(path string) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	old, _ := getIn(d.root, p)
+	norm := Path{}
+	root, err := deleteIn(d.root, p, &norm)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.root = root
+	if len(norm) == 0 {
+		d.record(journalEntry{
+			do:   []PatchOp{{Op: `replace`, Path: ``, Value: nil}},
+			undo: []PatchOp{{Op: `replace`, Path: ``, Value: old}},
+		})
+	} else {
+		d.record(journalEntry{
+			do:   []PatchOp{{Op: `remove`, Path: pointerOf(norm)}},
+			undo: []PatchOp{{Op: `add`, Path: pointerOf(norm), Value: old}},
+		})
+	}
+	d.notify(Change{Op: `remove`, Path: norm})
+	return nil
+}
+
+// Subscribe registers the function to be called after every change
+// that affects the subtree at the path: changes within it as well as
+// changes to any of its parents (which replace it). Each [] in the path
+// matches any key or index. Functions are called synchronously in the
+// order subscribed (after the change is complete, so they may safely
+// read the Document). The returned function cancels the subscription.
+func (d *Document) Subscribe(path string, fn func(Change)) (func(), error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nsub++
+	id := d.nsub
+	d.subs = append(d.subs, &subscription{id, p, fn})
+	cancel := func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for i, s := range d.subs {
+			if s.id == id {
+				d.subs = append(d.subs[:i:i], d.subs[i+1:]...)
+				return
+			}
+		}
+	}
+	return cancel, nil
+}
+
+// notify unlocks the Document and calls every subscription affected by
+// each of the changes (in order).
+func (d *Document) notify(changes ...Change) {
+	type call struct {
+		fn func(Change)
+		c  Change
+	}
+	var calls []call
+	for _, c := range changes {
+		for _, s := range d.subs {
+			if overlaps(s.path, c.Path) {
+				calls = append(calls, call{s.fn, c})
+			}
+		}
+	}
+	d.mu.Unlock()
+	for _, call := range calls {
+		call.fn(call.c)
+	}
+}
+
+// overlaps returns true if either path is within the other.
+func overlaps(sub, changed Path) bool {
+	for i := 0; i < len(sub) && i < len(changed); i++ {
+		s, c := sub[i], changed[i]
+		switch {
+		case s.Each:
+		case s.Index != nil && c.Index != nil:
+			if *s.Index != *c.Index {
+				return false
+			}
+		case s.Index == nil && c.Index == nil:
+			if s.Key != c.Key {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// absIndex returns the absolute index (counting negative from the end).
+func absIndex(i, n int) int {
+	if i < 0 {
+		return i + n
+	}
+	return i
+}
+
+// setIn sets the value at the path within cur returning the new cur
+// and the operation (add or replace) while appending the normalized
+// steps taken to norm.
+func setIn(cur any, p Path, v any, norm *Path) (any, string, error) {
+	if len(p) == 0 {
+		return v, `replace`, nil
+	}
+	step := p[0]
+	switch {
+
+	case step.Each:
+		return nil, ``, fmt.Errorf(`cannot set every item ([])`)
+
+	case step.Index != nil:
+		a, is := cur.([]any)
+		if !is && cur != nil {
+			return nil, ``, fmt.Errorf(`cannot index %T with %v`, cur, step)
+		}
+		i := absIndex(*step.Index, len(a))
+		*norm = append(*norm, PathStep{Index: &i})
+		if len(p) == 1 && i == len(a) {
+			return append(a, v), `add`, nil
+		}
+		if i < 0 || i >= len(a) {
+			return nil, ``, fmt.Errorf(`index out of range: %v`, step)
+		}
+		item, op, err := setIn(a[i], p[1:], v, norm)
+		if err != nil {
+			return nil, ``, err
+		}
+		a[i] = item
+		return a, op, nil
+
+	}
+
+	m, is := cur.(map[string]any)
+	if !is {
+		if cur != nil {
+			return nil, ``, fmt.Errorf(`cannot index %T with %v`, cur, step)
+		}
+		m = map[string]any{}
+	}
+	*norm = append(*norm, step)
+	child, has := m[step.Key]
+	if len(p) == 1 {
+		m[step.Key] = v
+		if has {
+			return m, `replace`, nil
+		}
+		return m, `add`, nil
+	}
+	child, op, err := setIn(child, p[1:], v, norm)
+	if err != nil {
+		return nil, ``, err
+	}
+	m[step.Key] = child
+	return m, op, nil
+}
+
+// deleteIn removes the value at the path within cur returning the new
+// cur while appending the normalized steps taken to norm.
+func deleteIn(cur any, p Path, norm *Path) (any, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	step := p[0]
+	switch t := cur.(type) {
+
+	case map[string]any:
+		if step.Each || step.Index != nil {
+			return nil, fmt.Errorf(`cannot index object with %v`, step)
+		}
+		*norm = append(*norm, step)
+		child, has := t[step.Key]
+		if !has {
+			return nil, fmt.Errorf(`no such key: %v`, *norm)
+		}
+		if len(p) == 1 {
+			delete(t, step.Key)
+			return t, nil
+		}
+		child, err := deleteIn(child, p[1:], norm)
+		if err != nil {
+			return nil, err
+		}
+		t[step.Key] = child
+		return t, nil
+
+	case []any:
+		if step.Index == nil {
+			return nil, fmt.Errorf(`cannot index array with %v`, step)
+		}
+		i := absIndex(*step.Index, len(t))
+		*norm = append(*norm, PathStep{Index: &i})
+		if i < 0 || i >= len(t) {
+			return nil, fmt.Errorf(`index out of range: %v`, step)
+		}
+		if len(p) == 1 {
+			return append(t[:i:i], t[i+1:]...), nil
+		}
+		item, err := deleteIn(t[i], p[1:], norm)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = item
+		return t, nil
+
+	}
+	return nil, fmt.Errorf(`cannot index %T with %v`, cur, step)
+}
@@ -0,0 +1,54 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDocument_Subscribe() {
+	doc, _ := json.NewDocument(map[string]any{
+		"name":  "app",
+		"hosts": []string{"a", "b"},
+	})
+
+	show := func(c json.Change) { fmt.Println(c.Op, c.Path, c.Value) }
+	doc.Subscribe(`.hosts`, show)
+	cancel, _ := doc.Subscribe(`.name`, show)
+
+	doc.Set(`.hosts[-1]`, "c")
+	doc.Set(`.hosts[2]`, "d")
+	doc.Set(`.name`, "other")
+	cancel()
+	doc.Set(`.name`, "ignored")
+	doc.Delete(`.hosts[0]`)
+	doc.Set(`.`, map[string]any{"hosts": nil})
+	doc.Set(`.port`, 8080)
+
+	fmt.Println(json.This{doc})
+
+	// Output:
+	// replace .hosts[1] c
+	// add .hosts[2] d
+	// replace .name other
+	// remove .hosts[0] <nil>
+	// replace . map[hosts:<nil>]
+	// {"hosts":null,"port":8080}
+}
+
+func ExampleDocument_Get() {
+	doc := new(json.Document)
+	doc.Set(`.a.b[0]`, true)
+	fmt.Println(doc.Get(`.a.b[0]`))
+	fmt.Println(doc.Get(`.a.missing`))
+	fmt.Println(doc.Get(`.a.b[]`))
+	fmt.Println(doc.Set(`.a.b[5]`, 1))
+	fmt.Println(doc.Delete(`.a.nope`))
+
+	// Output:
+	// true <nil>
+	// <nil> <nil>
+	// true <nil>
+	// index out of range: [5]
+	// no such key: .a.nope
+}
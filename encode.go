@@ -0,0 +1,870 @@
+package json
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	numberType        = reflect.TypeOf(json.Number(""))
+)
+
+// compactTypes contains the types registered with RegisterCompact.
+var compactTypes sync.Map
+
+// RegisterCompact registers the types of the values passed so that
+// they are always rendered compactly on a single line inside of
+// otherwise indented output from MarshalIndent. This is particularly
+// useful for coordinate pairs, small tuples, and matrices of numeric
+// data that humans prefer to read in this compact-matrix style:
+//
+//	json.RegisterCompact([2]float64{}, Point{})
+//
+// Registration is package-wide and cannot be undone (like most type
+// registrations). Pointers to registered types are also rendered
+// compactly.
+func RegisterCompact(v ...any) {
+	for _, i := range v {
+		compactTypes.Store(reflect.TypeOf(i), true)
+	}
+}
+
+// hasCompact returns true if the type (at any depth) may reach any
+// type registered with RegisterCompact (as it may through any
+// interface).
+func hasCompact(t reflect.Type) bool {
+	has := false
+	compactTypes.Range(func(_, _ any) bool { has = true; return false })
+	return has && t != nil && compactIn(t, map[reflect.Type]bool{})
+}
+
+func compactIn(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if isCompact(t) {
+		return true
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if compactIn(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return compactIn(t.Elem(), seen)
+	}
+	return false
+}
+
+// isCompact returns true if the type has been registered with
+// RegisterCompact.
+func isCompact(t reflect.Type) bool {
+	_, is := compactTypes.Load(t)
+	return is
+}
+
+// EncodeChunk is the number of bytes buffered by EncodeTo before they
+// are written.
+var EncodeChunk = 32 * 1024
+
+// EncodeTo writes the value as JSON (the same as Marshal) to the writer
+// in chunks of about EncodeChunk bytes as it goes instead of building
+// the entire output in memory first, so that huge values (big maps and
+// slices, etc.) require no more memory than they already use. Nothing
+// can be assumed about what was written when there is an error. Note
+// that anything that renders itself (json.Marshaler, etc.) is still
+// marshaled entirely before writing.
+func EncodeTo(w io.Writer, v any) error {
+	e := &encoder{buf: new(bytes.Buffer), utf8: InvalidUTF8, w: w}
+	if err := e.value(reflect.ValueOf(v), 0); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// maxDepth mirrors the point at which encoding/json starts checking
+// for pointer cycles (see visit).
+const maxDepth = 1000
+
+// encoder walks any value with reflection writing it as JSON with the
+// same rules as encoding/json but with control over escaping,
+// indentation, and other rendering decisions that the standard encoder
+// does not allow. Nothing is delegated to encoding/json (other than
+// compacting and indenting the output of MarshalJSON methods).
+type encoder struct {
+	buf      *bytes.Buffer
+	prefix   string
+	indent   string
+	maxStr   int          // truncate strings longer than (0 for no limit)
+	maxItems int          // elide array items beyond (0 for no limit)
+	utf8     UTF8Policy   // invalid UTF-8 in strings (see InvalidUTF8)
+	level    int          // pointer, interface, map, and slice nesting
+	seen     map[any]bool // being encoded once past maxDepth (see visit)
+	w        io.Writer    // flush to (see EncodeTo)
+	flushed  int          // bytes already written to w
+	self     bool         // skip MarshalJSON of the top-level value (see MarshalSelf)
+	less     keyOrder     // of map keys (see MarshalSorted)
+}
+
+// keyOrder returns true if the key a goes before b.
+type keyOrder func(a, b string) bool
+
+// flush writes the buffer to the writer (if any) once it holds at least
+// EncodeChunk bytes.
+func (e *encoder) flush() error {
+	if e.w == nil || e.buf.Len() < EncodeChunk {
+		return nil
+	}
+	n, err := e.w.Write(e.buf.Bytes())
+	e.flushed += n
+	e.buf.Reset()
+	return err
+}
+
+// newline writes a line return followed by the prefix and indentation
+// for the given depth (if indenting at all).
+func (e *encoder) newline(depth int) {
+	if e.indent == "" && e.prefix == "" {
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(e.prefix)
+	for i := 0; i < depth; i++ {
+		e.buf.WriteString(e.indent)
+	}
+}
+
+// colon writes the separator between keys and values.
+func (e *encoder) colon() {
+	e.buf.WriteByte(':')
+	if e.indent != "" || e.prefix != "" {
+		e.buf.WriteByte(' ')
+	}
+}
+
+// raw writes already marshaled JSON indenting it (if indenting) to
+// match the given depth.
+func (e *encoder) raw(buf []byte, depth int) error {
+	if e.indent == "" && e.prefix == "" {
+		e.buf.Write(buf)
+		return nil
+	}
+	pre := e.prefix + strings.Repeat(e.indent, depth)
+	return json.Indent(e.buf, buf, pre, e.indent)
+}
+
+// marshaler writes the value rendering itself with MarshalJSON (which
+// may call MarshalSelf) or MarshalText.
+func (e *encoder) marshaler(v reflect.Value, depth int) error {
+	i := iface(v)
+	if m, is := i.(json.Marshaler); is {
+		buf, err := m.MarshalJSON()
+		if err == nil {
+			c := new(bytes.Buffer)
+			if err = json.Compact(c, buf); err == nil {
+				return e.raw(c.Bytes(), depth)
+			}
+		}
+		return &json.MarshalerError{Type: v.Type(), Err: err}
+	}
+	text, err := i.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return &json.MarshalerError{Type: v.Type(), Err: err}
+	}
+	return e.str(string(text))
+}
+
+// compact writes the value on a single line (see RegisterCompact).
+func (e *encoder) compact(v reflect.Value) error {
+	sub := &encoder{buf: e.buf, utf8: e.utf8, level: e.level, seen: e.seen, less: e.less}
+	return sub.value(v, 0)
+}
+
+// scalar writes booleans, numbers, and byte slices.
+func (e *encoder) scalar(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		e.buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		e.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32:
+		return e.float(v, 32)
+	case reflect.Float64:
+		return e.float(v, 64)
+	case reflect.String: // json.Number
+		n := v.String()
+		if n == "" {
+			n = `0`
+		}
+		if !validNumber(n) {
+			return &json.UnsupportedValueError{Value: v, Str: `invalid number literal ` + strconv.Quote(n)}
+		}
+		e.buf.WriteString(n)
+	case reflect.Slice: // []byte
+		e.buf.WriteByte('"')
+		enc := base64.NewEncoder(base64.StdEncoding, e.buf)
+		enc.Write(v.Bytes())
+		enc.Close()
+		e.buf.WriteByte('"')
+	default:
+		return &json.UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+// float writes the floating point number the same as encoding/json
+// (exponents only for very large and very small numbers).
+func (e *encoder) float(v reflect.Value, bits int) error {
+	f := v.Float()
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b := strconv.AppendFloat(nil, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// validNumber returns true if the string is a JSON number.
+func validNumber(s string) bool {
+	if s != "" && s[0] == '-' {
+		s = s[1:]
+	}
+	digits := func() int {
+		n := 0
+		for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+			n++
+		}
+		s = s[n:]
+		return n
+	}
+	switch {
+	case s == "":
+		return false
+	case s[0] == '0':
+		s = s[1:]
+	case digits() == 0:
+		return false
+	}
+	if s != "" && s[0] == '.' {
+		s = s[1:]
+		if digits() == 0 {
+			return false
+		}
+	}
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			s = s[1:]
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return s == ""
+}
+
+// iface returns the interface of the value (or pointer to it if
+// addressable so that pointer receiver methods are observed).
+func iface(v reflect.Value) any {
+	if v.Kind() != reflect.Pointer && v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
+
+// marshals returns true if the value renders itself.
+func marshals(v reflect.Value) bool {
+	t := v.Type()
+	if t.Implements(marshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if v.CanAddr() {
+		p := reflect.PointerTo(t)
+		return p.Implements(marshalerType) || p.Implements(textMarshalerType)
+	}
+	return false
+}
+
+// value writes any reflected value at the given depth.
+func (e *encoder) value(v reflect.Value, depth int) error {
+
+	if !v.IsValid() {
+		e.buf.WriteString(`null`)
+		return nil
+	}
+
+	if (e.indent != "" || e.prefix != "") && isCompact(v.Type()) {
+		return e.compact(v)
+	}
+
+	if marshals(v) && !e.self {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			e.buf.WriteString(`null`)
+			return nil
+		}
+		return e.marshaler(v, depth)
+	}
+
+	switch v.Kind() {
+
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			e.buf.WriteString(`null`)
+			return nil
+		}
+		done, err := e.visit(v)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return e.value(v.Elem(), depth)
+
+	case reflect.Struct:
+		return e.object(v, depth)
+
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteString(`null`)
+			return nil
+		}
+		done, err := e.visit(v)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return e.mapping(v, depth)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString(`null`)
+			return nil
+		}
+		if et := v.Type().Elem(); et.Kind() == reflect.Uint8 &&
+			!reflect.PointerTo(et).Implements(marshalerType) &&
+			!reflect.PointerTo(et).Implements(textMarshalerType) {
+			return e.scalar(v)
+		}
+		done, err := e.visit(v)
+		if err != nil {
+			return err
+		}
+		defer done()
+		return e.array(v, depth)
+
+	case reflect.Array:
+		return e.array(v, depth)
+
+	case reflect.String:
+		if v.Type() == numberType {
+			break
+		}
+		str := v.String()
+		if e.maxStr > 0 && len(str) > e.maxStr {
+			str = truncate(str, e.maxStr)
+		}
+		return e.str(str)
+
+	}
+
+	return e.scalar(v)
+}
+
+// visit counts the nesting of the pointer, interface, map, or slice
+// and (as encoding/json does) only once deeper than maxDepth checks
+// that it is not already being encoded (a cycle) returning the
+// function to call when done with it.
+func (e *encoder) visit(v reflect.Value) (func(), error) {
+	e.level++
+	if e.level <= maxDepth || v.Kind() == reflect.Interface {
+		return func() { e.level-- }, nil
+	}
+	var key any = v.Pointer()
+	if v.Kind() == reflect.Slice {
+		key = [2]uintptr{v.Pointer(), uintptr(v.Len())}
+	}
+	if e.seen[key] {
+		e.level--
+		return nil, &json.UnsupportedValueError{
+			Value: v,
+			Str:   `encountered a cycle via ` + v.Type().String(),
+		}
+	}
+	if e.seen == nil {
+		e.seen = map[any]bool{}
+	}
+	e.seen[key] = true
+	return func() {
+		delete(e.seen, key)
+		e.level--
+	}, nil
+}
+
+// array writes slices and arrays.
+func (e *encoder) array(v reflect.Value, depth int) error {
+	n := v.Len()
+	if n == 0 {
+		e.buf.WriteString(`[]`)
+		return nil
+	}
+	e.self = false
+	show := n
+	if e.maxItems > 0 && n > e.maxItems {
+		show = e.maxItems
+	}
+	e.buf.WriteByte('[')
+	for i := 0; i < show; i++ {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := e.value(v.Index(i), depth+1); err != nil {
+			return err
+		}
+		if err := e.flush(); err != nil {
+			return err
+		}
+	}
+	if show < n {
+		e.buf.WriteByte(',')
+		e.newline(depth + 1)
+		e.buf.WriteString(quote(`…(+` + strconv.Itoa(n-show) + ` items)`))
+	}
+	e.newline(depth)
+	e.buf.WriteByte(']')
+	return nil
+}
+
+// mapping writes maps with their keys sorted the same as encoding/json
+// (unless ordered otherwise, see MarshalSorted).
+func (e *encoder) mapping(v reflect.Value, depth int) error {
+	if v.Len() == 0 {
+		e.buf.WriteString(`{}`)
+		return nil
+	}
+
+	e.self = false
+	type kv struct {
+		key string
+		val reflect.Value
+	}
+	pairs := make([]kv, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, kv{key, iter.Value()})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+	if e.less != nil {
+		sort.SliceStable(pairs, func(i, j int) bool { return e.less(pairs[i].key, pairs[j].key) })
+	}
+
+	e.buf.WriteByte('{')
+	for i, p := range pairs {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := e.str(p.key); err != nil {
+			return err
+		}
+		e.colon()
+		if err := e.value(p.val, depth+1); err != nil {
+			return err
+		}
+		if err := e.flush(); err != nil {
+			return err
+		}
+	}
+	e.newline(depth)
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// mapKey resolves the string form of a map key the same way as
+// encoding/json.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		buf, err := tm.MarshalText()
+		return string(buf), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: k.Type()}
+}
+
+// truncate cuts the string at the last rune boundary before max bytes
+// and appends a note with the number of bytes dropped.
+func truncate(s string, max int) string {
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + `…(+` + strconv.Itoa(len(s)-n) + ` bytes)`
+}
+
+// quote returns the string as a JSON string with the package escaping
+// defaults (see EscapeStrict).
+func quote(s string) string { return `"` + EscapeStrict(s) + `"` }
+
+// str writes the string as a JSON string observing the UTF-8 policy of
+// the encoder.
+func (e *encoder) str(s string) error {
+	switch e.utf8 {
+	case UTF8Raw:
+		e.buf.WriteString(quoteRaw(s))
+		return nil
+	case UTF8Error:
+		if i := invalidAt(s); i >= 0 {
+			return InvalidUTF8Error{e.flushed + e.buf.Len() + len(quote(s[:i])) - 1}
+		}
+	}
+	e.buf.WriteString(quote(s))
+	return nil
+}
+
+// object writes a struct observing the same field rules as
+// encoding/json.
+func (e *encoder) object(v reflect.Value, depth int) error {
+	e.self = false
+	e.buf.WriteByte('{')
+	n := 0
+	for _, f := range typeFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if n > 0 {
+			e.buf.WriteByte(',')
+		}
+		n++
+		e.newline(depth + 1)
+		e.buf.WriteString(quote(f.name))
+		e.colon()
+		if n, ok := epochValue(fv, f.epoch); ok && f.epoch != 0 {
+			e.buf.WriteString(strconv.FormatInt(n, 10))
+			continue
+		}
+		if f.decimal.applies(fv.Type()) {
+			s, ok, err := f.decimal.encode(f.name, fv)
+			if err != nil {
+				return err
+			}
+			if ok {
+				e.buf.WriteString(s)
+				continue
+			}
+		}
+		qv := fv // pointers to scalars are quoted as well (unless nil)
+		if qv.Kind() == reflect.Pointer && qv.Type().Name() == "" && !qv.IsNil() {
+			qv = qv.Elem()
+		}
+		if f.quoted && isScalar(qv) {
+			sub := &encoder{buf: new(bytes.Buffer), utf8: e.utf8}
+			if err := sub.value(qv, 0); err != nil {
+				return err
+			}
+			e.buf.WriteString(quote(sub.buf.String()))
+			continue
+		}
+		if err := e.value(fv, depth+1); err != nil {
+			return err
+		}
+		if err := e.flush(); err != nil {
+			return err
+		}
+	}
+	if n > 0 {
+		e.newline(depth)
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex but returns false instead
+// of panicking when a nil embedded pointer is encountered.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isScalar returns true for the kinds that observe the ",string" tag
+// option.
+func isScalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// isEmptyValue is the same as in encoding/json for omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// field is a single struct field to be encoded.
+type field struct {
+	name      string
+	tagged    bool
+	index     []int
+	omitEmpty bool
+	quoted    bool
+	epoch     time.Duration // unit of unix tag options (see epochUnit)
+	decimal   *decimalTag   // scale and round tag options (see Decimal)
+}
+
+var fieldCache sync.Map
+
+// typeFields returns the fields that encoding/json would encode for the
+// given struct type (including those promoted from embedded structs)
+// in the same order and with the same precedence rules.
+func typeFields(t reflect.Type) []field {
+	if f, has := fieldCache.Load(t); has {
+		return f.([]field)
+	}
+
+	type entry struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []field
+	current := []entry{}
+	next := []entry{{typ: t}}
+	count := map[reflect.Type]int{}
+	nextCount := map[reflect.Type]int{}
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, ent := range current {
+			if visited[ent.typ] {
+				continue
+			}
+			visited[ent.typ] = true
+
+			for i := 0; i < ent.typ.NumField(); i++ {
+				sf := ent.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+
+				index := make([]int, len(ent.index)+1)
+				copy(index, ent.index)
+				index[len(ent.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					f := field{
+						name:      name,
+						tagged:    name != "",
+						index:     index,
+						omitEmpty: hasOpt(opts, "omitempty"),
+						quoted:    hasOpt(opts, "string"),
+						epoch:     epochUnit(opts),
+						decimal:   decimalOpts(sf.Name, opts),
+					}
+					if f.name == "" {
+						f.name = sf.Name
+					}
+					fields = append(fields, f)
+					if count[ent.typ] > 1 {
+						// annihilated later by dominance
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, entry{ft, index})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tagged != x[j].tagged {
+			return x[i].tagged
+		}
+		return indexLess(x[i].index, x[j].index)
+	})
+
+	out := fields[:0]
+	for adv, i := 0, 0; i < len(fields); i += adv {
+		fi := fields[i]
+		for adv = 1; i+adv < len(fields); adv++ {
+			if fields[i+adv].name != fi.name {
+				break
+			}
+		}
+		if adv == 1 {
+			out = append(out, fi)
+			continue
+		}
+		if f, ok := dominantField(fields[i : i+adv]); ok {
+			out = append(out, f)
+		}
+	}
+	fields = out
+
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].index, fields[j].index)
+	})
+
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// dominantField returns the field that wins among those with the same
+// name (shallowest, then tagged) or false if there is no clear winner.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 &&
+		len(fields[0].index) == len(fields[1].index) &&
+		fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+func indexLess(a, b []int) bool {
+	for k, x := range a {
+		if k >= len(b) {
+			return false
+		}
+		if x != b[k] {
+			return x < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func hasOpt(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// optValue returns the value of the name=value tag option (if any).
+func optValue(opts, name string) (string, bool) {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if k, v, ok := strings.Cut(opt, "="); ok && k == name {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,236 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleRegisterCompact() {
+
+	type Point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+
+	json.RegisterCompact(Point{}, [2]float64{})
+
+	data := struct {
+		Name   string       `json:"name"`
+		Points []Point      `json:"points"`
+		Coords [][2]float64 `json:"coords"`
+		Empty  []Point      `json:"empty,omitempty"`
+	}{
+		Name:   "path",
+		Points: []Point{{1, 2}, {3, 4}},
+		Coords: [][2]float64{{1.5, -2}, {3, 4.25}},
+	}
+
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// {
+	//   "name": "path",
+	//   "points": [
+	//     {"x":1,"y":2},
+	//     {"x":3,"y":4}
+	//   ],
+	//   "coords": [
+	//     [1.5,-2],
+	//     [3,4.25]
+	//   ]
+	// }
+}
+
+type countWriter struct{ writes, bytes int }
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.bytes += len(p)
+	return len(p), nil
+}
+
+func ExampleEncodeTo() {
+	big := make(map[string][]int, 1000)
+	for i := 0; i < 1000; i++ {
+		big[fmt.Sprint(i)] = make([]int, 100)
+	}
+	w := new(countWriter)
+	if err := json.EncodeTo(w, big); err != nil {
+		fmt.Println(err)
+	}
+	buf, _ := json.Marshal(big)
+	fmt.Println(w.bytes == len(buf), w.writes > len(buf)/json.EncodeChunk-1)
+
+	json.EncodeTo(os.Stdout, map[string]any{"small": []int{1, 2}})
+
+	// Output:
+	// true true
+	// {"small":[1,2]}
+}
+
+type user struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+}
+
+func (u user) MarshalJSON() ([]byte, error) {
+	u.Password = ""
+	return json.MarshalSelf(u)
+}
+
+type team struct {
+	Lead    *user  `json:"lead"`
+	Members []user `json:"members"`
+}
+
+func ExampleMarshalSelf() {
+	t := team{
+		Lead:    &user{"ann", "secret"},
+		Members: []user{{"bob", "hunter2"}},
+	}
+	buf, err := json.Marshal(t)
+	fmt.Println(string(buf), err)
+
+	// Output:
+	// {"lead":{"name":"ann"},"members":[{"name":"bob"}]} <nil>
+}
+
+type node struct {
+	Name string `json:"name"`
+	Kids []node `json:"kids,omitempty"`
+}
+
+// MarshalJSON renders the node with its name upper-cased (and so, since
+// only the node itself skips the method, those of its kids as well).
+func (n node) MarshalJSON() ([]byte, error) {
+	n.Name = strings.ToUpper(n.Name)
+	return json.MarshalSelf(n)
+}
+
+func ExampleMarshalSelf_child() {
+	n := node{`root`, []node{{`a`, []node{{`b`, nil}}}, {`c`, nil}}}
+	buf, err := json.Marshal(n)
+	fmt.Println(string(buf), err)
+
+	// Output:
+	// {"name":"ROOT","kids":[{"name":"A","kids":[{"name":"B"}]},{"name":"C"}]} <nil>
+}
+
+type stdText int
+
+func (t stdText) MarshalText() ([]byte, error) { return []byte(fmt.Sprint(`t`, int(t))), nil }
+
+func TestMarshal_matchesStd(t *testing.T) {
+	type inner struct {
+		A int     `json:"a,string"`
+		B float64 `json:"b,omitempty"`
+	}
+	type outer struct {
+		inner
+		S  string             `json:"s"`
+		N  stdjson.Number     `json:"n"`
+		M  map[stdText]bool   `json:"m"`
+		P  *inner             `json:"p"`
+		I  any                `json:"i"`
+		Bs []byte             `json:"bs"`
+		Ar [2]uint8           `json:"ar"`
+		T  stdText            `json:"t"`
+		R  stdjson.RawMessage `json:"r"`
+		x  int
+	}
+	type quoted struct {
+		B *int    `json:"b,string"`
+		N *string `json:"n,string"`
+	}
+	five, s := 5, "s"
+	for _, v := range []any{
+		nil, true, 0, -12, uint64(1 << 63), 1.5, 1e21, 1e-7, 123456789.0,
+		float32(3.14), float32(1e-7), "", "<a & b>\n\t \x01", "💢",
+		[]byte("hi"), []int(nil), []string{}, map[string]int{"b": 1, "a": 2},
+		map[int]string{10: "x", 2: "y"}, stdjson.Number("1e3"),
+		outer{
+			inner: inner{A: 1, B: 2.5},
+			S:     "s",
+			N:     "-0.5",
+			M:     map[stdText]bool{1: true, 2: false},
+			I:     []any{1, "x", nil},
+			Bs:    []byte{0, 255},
+			T:     7,
+			R:     stdjson.RawMessage(`{ "a" : [1, 2] }`),
+		},
+		quoted{B: &five, N: &s}, quoted{},
+	} {
+		b := new(strings.Builder)
+		enc := stdjson.NewEncoder(b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.TrimSpace(b.String())
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%#v\n got: %s\nwant: %s", v, got, want)
+		}
+	}
+
+	var q quoted
+	buf, _ := json.Marshal(quoted{B: &five})
+	if err := json.Unmarshal(buf, &q); err != nil || q.B == nil || *q.B != 5 {
+		t.Errorf("%s does not round trip: %v", buf, err)
+	}
+}
+
+func TestMarshal_deep(t *testing.T) {
+	type link struct {
+		N    int   `json:"n"`
+		Next *link `json:"next,omitempty"`
+	}
+	var head *link
+	for i := 0; i < 1500; i++ {
+		head = &link{i, head}
+	}
+	want, err := stdjson.Marshal(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("deep list differs from encoding/json")
+	}
+
+	head.Next.Next = head
+	if _, err := json.Marshal(head); err == nil ||
+		!strings.Contains(err.Error(), `encountered a cycle`) {
+		t.Errorf("pointer cycle: %v", err)
+	}
+
+	m := map[string]any{}
+	m[`m`] = m
+	if _, err := json.Marshal(m); err == nil ||
+		!strings.Contains(err.Error(), `encountered a cycle`) {
+		t.Errorf("map cycle: %v", err)
+	}
+
+	type list []list
+	l := list{nil}
+	l[0] = l
+	if _, err := json.Marshal(l); err == nil ||
+		!strings.Contains(err.Error(), `encountered a cycle`) {
+		t.Errorf("slice cycle: %v", err)
+	}
+}
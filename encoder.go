@@ -0,0 +1,66 @@
+package json
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// Encoder writes many JSON values to a single stream (JSON Lines logs,
+// pipes to other tools, etc.) with the package defaults (see Marshal):
+// no HTML escaping and no trailing newline. Instead, every value after
+// the first is preceded by the separator (a newline by default, see
+// SetSeparator) so that the stream never ends with one. Big values are
+// written in chunks as they are encoded (see EncodeTo).
+//
+//     enc := json.NewEncoder(os.Stdout)
+//     for _, e := range events {
+//       if err := enc.Encode(e); err != nil {
+//         return err
+//       }
+//     }
+//
+type Encoder struct {
+	w      io.Writer
+	prefix string
+	indent string
+	sep    string
+	n      int
+}
+
+// NewEncoder returns a new Encoder writing to the writer.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, sep: "\n"}
+}
+
+// SetIndent indents every value written after it is called (see
+// MarshalIndent). Empty strings (the default) write compact values.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix, e.indent = prefix, indent
+}
+
+// SetSeparator sets what is written between values (a newline by
+// default).
+func (e *Encoder) SetSeparator(sep string) { e.sep = sep }
+
+// Encode writes the value as JSON preceded by the separator (unless it
+// is the first). Nothing can be assumed about what was written when
+// there is an error.
+func (e *Encoder) Encode(v any) error {
+	enc := &encoder{
+		buf:    new(bytes.Buffer),
+		prefix: e.prefix,
+		indent: e.indent,
+		utf8:   InvalidUTF8,
+		w:      e.w,
+	}
+	if e.n > 0 {
+		enc.buf.WriteString(e.sep)
+	}
+	if err := enc.value(reflect.ValueOf(v), 0); err != nil {
+		return err
+	}
+	e.n++
+	_, err := e.w.Write(enc.buf.Bytes())
+	return err
+}
@@ -0,0 +1,24 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleEncoder() {
+	enc := json.NewEncoder(os.Stdout)
+	enc.Encode(map[string]string{"msg": "<hi> & bye"})
+	enc.Encode([]int{1, 2})
+	enc.SetIndent("", "  ")
+	enc.Encode(map[string]int{"n": 3})
+	fmt.Println("|")
+
+	// Output:
+	// {"msg":"<hi> & bye"}
+	// [1,2]
+	// {
+	//   "n": 3
+	// }|
+}
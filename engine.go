@@ -0,0 +1,72 @@
+package json
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Engine specifies the underlying JSON implementation that does the
+// actual encoding and decoding for this package. The encoding/json
+// standard library (StdEngine, see there for the native encoder used
+// instead to marshal) is used by default but faster
+// third-party engines (jsoniter, go-json, etc.) can be plugged in
+// package-wide (by setting DefaultEngine) or for a single call
+// (MarshalWith, UnmarshalWith) without giving up any of the API or
+// defaults (no HTML escaping, no trailing newline) from this package.
+// Most third-party engines require only a small adapter to return the
+// EngineEncoder and EngineDecoder interfaces.
+type Engine interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(buf []byte, v any) error
+	NewEncoder(w io.Writer) EngineEncoder
+	NewDecoder(r io.Reader) EngineDecoder
+}
+
+// EngineEncoder is the subset of the encoding/json.Encoder methods
+// required from any Engine.
+type EngineEncoder interface {
+	Encode(v any) error
+	SetEscapeHTML(on bool)
+	SetIndent(prefix, indent string)
+}
+
+// EngineDecoder is the subset of the encoding/json.Decoder methods
+// required from any Engine.
+type EngineDecoder interface {
+	Decode(v any) error
+	More() bool
+	Buffered() io.Reader
+	UseNumber()
+	DisallowUnknownFields()
+}
+
+// DefaultEngine is the Engine used by all package functions that do
+// not explicitly take one. Changing it is not safe for concurrent use
+// and should be done once during initialization.
+var DefaultEngine Engine = StdEngine{}
+
+// StdEngine is the Engine using the encoding/json standard library for
+// decoding. For encoding, Marshal and MarshalIndent (and their With
+// variants) recognize it and use the native encoder of this package
+// instead (the same output as encoding/json but with the package
+// policies and without HTML escaping), so its own Marshal and
+// NewEncoder methods are only used when called directly.
+type StdEngine struct{}
+
+// Marshal implements Engine.
+func (StdEngine) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements Engine.
+func (StdEngine) Unmarshal(buf []byte, v any) error {
+	return json.Unmarshal(buf, v)
+}
+
+// NewEncoder implements Engine.
+func (StdEngine) NewEncoder(w io.Writer) EngineEncoder {
+	return json.NewEncoder(w)
+}
+
+// NewDecoder implements Engine.
+func (StdEngine) NewDecoder(r io.Reader) EngineDecoder {
+	return json.NewDecoder(r)
+}
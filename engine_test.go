@@ -0,0 +1,61 @@
+package json_test
+
+import (
+	"fmt"
+	"io"
+
+	json "github.com/rwxrob/json"
+)
+
+// Loud is an Engine that announces every encoder it creates but
+// otherwise uses the standard library.
+type Loud struct{ json.StdEngine }
+
+func (e Loud) NewEncoder(w io.Writer) json.EngineEncoder {
+	fmt.Println("encoding with loud engine")
+	return e.StdEngine.NewEncoder(w)
+}
+
+func ExampleMarshalWith() {
+	m := map[string]string{"<foo>": "&bar"}
+
+	buf, err := json.MarshalWith(Loud{}, m)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// encoding with loud engine
+	// {"<foo>":"&bar"}
+}
+
+func ExampleDefaultEngine() {
+	defer func(e json.Engine) { json.DefaultEngine = e }(json.DefaultEngine)
+	json.DefaultEngine = Loud{}
+
+	buf, err := json.Marshal([]int{1, 2})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// encoding with loud engine
+	// [1,2]
+}
+
+func ExampleMarshalIndentWith() {
+	buf, err := json.MarshalIndentWith(Loud{}, []int{1, 2}, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// encoding with loud engine
+	// [
+	//   1,
+	//   2
+	// ]
+}
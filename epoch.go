@@ -0,0 +1,67 @@
+package json
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"time"
+)
+
+// timeType is that of epoch fields: time.Time (or *time.Time) fields
+// with the unix, unixmilli, or unixmicro tag option (see Marshal).
+var timeType = reflect.TypeOf(time.Time{})
+
+// epochUnit returns the unit of the epoch tag option (if any).
+func epochUnit(opts string) time.Duration {
+	switch {
+	case hasOpt(opts, `unix`):
+		return time.Second
+	case hasOpt(opts, `unixmilli`):
+		return time.Millisecond
+	case hasOpt(opts, `unixmicro`):
+		return time.Microsecond
+	}
+	return 0
+}
+
+// epochValue returns the time (time.Time or non-nil *time.Time) as
+// a number of the unit since the epoch (or false if not a time).
+func epochValue(v reflect.Value, unit time.Duration) (int64, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	if v.Type() != timeType {
+		return 0, false
+	}
+	t := v.Interface().(time.Time)
+	switch unit {
+	case time.Millisecond:
+		return t.UnixMilli(), true
+	case time.Microsecond:
+		return t.UnixMicro(), true
+	}
+	return t.Unix(), true
+}
+
+// epochTime returns the time for the number of the unit since the
+// epoch.
+func epochTime(n json.Number, unit time.Duration) (time.Time, bool) {
+	if i, err := n.Int64(); err == nil {
+		switch unit {
+		case time.Millisecond:
+			return time.UnixMilli(i).UTC(), true
+		case time.Microsecond:
+			return time.UnixMicro(i).UTC(), true
+		}
+		return time.Unix(i, 0).UTC(), true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f * float64(unit) / float64(time.Second))
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+}
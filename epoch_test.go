@@ -0,0 +1,42 @@
+package json_test
+
+import (
+	"fmt"
+	"time"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMarshal_epoch() {
+	type Event struct {
+		Name    string     `json:"name"`
+		Created time.Time  `json:"created,unix"`
+		Updated *time.Time `json:"updated,unixmilli,omitempty"`
+		Seen    time.Time  `json:"seen,unixmicro"`
+		At      time.Time  `json:"at"`
+	}
+
+	var events []Event
+	err := json.Unmarshal([]byte(`[
+		{"name":"a","created":1700000000,"updated":1700000000123,"seen":1700000000123456,"at":"2023-11-14T22:13:20Z"},
+		{"name":"b","created":1700000000.5,"seen":"2023-11-14T22:13:20Z"}
+	]`), &events)
+	fmt.Println(err)
+	for _, e := range events {
+		fmt.Println(e.Name, e.Created.Format(time.RFC3339Nano), e.Updated != nil, e.Seen.Format(time.RFC3339Nano))
+	}
+	fmt.Println(events[0].Updated.Format(time.RFC3339Nano))
+
+	buf, _ := json.Marshal(events[0])
+	fmt.Println(string(buf))
+	buf, _ = json.Marshal(events[1])
+	fmt.Println(string(buf))
+
+	// Output:
+	// <nil>
+	// a 2023-11-14T22:13:20Z true 2023-11-14T22:13:20.123456Z
+	// b 2023-11-14T22:13:20.5Z false 2023-11-14T22:13:20Z
+	// 2023-11-14T22:13:20.123Z
+	// {"name":"a","created":1700000000,"updated":1700000000123,"seen":1700000000123456,"at":"2023-11-14T22:13:20Z"}
+	// {"name":"b","created":1700000000,"seen":1700000000000000,"at":"0001-01-01T00:00:00Z"}
+}
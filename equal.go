@@ -0,0 +1,99 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// EqualOptions are the options for EqualWith. The zero value is the
+// same as Equal.
+type EqualOptions struct {
+	Tolerance float64 // numbers are equal if no further apart than this
+}
+
+// Equal returns true if the two JSON documents are the same structurally
+// ignoring key order and insignificant whitespace, which is what table
+// tests of API responses want. Numbers are equal if they have the same
+// value however written (1, 1.0, and 1e0 are all the same) without any
+// loss of precision (even beyond that of a float64). Invalid JSON is
+// never equal to anything.
+func Equal(a, b []byte) bool { return EqualWith(a, b, EqualOptions{}) }
+
+// EqualWith is the same as Equal with options (see EqualOptions).
+func EqualWith(a, b []byte, opt EqualOptions) bool {
+	av, err := equalDecode(a)
+	if err != nil {
+		return false
+	}
+	bv, err := equalDecode(b)
+	if err != nil {
+		return false
+	}
+	return equalValues(av, bv, opt)
+}
+
+// equalDecode decodes the JSON into generic form with json.Number for
+// every number.
+func equalDecode(buf []byte) (any, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf(`invalid data after top-level value`)
+	}
+	return v, nil
+}
+
+// equalValues compares the values (in generic form with json.Number).
+func equalValues(a, b any, opt EqualOptions) bool {
+	switch at := a.(type) {
+	case map[string]any:
+		bt, is := b.(map[string]any)
+		if !is || len(at) != len(bt) {
+			return false
+		}
+		for k, av := range at {
+			bv, has := bt[k]
+			if !has || !equalValues(av, bv, opt) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bt, is := b.([]any)
+		if !is || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !equalValues(at[i], bt[i], opt) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bt, is := b.(json.Number)
+		if !is {
+			return false
+		}
+		if opt.Tolerance > 0 {
+			af, aerr := at.Float64()
+			bf, berr := bt.Float64()
+			return aerr == nil && berr == nil && math.Abs(af-bf) <= opt.Tolerance
+		}
+		ar, aok := new(big.Rat).SetString(string(at))
+		br, bok := new(big.Rat).SetString(string(bt))
+		return aok && bok && ar.Cmp(br) == 0
+	}
+	return a == b
+}
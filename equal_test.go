@@ -0,0 +1,37 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleEqual() {
+	want := []byte(`{"id": 1, "tags": ["a", "b"], "price": 9.99}`)
+	for _, got := range []string{
+		`{"price":9.99,"tags":["a","b"],"id":1.0}`,
+		`{"id":1e0,"tags":["a","b"],"price":9.990}`,
+		`{"id":1,"tags":["b","a"],"price":9.99}`,
+		`{"id":1,"tags":["a","b"],"price":9.99,"extra":null}`,
+		`{"id":"1","tags":["a","b"],"price":9.99}`,
+		`{"id":1,"tags":["a","b"],"price":9.99} {}`,
+	} {
+		fmt.Println(json.Equal(want, []byte(got)))
+	}
+
+	big := []byte(`9007199254740993`)
+	fmt.Println(json.Equal(big, []byte(`9007199254740992`)))
+
+	fmt.Println(json.EqualWith(want, []byte(`{"id":1,"tags":["a","b"],"price":9.990000001}`),
+		json.EqualOptions{Tolerance: 1e-6}))
+
+	// Output:
+	// true
+	// true
+	// false
+	// false
+	// false
+	// false
+	// false
+	// true
+}
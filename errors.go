@@ -0,0 +1,90 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// errorValue is the consistent JSON structure of an error (see
+// ErrorValue).
+type errorValue struct {
+	Message string         `json:"message"`
+	Type    string         `json:"type"`
+	Details map[string]any `json:"details,omitempty"`
+	Cause   *errorValue    `json:"cause,omitempty"`
+	Causes  []*errorValue  `json:"causes,omitempty"`
+}
+
+// ErrorValue returns a value that marshals any error into a consistent
+// JSON structure so that CLI tools can offer a machine readable error
+// mode. Every error has a message and its Go type. The typed errors of
+// this package and encoding/json (TimeOutError, InvalidUTF8Error,
+// SyntaxError, UnmarshalTypeError, etc.) also include their specific
+// details (phase, offset, field, etc.). Wrapped errors are included as
+// the cause (or causes when joined), recursively:
+//
+//     {
+//       "message": "fetching: header timeout: ...",
+//       "type": "*fmt.wrapError",
+//       "cause": {
+//         "message": "header timeout: ...",
+//         "type": "*json.TimeOutError",
+//         "details": {"phase": "header"},
+//         "cause": {...}
+//       }
+//     }
+//
+// A nil error returns nil.
+func ErrorValue(err error) any {
+	if err == nil {
+		return nil
+	}
+	return errorValueOf(err)
+}
+
+func errorValueOf(err error) *errorValue {
+	ev := &errorValue{Message: err.Error(), Type: fmt.Sprintf(`%T`, err)}
+	switch e := err.(type) {
+	case *TimeOutError:
+		ev.Details = map[string]any{`phase`: e.Phase}
+	case InvalidUTF8Error:
+		ev.Details = map[string]any{`offset`: e.Offset}
+	case *json.SyntaxError:
+		ev.Details = map[string]any{`offset`: e.Offset}
+	case *json.UnmarshalTypeError:
+		ev.Details = map[string]any{
+			`value`:  e.Value,
+			`type`:   e.Type.String(),
+			`offset`: e.Offset,
+			`field`:  e.Field,
+		}
+	case *json.UnsupportedTypeError:
+		ev.Details = map[string]any{`type`: e.Type.String()}
+	case *json.UnsupportedValueError:
+		ev.Details = map[string]any{`value`: e.Str}
+	case *HTTPError:
+		ev.Details = map[string]any{`status`: e.StatusCode}
+	case *url.Error:
+		ev.Details = map[string]any{`op`: e.Op, `url`: e.URL}
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if c := u.Unwrap(); c != nil {
+			ev.Cause = errorValueOf(c)
+		}
+	case interface{ Unwrap() []error }:
+		for _, c := range u.Unwrap() {
+			if c != nil {
+				ev.Causes = append(ev.Causes, errorValueOf(c))
+			}
+		}
+	}
+	return ev
+}
+
+// PrintError prints the error as JSON (see ErrorValue) on a single
+// line (or null if nil).
+func PrintError(err error) {
+	fmt.Println(This{ErrorValue(err)})
+}
@@ -0,0 +1,22 @@
+package json_test
+
+import (
+	"errors"
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExamplePrintError() {
+	var n int
+	err := json.Unmarshal([]byte(`"nope"`), &n)
+	json.PrintError(fmt.Errorf("loading config: %w", err))
+
+	json.PrintError(errors.New("plain"))
+	json.PrintError(nil)
+
+	// Output:
+	// {"message":"loading config: json: cannot unmarshal string into Go value of type int","type":"*fmt.wrapError","cause":{"message":"json: cannot unmarshal string into Go value of type int","type":"*json.UnmarshalTypeError","details":{"field":"","offset":6,"type":"int","value":"string"}}}
+	// {"message":"plain","type":"*errors.errorString"}
+	// null
+}
@@ -0,0 +1,89 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	json "github.com/rwxrob/json"
+)
+
+func FuzzEscapeStrict(f *testing.F) {
+	for _, s := range []string{
+		``, `plain`, `<>&"'\`, "\t\b\f\n\r", "\x00\x1f\x7f",
+		"\u2028\u2029", "💢д", "\xff\xfe", "a\xc3", "\ufffd",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		out := json.EscapeStrict(in)
+		var got string
+		if err := stdjson.Unmarshal([]byte(`"`+out+`"`), &got); err != nil {
+			t.Fatalf("EscapeStrict(%q) = %q rejected: %v", in, out, err)
+		}
+		want := in
+		if !utf8.ValidString(in) {
+			want = replaceEach(in)
+		}
+		if got != want {
+			t.Fatalf("EscapeStrict(%q) round trip = %q, want %q", in, got, want)
+		}
+		back, err := json.Unescape(out)
+		if err != nil {
+			t.Fatalf("Unescape(EscapeStrict(%q)) failed: %v", in, err)
+		}
+		if back != want {
+			t.Fatalf("Unescape(EscapeStrict(%q)) = %q, want %q", in, back, want)
+		}
+	})
+}
+
+func FuzzUnescape(f *testing.F) {
+	for _, s := range []string{
+		``, `plain`, `\t\b\f\n\r\\\"\/`, `\u00e9`, `\ud83d\udca2`,
+		`\ud800`, `\udc00\ud800x`, `\u12`, `\x`, `\`,
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		got, err := json.Unescape(in)
+		var want string
+		stderr := stdjson.Unmarshal([]byte(`"`+in+`"`), &want)
+		if stderr != nil || !utf8.ValidString(in) {
+			return // not a valid JSON string body (control chars, etc.)
+		}
+		if err != nil {
+			t.Fatalf("Unescape(%q) failed: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("Unescape(%q) = %q, want %q", in, got, want)
+		}
+		if back, _ := json.Unescape(json.EscapeStrict(want)); utf8.ValidString(want) && back != want {
+			t.Fatalf("Unescape(EscapeStrict(%q)) = %q", want, back)
+		}
+	})
+}
+
+// replaceEach replaces every invalid UTF-8 byte with U+FFFD.
+func replaceEach(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var benchEscape = strings.Repeat("some \"quoted\"\ttext with д and 💢\n", 32)
+
+func BenchmarkEscape(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		json.Escape(benchEscape)
+	}
+}
+
+func BenchmarkEscapeStrict(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		json.EscapeStrict(benchEscape)
+	}
+}
@@ -0,0 +1,74 @@
+package json
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// ETagHandler is HTTP middleware giving any JSON endpoint (including
+// Proxy) cheap conditional GET support. Successful (200) JSON responses
+// to GET and HEAD requests are buffered and get the CanonicalETag of
+// their body. When it matches the If-None-Match header of the request
+// the body is dropped and 304 (Not Modified) is returned instead. All
+// other responses pass through untouched (but buffered).
+func ETagHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &etagRecorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		ct := rec.header.Get(`Content-Type`)
+		if rec.status == http.StatusOK && strings.Contains(ct, `json`) {
+			if tag, err := CanonicalETag(rec.body.Bytes()); err == nil {
+				w.Header().Set(`ETag`, tag)
+				if etagMatch(r.Header.Get(`If-None-Match`), tag) {
+					w.Header().Del(`Content-Length`)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+		}
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+// etagMatch returns true if any of the (comma separated) ETags match
+// (weakly as required for If-None-Match) or the header is *.
+func etagMatch(header, tag string) bool {
+	for _, t := range strings.Split(header, `,`) {
+		t = strings.TrimPrefix(strings.TrimSpace(t), `W/`)
+		if t == tag || t == `*` {
+			return true
+		}
+	}
+	return false
+}
+
+// etagRecorder buffers the entire response.
+type etagRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (e *etagRecorder) Header() http.Header { return e.header }
+
+func (e *etagRecorder) WriteHeader(status int) {
+	if !e.wrote {
+		e.status, e.wrote = status, true
+	}
+}
+
+func (e *etagRecorder) Write(buf []byte) (int, error) {
+	e.wrote = true
+	return e.body.Write(buf)
+}
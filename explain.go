@@ -0,0 +1,202 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan describes how a query expression traverses a document (see
+// Explain). When created from a specific document (This.Explain) every
+// step also reports how many values it produced so that it is obvious
+// exactly where a query that returns nothing goes wrong.
+type Plan struct {
+	Expr      string
+	Syntax    string // path or jq
+	Streaming bool   // answerable in a single pass without loading it all
+	Steps     []PlanStep
+}
+
+// PlanStep is a single step of a Plan. Matches and Nulls are -1 unless
+// the Plan was made against a document.
+type PlanStep struct {
+	Op      string // the part of the expression
+	Desc    string // what it does
+	Matches int    // values produced
+	Nulls   int    // of those, how many were null
+}
+
+// Explain returns the Plan for the query expression which is either
+// a simple Path (which can always be answered by streaming) or a jq
+// expression (see JQ), which is divided into the steps of its top-level
+// pipeline.
+func Explain(expr string) (*Plan, error) {
+	plan := &Plan{Expr: strings.TrimSpace(expr)}
+	if path, err := ParsePath(expr); err == nil {
+		plan.Syntax = `path`
+		plan.Streaming = true
+		for _, s := range path {
+			plan.Steps = append(plan.Steps, PlanStep{
+				Op: s.String(), Desc: s.describe(), Matches: -1, Nulls: -1,
+			})
+		}
+		return plan, nil
+	}
+	if _, err := ParseJQ(expr); err != nil {
+		return nil, err
+	}
+	plan.Syntax = `jq`
+	segs, err := jqSegments(expr)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		plan.Steps = append(plan.Steps, PlanStep{
+			Op: seg, Desc: jqDescribe(seg), Matches: -1, Nulls: -1,
+		})
+	}
+	return plan, nil
+}
+
+// Explain is the same as the package Explain function but also runs
+// the query against the document one step at a time recording how many
+// values (and nulls) each step produced.
+func (s This) Explain(expr string) (*Plan, error) {
+	plan, err := Explain(expr)
+	if err != nil {
+		return nil, err
+	}
+	src, err := s.querySource()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := cachedDoc(`jq`, src, parseJQDocs)
+	if err != nil {
+		return nil, err
+	}
+	docs := parsed.([]any)
+	var prefix string
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		var run func(any) ([]any, error)
+		if plan.Syntax == `path` {
+			path := MustParsePath(expr)[:i+1]
+			run = path.Select
+		} else {
+			if i > 0 {
+				prefix += ` | `
+			}
+			prefix += step.Op
+			run = MustParseJQ(prefix).Select
+		}
+		step.Matches, step.Nulls = 0, 0
+		for _, doc := range docs {
+			out, err := run(doc)
+			if err != nil {
+				return plan, fmt.Errorf(`step %d (%s): %w`, i+1, step.Op, err)
+			}
+			step.Matches += len(out)
+			for _, o := range out {
+				if o == nil {
+					step.Nulls++
+				}
+			}
+		}
+	}
+	return plan, nil
+}
+
+// String fulfills the fmt.Stringer interface with a human-readable
+// rendering of the plan.
+func (p *Plan) String() string {
+	var b strings.Builder
+	b.WriteString(p.Syntax + ` ` + p.Expr)
+	if p.Streaming {
+		b.WriteString(` (streaming)`)
+	}
+	b.WriteByte('\n')
+	width := 0
+	for _, s := range p.Steps {
+		if len(s.Op) > width {
+			width = len(s.Op)
+		}
+	}
+	for i, s := range p.Steps {
+		fmt.Fprintf(&b, "  %d %-*s  %s", i+1, width, s.Op, s.Desc)
+		if s.Matches >= 0 {
+			fmt.Fprintf(&b, ` -> %d`, s.Matches)
+			if s.Nulls > 0 {
+				fmt.Fprintf(&b, ` (%d null)`, s.Nulls)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// describe returns a short description of the step.
+func (s PathStep) describe() string {
+	switch {
+	case s.Each:
+		return `every item`
+	case s.Index != nil && *s.Index < 0:
+		return fmt.Sprintf(`item %d from the end`, -*s.Index)
+	case s.Index != nil:
+		return fmt.Sprintf(`item %d`, *s.Index)
+	}
+	return `key ` + quote(s.Key)
+}
+
+// jqSegments splits the jq expression into its top-level pipeline.
+func jqSegments(expr string) ([]string, error) {
+	toks, err := jqLex(expr)
+	if err != nil {
+		return nil, err
+	}
+	var segs []string
+	depth, start := 0, 0
+	for _, t := range toks {
+		if t.kind != 'p' && t.kind != 'i' {
+			continue
+		}
+		switch t.text {
+		case `(`, `[`, `{`, `if`:
+			depth++
+		case `)`, `]`, `}`, `end`:
+			depth--
+		case `|`:
+			if depth == 0 {
+				segs = append(segs, strings.TrimSpace(expr[start:t.pos]))
+				start = t.pos + 1
+			}
+		}
+	}
+	return append(segs, strings.TrimSpace(expr[start:])), nil
+}
+
+// jqDescribe returns a short description of a jq pipeline segment.
+func jqDescribe(seg string) string {
+	if _, err := ParsePath(seg); err == nil {
+		return `path`
+	}
+	name, _, _ := strings.Cut(seg, `(`)
+	switch strings.TrimSpace(name) {
+	case `select`:
+		return `filter`
+	case `map`, `map_values`, `with_entries`:
+		return `transform every item`
+	case `sort`, `sort_by`, `group_by`, `unique`, `unique_by`, `min`, `max`,
+		`min_by`, `max_by`, `add`, `length`, `keys`, `reverse`:
+		return `whole value (no streaming)`
+	}
+	switch {
+	case strings.HasPrefix(seg, `..`):
+		return `every value recursively (no streaming)`
+	case strings.HasPrefix(seg, `[`):
+		return `collect into array`
+	case strings.HasPrefix(seg, `{`):
+		return `construct object`
+	case strings.HasPrefix(seg, `if`):
+		return `conditional`
+	}
+	return `expression`
+}
@@ -0,0 +1,48 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleExplain() {
+	plan, _ := json.Explain(`.items[].tags[-1]`)
+	fmt.Print(plan)
+
+	plan, _ = json.Explain(`.items | map(.id) | sort`)
+	fmt.Print(plan)
+
+	// Output:
+	// path .items[].tags[-1] (streaming)
+	//   1 .items  key "items"
+	//   2 []      every item
+	//   3 .tags   key "tags"
+	//   4 [-1]    item 1 from the end
+	// jq .items | map(.id) | sort
+	//   1 .items    path
+	//   2 map(.id)  transform every item
+	//   3 sort      whole value (no streaming)
+}
+
+func ExampleThis_Explain() {
+	this := json.This{`{"users":[{"name":"ann"},{"nom":"bob"}]}`}
+
+	plan, err := this.Explain(`.user[].name`)
+	fmt.Print(plan)
+	fmt.Println(err)
+
+	plan, _ = this.Explain(`.users[] | select(.name != null) | .name`)
+	fmt.Print(plan)
+
+	// Output:
+	// path .user[].name (streaming)
+	//   1 .user  key "user" -> 1 (1 null)
+	//   2 []     every item -> 0
+	//   3 .name  key "name"
+	// step 2 ([]): cannot iterate over null
+	// jq .users[] | select(.name != null) | .name
+	//   1 .users[]               path -> 2
+	//   2 select(.name != null)  filter -> 1
+	//   3 .name                  path -> 1
+}
@@ -0,0 +1,339 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SortMemory is the default most bytes of items that SortJSONL holds
+// in memory before spilling them (sorted) to a temporary file.
+var SortMemory int64 = 64 << 20
+
+// SortOptions are the options for SortJSONL.
+type SortOptions struct {
+	Key     string // path (see Path) of the value sorted by (whole item if empty)
+	Reverse bool   // sort in descending order
+	Unique  bool   // keep only the first item with each key value
+	Dir     string // for temporary files (os.TempDir if empty)
+	Memory  int64  // most bytes held before spilling (SortMemory if 0)
+}
+
+// SortJSONL writes every item of a top-level array (or values of JSON
+// Lines) from the reader to the writer as JSON Lines sorted by the
+// value at the Key so that datasets larger than memory can be sorted
+// (and deduplicated) before diffing or joining them. Items are held in
+// memory up to the Memory limit and then sorted and spilled to
+// temporary files that are merged (k-way) at the end and always
+// removed. The sort is stable: items with the same key value remain in
+// the order read (and the first is kept when Unique). Items without
+// the key sort as null. Key values of different types are ordered
+// null, false, true, numbers (by value), strings (by bytes), arrays,
+// and objects (by their Canonical form). Items are written as they
+// were (never unmarshaled) but compacted to a single line each.
+//
+//     err := json.SortJSONL(out, in, json.SortOptions{
+//       Key:    `.user.id`,
+//       Unique: true,
+//     })
+//
+func SortJSONL(w io.Writer, r io.Reader, opt SortOptions) error {
+	var key Path
+	if opt.Key != "" {
+		var err error
+		if key, err = ParsePath(opt.Key); err != nil {
+			return err
+		}
+	}
+	if opt.Memory <= 0 {
+		opt.Memory = SortMemory
+	}
+	s := &sorter{opt: opt, key: key}
+	defer s.remove()
+	if err := s.read(r); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if len(s.spills) == 0 {
+		s.sort()
+		var last *sortItem
+		for _, it := range s.items {
+			if s.opt.Unique && last != nil && compareSortKeys(last.key, it.key) == 0 {
+				continue
+			}
+			bw.Write(it.line)
+			bw.WriteByte('\n')
+			last = it
+		}
+		return bw.Flush()
+	}
+	if len(s.items) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	if err := s.merge(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// sorter is the state of SortJSONL.
+type sorter struct {
+	opt    SortOptions
+	key    Path
+	items  []*sortItem
+	size   int64    // of items held
+	spills []string // temporary files (sorted)
+}
+
+// sortItem is a single item with the key value it is sorted by.
+type sortItem struct {
+	line []byte
+	key  sortKey
+	src  int // spill file (for stable merges)
+}
+
+// sortKey is the comparable form of a key value.
+type sortKey struct {
+	rank int // null, false, true, number, string, array, object
+	num  float64
+	str  string // string or Canonical form of arrays and objects
+}
+
+// read adds every item from the reader spilling them to temporary
+// files whenever the Memory limit is reached.
+func (s *sorter) read(r io.Reader) error {
+	items, err := newItemReader(r)
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		raw, err := items.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		it, err := s.item(raw)
+		if err != nil {
+			return fmt.Errorf(`item %d: %w`, i, err)
+		}
+		s.items = append(s.items, it)
+		s.size += int64(len(it.line)+len(it.key.str)) + 64
+		if s.size >= s.opt.Memory {
+			if err := s.spill(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// item returns the compacted item with its key value.
+func (s *sorter) item(raw []byte) (*sortItem, error) {
+	line := new(bytes.Buffer)
+	if err := json.Compact(line, raw); err != nil {
+		return nil, err
+	}
+	var v any
+	if err := Unmarshal(line.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	if s.key != nil {
+		found, err := s.key.Select(v)
+		if err != nil {
+			return nil, err
+		}
+		v = nil
+		if len(found) > 0 {
+			v = found[0]
+		}
+	}
+	k, err := newSortKey(v)
+	if err != nil {
+		return nil, err
+	}
+	return &sortItem{line: line.Bytes(), key: k}, nil
+}
+
+// newSortKey returns the comparable form of the (generic) value.
+func newSortKey(v any) (sortKey, error) {
+	switch t := v.(type) {
+	case nil:
+		return sortKey{rank: 0}, nil
+	case bool:
+		if t {
+			return sortKey{rank: 2}, nil
+		}
+		return sortKey{rank: 1}, nil
+	case float64:
+		return sortKey{rank: 3, num: t}, nil
+	case string:
+		return sortKey{rank: 4, str: t}, nil
+	}
+	rank := 6
+	if _, is := v.([]any); is {
+		rank = 5
+	}
+	buf, err := Marshal(v)
+	if err != nil {
+		return sortKey{}, err
+	}
+	if buf, err = Canonical(buf); err != nil {
+		return sortKey{}, err
+	}
+	return sortKey{rank: rank, str: string(buf)}, nil
+}
+
+// compareSortKeys returns -1, 0, or 1 if a is less than, the same as,
+// or more than b.
+func compareSortKeys(a, b sortKey) int {
+	switch {
+	case a.rank != b.rank:
+		if a.rank < b.rank {
+			return -1
+		}
+		return 1
+	case a.num < b.num:
+		return -1
+	case a.num > b.num:
+		return 1
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+// less returns true if item a sorts before b (in the direction wanted)
+// falling back to the spill file so that merges are stable.
+func (s *sorter) less(a, b *sortItem) bool {
+	c := compareSortKeys(a.key, b.key)
+	if s.opt.Reverse {
+		c = -c
+	}
+	if c == 0 {
+		return a.src < b.src
+	}
+	return c < 0
+}
+
+// sort orders the items held (stable).
+func (s *sorter) sort() {
+	sort.SliceStable(s.items, func(i, j int) bool {
+		return s.less(s.items[i], s.items[j])
+	})
+}
+
+// spill sorts the items held and writes them to a new temporary file
+// (dropping duplicates when Unique).
+func (s *sorter) spill() error {
+	s.sort()
+	f, err := os.CreateTemp(s.opt.Dir, `jsonsort-*.jsonl`)
+	if err != nil {
+		return err
+	}
+	s.spills = append(s.spills, f.Name())
+	bw := bufio.NewWriter(f)
+	var last *sortItem
+	for _, it := range s.items {
+		if s.opt.Unique && last != nil && compareSortKeys(last.key, it.key) == 0 {
+			continue
+		}
+		bw.Write(it.line)
+		bw.WriteByte('\n')
+		last = it
+	}
+	err = bw.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	s.items, s.size = nil, 0
+	return err
+}
+
+// merge writes the items of every spill file in order to the writer.
+func (s *sorter) merge(w *bufio.Writer) error {
+	h := &sortHeap{s: s}
+	for n, name := range s.spills {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in := &sortInput{r: bufio.NewReader(f), src: n}
+		if err := h.push(in); err != nil {
+			return err
+		}
+	}
+	var last *sortItem
+	for h.Len() > 0 {
+		in := h.ins[0]
+		it := in.item
+		if !s.opt.Unique || last == nil || compareSortKeys(last.key, it.key) != 0 {
+			w.Write(it.line)
+			w.WriteByte('\n')
+			last = it
+		}
+		heap.Pop(h)
+		if err := h.push(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortInput is a single spill file being merged.
+type sortInput struct {
+	r    *bufio.Reader
+	src  int
+	item *sortItem // next
+}
+
+// sortHeap is the heap of the next item of every spill file.
+type sortHeap struct {
+	s   *sorter
+	ins []*sortInput
+}
+
+// push reads the next item of the input and adds it to the heap (unless
+// the input has no more).
+func (h *sortHeap) push(in *sortInput) error {
+	line, err := in.r.ReadBytes('\n')
+	if err == io.EOF && len(line) == 0 {
+		return nil
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+	it, err := h.s.item(bytes.TrimSuffix(line, []byte("\n")))
+	if err != nil {
+		return err
+	}
+	it.src = in.src
+	in.item = it
+	heap.Push(h, in)
+	return nil
+}
+
+func (h *sortHeap) Len() int           { return len(h.ins) }
+func (h *sortHeap) Less(i, j int) bool { return h.s.less(h.ins[i].item, h.ins[j].item) }
+func (h *sortHeap) Swap(i, j int)      { h.ins[i], h.ins[j] = h.ins[j], h.ins[i] }
+func (h *sortHeap) Push(x any)         { h.ins = append(h.ins, x.(*sortInput)) }
+
+func (h *sortHeap) Pop() any {
+	in := h.ins[len(h.ins)-1]
+	h.ins = h.ins[:len(h.ins)-1]
+	return in
+}
+
+// remove removes every temporary file.
+func (s *sorter) remove() {
+	for _, name := range s.spills {
+		os.Remove(name)
+	}
+}
@@ -0,0 +1,82 @@
+package json_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSortJSONL() {
+	in := `{"id": 3, "name": "cy"}
+{"id": 1, "name": "ann"}
+{"name": "nobody"}
+{"id": 2, "name": "bob"}
+{"id": 1, "name": "ann again"}
+{"id": "x"}
+`
+	err := json.SortJSONL(os.Stdout, strings.NewReader(in), json.SortOptions{Key: `.id`})
+	fmt.Println(err)
+
+	err = json.SortJSONL(os.Stdout, strings.NewReader(in), json.SortOptions{
+		Key:     `.id`,
+		Unique:  true,
+		Reverse: true,
+		Memory:  1, // spills every item (for huge datasets)
+	})
+	fmt.Println(err)
+
+	// Output:
+	// {"name":"nobody"}
+	// {"id":1,"name":"ann"}
+	// {"id":1,"name":"ann again"}
+	// {"id":2,"name":"bob"}
+	// {"id":3,"name":"cy"}
+	// {"id":"x"}
+	// <nil>
+	// {"id":"x"}
+	// {"id":3,"name":"cy"}
+	// {"id":2,"name":"bob"}
+	// {"id":1,"name":"ann"}
+	// {"name":"nobody"}
+	// <nil>
+}
+
+func TestSortJSONL_spill(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var in strings.Builder
+	in.WriteString("[")
+	for i := 0; i < 2000; i++ {
+		if i > 0 {
+			in.WriteString(",")
+		}
+		fmt.Fprintf(&in, `{"k": %d, "i": %d}`, r.Intn(300), i)
+	}
+	in.WriteString("]")
+	dir := t.TempDir()
+	for _, unique := range []bool{false, true} {
+		var mem, disk bytes.Buffer
+		opt := json.SortOptions{Key: `.k`, Unique: unique, Dir: dir}
+		if err := json.SortJSONL(&mem, strings.NewReader(in.String()), opt); err != nil {
+			t.Fatal(err)
+		}
+		opt.Memory = 1000
+		if err := json.SortJSONL(&disk, strings.NewReader(in.String()), opt); err != nil {
+			t.Fatal(err)
+		}
+		if mem.String() != disk.String() {
+			t.Errorf("unique=%v: spilled sort differs from in-memory sort", unique)
+		}
+		lines := strings.Count(mem.String(), "\n")
+		if unique && lines > 300 || !unique && lines != 2000 {
+			t.Errorf("unique=%v: %d lines", unique, lines)
+		}
+	}
+	if left, _ := os.ReadDir(dir); len(left) > 0 {
+		t.Errorf("%d temporary files left", len(left))
+	}
+}
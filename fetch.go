@@ -1,27 +1,151 @@
 package json
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // TimeOut is a package global timeout for any of the high-level https
 // query functions in this package. The default value is 60 seconds.
+// This is the total time allowed for the entire request. See
+// DialTimeOut, TLSTimeOut, and HeaderTimeOut for the individual phases.
+// It is only a fallback for FetchCtx when the context passed has no
+// deadline of its own.
 var TimeOut int = 60
 
+// DialTimeOut, TLSTimeOut, and HeaderTimeOut are the package global
+// timeouts for the individual phases of every request: connecting,
+// completing the TLS handshake, and waiting for the response headers
+// once the request is sent (the server being slow). Each is disabled
+// (zero) by default leaving only the overall TimeOut. When any is set,
+// the Transport of the Client (if an *http.Transport or nil) is cloned
+// once with the timeouts applied. Timeouts are always returned as
+// a *TimeOutError naming the phase.
+var (
+	DialTimeOut   time.Duration
+	TLSTimeOut    time.Duration
+	HeaderTimeOut time.Duration
+)
+
+// TimeOutError is returned by Fetch when any of the timeouts (see
+// TimeOut and DialTimeOut) is exceeded.
+type TimeOutError struct {
+	Phase string // dial, tls, header, or total
+	Err   error
+}
+
+// Error fulfills the error interface.
+func (e *TimeOutError) Error() string {
+	return fmt.Sprintf(`%s timeout: %v`, e.Phase, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *TimeOutError) Unwrap() error { return e.Err }
+
+// Timeout always returns true (see net.Error).
+func (e *TimeOutError) Timeout() bool { return true }
+
+// timeOutError returns a *TimeOutError for the error if it is
+// a timeout or the error as is if not.
+func timeOutError(err error) error {
+	var nerr net.Error
+	var oerr *net.OpError
+	msg := err.Error()
+	switch {
+	case errors.As(err, &oerr) && oerr.Op == `dial` && oerr.Timeout():
+		return &TimeOutError{`dial`, err}
+	case strings.Contains(msg, `TLS handshake timeout`):
+		return &TimeOutError{`tls`, err}
+	case strings.Contains(msg, `timeout awaiting response headers`):
+		return &TimeOutError{`header`, err}
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &nerr) && nerr.Timeout():
+		return &TimeOutError{`total`, err}
+	}
+	return err
+}
+
+var phased struct {
+	sync.Mutex
+	key    phasedKey
+	client *http.Client
+}
+
+type phasedKey struct {
+	base              *http.Client
+	dial, tls, header time.Duration
+}
+
+// fetchClient returns the client with any phase timeouts applied.
+func fetchClient(base *http.Client) *http.Client {
+	if DialTimeOut == 0 && TLSTimeOut == 0 && HeaderTimeOut == 0 {
+		return base
+	}
+	key := phasedKey{base, DialTimeOut, TLSTimeOut, HeaderTimeOut}
+	phased.Lock()
+	defer phased.Unlock()
+	if phased.client != nil && phased.key == key {
+		return phased.client
+	}
+	var tr *http.Transport
+	switch t := base.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		return base
+	}
+	if DialTimeOut > 0 {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   DialTimeOut,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if TLSTimeOut > 0 {
+		tr.TLSHandshakeTimeout = TLSTimeOut
+	}
+	if HeaderTimeOut > 0 {
+		tr.ResponseHeaderTimeout = HeaderTimeOut
+	}
+	c := *base
+	c.Transport = tr
+	phased.key, phased.client = key, &c
+	return &c
+}
+
 // Client provides a way to change the default HTTP client for
 // any further package HTTP request function calls. By default, it is
 // set to http.DefaultClient. This is particularly useful when creating
 // mockups and other testing.
 var Client = http.DefaultClient
 
+// OnRequest and OnResponse are package global hooks (interceptors)
+// called in order for every request sent by Fetch (and the rest) before
+// those of the Request itself (see Request.OnRequest) to add logging,
+// tracing, authentication, metrics, and such without replacing the
+// Client:
+//
+//     json.OnRequest = append(json.OnRequest, func(r *http.Request) {
+//       r.Header.Set(`X-Request-Id`, newRequestID())
+//     })
+//
+var (
+	OnRequest  []func(*http.Request)
+	OnResponse []func(*http.Response)
+)
+
 // Request is a human-friendly way to think of web requests and the
 // resulting JSON unmarshaled response. This design more similar to
 // a pragmatic curl request than the canonical specification (unique
@@ -33,13 +157,77 @@ var Client = http.DefaultClient
 // string. Encouraging the use of url.Values for passing the query
 // string serves as a reminder that all query strings should be URL
 // encoded (as is often forgotten).
+//
+// Any request headers (Authorization, Accept, etc.) are set with
+// Header (added to those set by the Client) with values that may refer
+// to secrets (see Secret):
+//
+//     json.Fetch(&json.Request{
+//       URL:    `https://api.github.com/user`,
+//       Header: map[string]string{
+//         `Accept`:        `application/vnd.github+json`,
+//         `Authorization`: `Bearer {{secret "github_token"}}`,
+//       },
+//       Into: &user,
+//     })
+//
 type Request struct {
 	Method string            // GET, POST, etc. (will upper)
 	URL    string            // base url with no query string
 	Query  url.Values        // query string to append to URL
 	Header map[string]string // never more than one of same
-	Body   url.Values        // body data, will JSON encode
+	Body   url.Values        // body data, will form encode
 	Into   any               // pointer to struct for unmarshaling
+
+	// JSON (instead of Body) is marshaled (see Marshal) and sent as the
+	// body with the application/json Content-Type, which is what most
+	// JSON APIs expect for POST, PUT, and PATCH.
+	JSON any
+
+	// Resolve overrides DNS for this request only (like curl --resolve)
+	// mapping a host (or host:port) to the IP address to connect to
+	// instead. The URL (and therefore Host header and TLS server name)
+	// is unchanged, which is exactly what is needed to reach a specific
+	// server behind a shared hostname (staging, for example).
+	Resolve map[string]string
+
+	// Network forces IPv4 (tcp4) or IPv6 (tcp6) for this request.
+	// Otherwise (empty) both are tried (happy eyeballs) as usual.
+	Network string
+
+	// Client (if set) sends this request instead of the package global
+	// Client.
+	Client *http.Client
+
+	// OnRequest hooks are called in order (after the package global
+	// OnRequest) with the http.Request just before it is sent (with
+	// secrets resolved) and may change it (to add an Authorization
+	// header with a fresh token, for example). OnResponse hooks are
+	// called the same way with every http.Response as soon as it is
+	// received (whatever its status) and must not read its Body.
+	OnRequest  []func(*http.Request)
+	OnResponse []func(*http.Response)
+
+	// Vendor (github, for example) sets the Accept header (unless in
+	// Header) to the vendor media type with the Version (or that of
+	// MediaVersions for the Vendor) such as application/vnd.github.v3+json.
+	// Versions maps the versions of the media type of the response to
+	// the pointers to unmarshal into (each a struct for that version)
+	// instead of Into (which is used for any other media type). See the
+	// MediaType of the Response for which was used.
+	Vendor   string
+	Version  string
+	Versions map[string]any
+
+	// RetryPolicies (if set) replaces the package global RetryPolicies
+	// for this request (an empty map for no retries at all).
+	RetryPolicies map[int]RetryPolicy
+
+	// OnBody hooks rewrite the raw body of the response after those of
+	// the package global OnBody (see there).
+	OnBody []func(buf []byte) ([]byte, error)
+
+	filled bool // placeholders (and secrets) already resolved (see fill)
 }
 
 // Fetch passes the Request Client and unmarshals the JSON response into
@@ -47,65 +235,362 @@ type Request struct {
 // leaving any existing data alone.
 //
 // All output data is expected to be JSON with the appropriate
-// headers added to indicate it.
+// headers added to indicate it unless the Content-Type of the response
+// has a registered Transcoder (see RegisterTranscoder) in which case
+// that is used to decode the response instead.
 //
 // If a Body is sent, it will be encoded as if submit from a POST form.
+// If JSON is sent instead, it will be marshaled as JSON.
 //
-// Fetch observes the package global json.TimeOut.
+// Fetch observes the package global json.TimeOut (and DialTimeOut,
+// etc.), ResponseSpec, OnProgress, RetryPolicies, and OnBody.
 //
-// Status codes not in th 200s range will return an error with the
-// status message.
+// Status codes not in th 200s range will return an *HTTPError with the
+// status message and body.
 //
 // The http.DefaultClient is used by default but can be changed by
 // setting json.Client.
-func Fetch(it *Request) error {
+func Fetch(it *Request) error { return FetchCtx(context.Background(), it) }
+
+// FetchCtx is the same as Fetch but sends the Request with the context
+// so that callers can set their own per-call deadline or cancel it.
+// The package global TimeOut applies only if the context has no
+// deadline. Exceeding the deadline returns a *TimeOutError (total) and
+// canceling returns an error wrapping context.Canceled.
+func FetchCtx(ctx context.Context, it *Request) error {
+	_, err := FetchResponse(ctx, it)
+	return err
+}
+
+// Response is the metadata and raw (undecoded) body of the response to
+// a Request (see FetchResponse).
+type Response struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	MediaType  MediaType // if a vendor media type (see Request.Vendor)
+}
+
+// HTTPError is returned by Fetch (and the rest) for responses with
+// status codes not in the 200s and keeps the body so that callers can
+// parse the error payload of the API:
+//
+//     var herr *json.HTTPError
+//     if errors.As(err, &herr) && herr.StatusCode == 422 {
+//       var payload struct{ Error string }
+//       json.Unmarshal(herr.Body, &payload)
+//     }
+//
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// Error fulfills the error interface with the status (404 Not Found,
+// for example).
+func (e *HTTPError) Error() string { return e.Status }
+
+// FetchResponse is the same as FetchCtx but also returns the Response
+// so that callers can read its status and headers (rate limits,
+// Location, pagination links, etc.). The Response is returned whenever
+// one was received, even with an error for a status code not in the
+// 200s (when it has the body of the error) or when unmarshaling fails.
+// With no Into the body is not unmarshaled at all.
+func FetchResponse(ctx context.Context, it *Request) (*Response, error) {
+	if _, has := ctx.Deadline(); !has {
+		dur := time.Duration(time.Second * time.Duration(TimeOut))
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dur)
+		defer cancel()
+	}
+
+	res, done, err := it.doRetry(ctx)
+	if err != nil {
+		var herr *HTTPError
+		if errors.As(err, &herr) {
+			mt, _ := ParseMediaType(herr.Header.Get("Content-Type"))
+			return &Response{herr.StatusCode, herr.Status, herr.Header, herr.Body, mt}, err
+		}
+		return nil, err
+	}
+	defer done()
+
+	pr := newProgressReader(res.Body, it.URL, res.ContentLength)
+	buf, err := io.ReadAll(pr)
+	pr.done()
+	if err != nil {
+		return nil, timeOutError(err)
+	}
+	buf, err = it.body(buf)
+	if err != nil {
+		return nil, err
+	}
+	mt, _ := ParseMediaType(res.Header.Get("Content-Type"))
+	r := &Response{res.StatusCode, res.Status, res.Header, buf, mt}
+	var serr error
+	if ResponseSpec != nil {
+		serr = ResponseSpec.ValidateResponse(it.Method, it.URL, res.StatusCode,
+			res.Header.Get("Content-Type"), buf)
+	}
+	into, err := it.into(mt)
+	if err != nil {
+		return r, err
+	}
+	if into == nil {
+		return r, serr
+	}
+	if t, has := TranscoderFor(res.Header.Get("Content-Type")); has {
+		err = t.Decode(buf, into)
+	} else {
+		err = Unmarshal(buf, into)
+	}
+	if err != nil {
+		return r, err
+	}
+	return r, serr
+}
+
+// doRetry calls do retrying any failures as the RetryPolicies say.
+func (it *Request) doRetry(ctx context.Context) (*http.Response, func(), error) {
+	u := it.URL
+	retries := map[int]int{}
+	for {
+		res, done, err := it.do(ctx)
+		if err == nil {
+			return res, done, nil
+		}
+		p, wait, ok := it.retry(err, retries)
+		if !ok {
+			return nil, nil, err
+		}
+		it.URL = u
+		if LogRoutes != nil {
+			host := u
+			if pu, perr := url.Parse(u); perr == nil {
+				host = pu.Hostname()
+			}
+			logf(`req`, host, LogInfo, `%s %s: %v (retrying in %v)`,
+				it.Method, u, err, wait.Round(time.Millisecond))
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, nil, timeOutError(ctx.Err())
+		case <-t.C:
+		}
+		if p.Refresh != nil {
+			if err := p.Refresh(ctx); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// do sends the Request with the context returning the response (only
+// if the status is in the 200s) and the function to call when done
+// with it.
+func (it *Request) do(ctx context.Context) (*http.Response, func(), error) {
 	var err error
 	var bodyreader io.Reader
 	var bodylength string
 
+	resolve, resolveAll := resolveSecrets, resolveValues
+	if it.filled {
+		resolve = func(s string) (string, error) { return s, nil }
+		resolveAll = func(v url.Values) (url.Values, error) { return v, nil }
+	}
+
+	base, err := resolve(it.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err := resolveAll(it.Query)
+	if err != nil {
+		return nil, nil, err
+	}
 	it.URL = it.URL + "?" + it.Query.Encode()
 	if it.Method == "" {
 		it.Method = `GET`
 	}
 
-	if it.Body != nil {
-		encoded := it.Body.Encode()
+	var bodytype string
+	switch {
+	case it.Body != nil && it.JSON != nil:
+		return nil, nil, fmt.Errorf(`cannot send both Body and JSON`)
+	case it.Body != nil:
+		body, err := resolveAll(it.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded := body.Encode()
 		bodyreader = strings.NewReader(encoded)
 		bodylength = strconv.Itoa(len(encoded))
+		bodytype = "application/x-www-form-urlencoded"
+	case it.JSON != nil:
+		buf, err := Marshal(it.JSON)
+		if err != nil {
+			return nil, nil, err
+		}
+		bodyreader = bytes.NewReader(buf)
+		bodylength = strconv.Itoa(len(buf))
+		bodytype = "application/json"
 	}
 
-	req, err := http.NewRequest(it.Method, it.URL, bodyreader)
-	if it.Body != nil {
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-		req.Header.Add("Content-Length", bodylength)
-	}
+	req, err := http.NewRequest(it.Method, base+"?"+query.Encode(), bodyreader)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if bodytype != "" {
+		req.Header.Add("Content-Type", bodytype)
+		req.Header.Add("Content-Length", bodylength)
 	}
 
 	if it.Header != nil {
 		for k, v := range it.Header {
+			v, err := resolve(v)
+			if err != nil {
+				return nil, nil, err
+			}
 			req.Header.Add(k, v)
 		}
 	}
-
-	dur := time.Duration(time.Second * time.Duration(TimeOut))
-	ctx, cancel := context.WithTimeout(context.Background(), dur)
-	defer cancel()
+	if accept := it.accept(); accept != "" && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", accept)
+	}
 	req = req.WithContext(ctx)
+	for _, hook := range OnRequest {
+		hook(req)
+	}
+	for _, hook := range it.OnRequest {
+		hook(req)
+	}
+
+	client := Client
+	if it.Client != nil {
+		client = it.Client
+	}
+	client = fetchClient(client)
+	own := it.Resolve != nil || it.Network != ""
+	if own {
+		client = it.dialClient(client)
+	}
 
-	res, err := Client.Do(req)
+	if LogRoutes != nil && logEnabled(`req`, LogDebug) {
+		names := make([]string, 0, len(req.Header))
+		for k := range req.Header {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		logf(`req`, req.URL.Hostname(), LogDebug, `%s %s headers: %s`,
+			it.Method, it.URL, strings.Join(names, `, `))
+	}
+	start := time.Now()
+	res, err := client.Do(req)
 	if err != nil {
-		return err
+		if own {
+			client.CloseIdleConnections()
+		}
+		err = timeOutError(err)
+		if LogRoutes != nil {
+			logf(`req`, req.URL.Hostname(), LogError, `%s %s: %v`, it.Method, it.URL, err)
+		}
+		return nil, nil, err
+	}
+	for _, hook := range OnResponse {
+		hook(res)
+	}
+	for _, hook := range it.OnResponse {
+		hook(res)
+	}
+	if LogRoutes != nil {
+		level := LogInfo
+		if !(200 <= res.StatusCode && res.StatusCode < 300) {
+			level = LogError
+		}
+		logf(`req`, req.URL.Hostname(), level, `%s %s %s (%v)`,
+			it.Method, it.URL, res.Status, time.Since(start).Round(time.Millisecond))
+	}
+	done := func() {
+		res.Body.Close()
+		if own {
+			client.CloseIdleConnections()
+		}
 	}
 
 	if !(200 <= res.StatusCode && res.StatusCode < 300) {
-		return fmt.Errorf(res.Status)
+		body, _ := io.ReadAll(res.Body)
+		done()
+		return nil, nil, &HTTPError{res.StatusCode, res.Status, res.Header, body}
+	}
+	return res, done, nil
+}
+
+// dialClient returns a copy of the client (with its own transport)
+// observing the Resolve and Network settings of the Request. The
+// client is returned as is if its transport cannot be cloned.
+func (it *Request) dialClient(client *http.Client) *http.Client {
+	var tr *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		return client
+	}
+	dialer := &net.Dialer{Timeout: DialTimeOut, KeepAlive: 30 * time.Second}
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			ip, has := it.Resolve[addr]
+			if !has {
+				ip, has = it.Resolve[host]
+			}
+			if has {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		if it.Network != "" {
+			network = it.Network
+		}
+		return dialer.DialContext(ctx, network, addr)
 	}
+	c := *client
+	c.Transport = tr
+	return &c
+}
 
-	buf, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
+// Get sends a GET request (see FetchCtx) to the URL with the query
+// string values (if any) returning the response unmarshaled into a new
+// value of the type:
+//
+//     user, err := json.Get[User](ctx, api+`/users/me`, nil)
+//
+func Get[T any](ctx context.Context, url string, query url.Values) (T, error) {
+	var v T
+	err := FetchCtx(ctx, &Request{URL: url, Query: query, Into: &v})
+	return v, err
+}
+
+// Post sends a POST request (see FetchCtx) to the URL with the body
+// marshaled as JSON (see Request.JSON) returning the response
+// unmarshaled into a new value of the type:
+//
+//     created, err := json.Post[User](ctx, api+`/users`, User{Name: `ann`})
+//
+func Post[T any](ctx context.Context, url string, body any) (T, error) {
+	var v T
+	err := FetchCtx(ctx, &Request{Method: `POST`, URL: url, JSON: body, Into: &v})
+	return v, err
+}
+
+// MustFetch is the same as Fetch but panics on any error. Use it only
+// for quick scripts and examples.
+func MustFetch(it *Request) {
+	if err := Fetch(it); err != nil {
+		panic(err)
 	}
-	return json.Unmarshal(buf, it.Into)
 }
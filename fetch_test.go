@@ -1,9 +1,14 @@
 package json_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	_http "net/http"
 	ht "net/http/httptest"
+	"net/url"
+	"time"
 
 	json "github.com/rwxrob/json"
 )
@@ -125,3 +130,347 @@ func ExampleFetch() {
 	// {"get":"t","post":"t","put":"t","patch":"t","delete":"","c":"t","i":"i"}
 	// {"get":"t","post":"t","put":"t","patch":"t","delete":"t","c":"t","i":"i"}
 }
+
+func ExampleMustFetch() {
+
+	handler := _http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"get":"t"}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	data := map[string]string{}
+	json.MustFetch(&json.Request{URL: svr.URL, Into: &data})
+	fmt.Println(data)
+
+	defer func() { fmt.Println(recover()) }()
+	json.MustFetch(&json.Request{URL: svr.URL, Into: new(int)})
+
+	// Output:
+	// map[get:t]
+	// json: cannot unmarshal object into Go value of type int
+}
+
+func ExampleFetch_contentType() {
+
+	handler := _http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			fmt.Fprintln(w, `{"id":1}`)
+			fmt.Fprintln(w, `{"id":2}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	var data []map[string]int
+	if err := json.Fetch(&json.Request{URL: svr.URL, Into: &data}); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data)
+
+	// Output:
+	// [map[id:1] map[id:2]]
+}
+
+func ExampleHeaderTimeOut() {
+
+	handler := _http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprintf(w, `{}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	json.HeaderTimeOut = 20 * time.Millisecond
+	defer func() { json.HeaderTimeOut = 0 }()
+
+	err := json.Fetch(&json.Request{URL: svr.URL, Into: new(any)})
+	var terr *json.TimeOutError
+	if errors.As(err, &terr) {
+		fmt.Println(terr.Phase)
+	}
+
+	// Output:
+	// header
+}
+
+func ExampleFetchCtx() {
+
+	handler := _http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprintf(w, `{}`)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := json.FetchCtx(ctx, &json.Request{URL: svr.URL, Into: new(any)})
+	var terr *json.TimeOutError
+	if errors.As(err, &terr) {
+		fmt.Println(terr.Phase)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = json.FetchCtx(ctx, &json.Request{URL: svr.URL, Into: new(any)})
+	fmt.Println(errors.Is(err, context.Canceled))
+
+	// Output:
+	// total
+	// true
+}
+
+func ExampleFetchResponse() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`X-RateLimit-Remaining`, `42`)
+			if r.URL.Path == `/missing` {
+				w.WriteHeader(404)
+				fmt.Fprint(w, `{"error":"no such thing"}`)
+				return
+			}
+			w.Header().Set(`Location`, `/things/1`)
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"id":1}`)
+		}))
+	defer svr.Close()
+
+	var got struct{ ID int }
+	res, err := json.FetchResponse(context.Background(), &json.Request{
+		Method: `POST`,
+		URL:    svr.URL + `/things`,
+		Into:   &got,
+	})
+	fmt.Println(err, got.ID, res.StatusCode, res.Header.Get(`Location`))
+	fmt.Println(res.Header.Get(`X-RateLimit-Remaining`), string(res.Body))
+
+	res, err = json.FetchResponse(context.Background(), &json.Request{
+		URL: svr.URL + `/missing`,
+	})
+	fmt.Println(err, res.StatusCode, string(res.Body))
+
+	// Output:
+	// <nil> 1 201 /things/1
+	// 42 {"id":1}
+	// 404 Not Found 404 {"error":"no such thing"}
+}
+
+func ExampleHTTPError() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			w.WriteHeader(422)
+			fmt.Fprint(w, `{"error":"name is required"}`)
+		}))
+	defer svr.Close()
+
+	err := json.Fetch(&json.Request{Method: `POST`, URL: svr.URL, Into: new(any)})
+	fmt.Println(err)
+
+	var herr *json.HTTPError
+	if errors.As(err, &herr) {
+		var payload struct{ Error string }
+		json.Unmarshal(herr.Body, &payload)
+		fmt.Println(herr.StatusCode, herr.Header.Get(`Content-Type`), payload.Error)
+	}
+
+	// Output:
+	// 422 Unprocessable Entity
+	// 422 application/json name is required
+}
+
+func ExampleRequest_hooks() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`X-RateLimit-Remaining`, `41`)
+			fmt.Fprintf(w, `{"auth":%q,"id":%q}`,
+				r.Header.Get(`Authorization`), r.Header.Get(`X-Request-Id`))
+		}))
+	defer svr.Close()
+
+	json.OnRequest = []func(*_http.Request){
+		func(r *_http.Request) { r.Header.Set(`X-Request-Id`, `42`) },
+	}
+	defer func() { json.OnRequest = nil }()
+
+	token := `fresh`
+	var got map[string]any
+	err := json.Fetch(&json.Request{
+		URL:    svr.URL,
+		Client: &_http.Client{Timeout: time.Second},
+		OnRequest: []func(*_http.Request){
+			func(r *_http.Request) { r.Header.Set(`Authorization`, `Bearer `+token) },
+		},
+		OnResponse: []func(*_http.Response){
+			func(r *_http.Response) {
+				fmt.Println(r.StatusCode, r.Header.Get(`X-RateLimit-Remaining`))
+			},
+		},
+		Into: &got,
+	})
+	fmt.Println(json.This{got}, err)
+
+	// Output:
+	// 200 41
+	// {"auth":"Bearer fresh","id":"42"} <nil>
+}
+
+func ExampleGet() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"name":%q}`, r.URL.Query().Get(`name`))
+		}))
+	defer svr.Close()
+
+	type User struct{ Name string }
+	user, err := json.Get[User](context.Background(), svr.URL, url.Values{"name": {"ann"}})
+	fmt.Println(user.Name, err)
+
+	// Output:
+	// ann <nil>
+}
+
+func ExamplePost() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"method":%q,"got":`, r.Method)
+			io.Copy(w, r.Body)
+			fmt.Fprint(w, `}`)
+		}))
+	defer svr.Close()
+
+	type Result struct {
+		Method string
+		Got    map[string]any
+	}
+	res, err := json.Post[Result](context.Background(), svr.URL, map[string]any{"name": "ann"})
+	fmt.Println(res.Method, res.Got, err)
+
+	// Output:
+	// POST map[name:ann] <nil>
+}
+
+func ExampleRequest_resolve() {
+
+	handler := _http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"host":%q}`, r.Host)
+		})
+	svr := ht.NewServer(handler)
+	defer svr.Close()
+	u, _ := url.Parse(svr.URL)
+
+	data := map[string]string{}
+	err := json.Fetch(&json.Request{
+		URL:     "http://api.example.com:" + u.Port(),
+		Resolve: map[string]string{"api.example.com": u.Hostname()},
+		Network: "tcp4",
+		Into:    &data,
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data["host"] == "api.example.com:"+u.Port())
+
+	// Output:
+	// true
+}
+
+func ExampleRequest_header() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"accept":%q,"auth":%q,"custom":%q}`,
+				r.Header.Get(`Accept`), r.Header.Get(`Authorization`),
+				r.Header.Get(`X-Custom`))
+		}))
+	defer svr.Close()
+
+	var got map[string]string
+	err := json.Fetch(&json.Request{
+		URL: svr.URL,
+		Header: map[string]string{
+			`Accept`:        `application/vnd.api+json`,
+			`Authorization`: `Bearer abc`,
+			`X-Custom`:      `yes`,
+		},
+		Into: &got,
+	})
+	fmt.Println(err, got[`accept`], got[`auth`], got[`custom`])
+
+	// Output:
+	// <nil> application/vnd.api+json Bearer abc yes
+}
+
+func ExampleRequest_json() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			fmt.Fprintf(w, `{"type":%q,"body":`, r.Header.Get(`Content-Type`))
+			io.Copy(w, r.Body)
+			fmt.Fprint(w, `}`)
+		}))
+	defer svr.Close()
+
+	var got map[string]any
+	err := json.Fetch(&json.Request{
+		Method: `POST`,
+		URL:    svr.URL,
+		JSON:   map[string]any{"name": "<ann>", "tags": []string{"a"}},
+		Into:   &got,
+	})
+	fmt.Println(err, json.This{got})
+
+	err = json.Fetch(&json.Request{
+		URL:  svr.URL,
+		Body: url.Values{"a": {"1"}},
+		JSON: 1,
+	})
+	fmt.Println(err)
+
+	// Output:
+	// <nil> {"body":{"name":"<ann>","tags":["a"]},"type":"application/json"}
+	// cannot send both Body and JSON
+}
+
+func ExampleRequest_vendor() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Println(r.Header.Get(`Accept`))
+			if r.Header.Get(`Accept`) == `application/vnd.shop.v1+json` {
+				w.Header().Set(`Content-Type`, `application/vnd.shop.v1+json`)
+				fmt.Fprint(w, `{"name":"Ada Lovelace"}`)
+				return
+			}
+			w.Header().Set(`Content-Type`, `application/vnd.shop.v2+json; charset=utf-8`)
+			fmt.Fprint(w, `{"first":"Ada","last":"Lovelace"}`)
+		}))
+	defer svr.Close()
+
+	type UserV1 struct{ Name string }
+	type UserV2 struct{ First, Last string }
+
+	json.MediaVersions[`shop`] = `v2`
+	defer delete(json.MediaVersions, `shop`)
+
+	for _, version := range []string{``, `v1`} {
+		var v1 UserV1
+		var v2 UserV2
+		res, err := json.FetchResponse(context.Background(), &json.Request{
+			URL:      svr.URL,
+			Vendor:   `shop`,
+			Version:  version,
+			Versions: map[string]any{`v1`: &v1, `v2`: &v2},
+		})
+		fmt.Println(err, res.MediaType.Version, v1, v2)
+	}
+
+	// Output:
+	// application/vnd.shop.v2+json
+	// <nil> v2 {} {Ada Lovelace}
+	// application/vnd.shop.v1+json
+	// <nil> v1 {Ada Lovelace} { }
+}
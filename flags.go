@@ -0,0 +1,301 @@
+package json
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flags bridges JSON (configuration) documents and command-line flags.
+// Every leaf of the document shape (described by a struct or a JSON
+// Schema) becomes a single flag named by its dotted path (server.port,
+// for example) which can be defined on a standard flag.FlagSet (Define)
+// or on any other flag library (Visit). Once the flags are parsed, the
+// resulting document is available with JSON, Value, or Unmarshal.
+type Flags struct {
+	leaves []*flagLeaf
+}
+
+// flag kinds (named for their JSON Schema types)
+const (
+	flagString  = `string`
+	flagNumber  = `number`
+	flagInteger = `integer`
+	flagBoolean = `boolean`
+	flagArray   = `array`
+	flagJSON    = `json` // anything else (objects, marshalers, etc.)
+)
+
+type flagLeaf struct {
+	path  []string
+	kind  string
+	items string // kind of array items
+	usage string
+	value any // default until set
+}
+
+// NewFlags returns the Flags for the given source which is either
+// a struct (or pointer to one) or a JSON Schema (as a string, []byte,
+// or map[string]any). For structs, the json field names are used and
+// the current field values become the flag defaults. A usage struct
+// tag provides the flag usage. For schemas, the properties (nested
+// objects are followed), type, default, items type, and description
+// are used. Anything without a simple type (maps, types with their own
+// marshaling, untyped properties) expects JSON but accepts a plain
+// string as well.
+func NewFlags(src any) (*Flags, error) {
+	f := new(Flags)
+	switch s := src.(type) {
+	case string:
+		return f, f.fromSchema([]byte(s))
+	case []byte:
+		return f, f.fromSchema(s)
+	case map[string]any:
+		f.schema(nil, s)
+		return f, nil
+	}
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(`flags require a struct or schema, not %T`, src)
+	}
+	f.structure(nil, v.Type(), v)
+	return f, nil
+}
+
+func (f *Flags) fromSchema(buf []byte) error {
+	var s map[string]any
+	if err := Unmarshal(buf, &s); err != nil {
+		return err
+	}
+	f.schema(nil, s)
+	return nil
+}
+
+// schema adds the leaves for the properties of the schema object.
+func (f *Flags) schema(path []string, s map[string]any) {
+	props, _ := s[`properties`].(map[string]any)
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p, _ := props[k].(map[string]any)
+		kpath := append(path[:len(path):len(path)], k)
+		typ, _ := p[`type`].(string)
+		if _, has := p[`properties`]; has && (typ == `object` || typ == ``) {
+			f.schema(kpath, p)
+			continue
+		}
+		leaf := &flagLeaf{path: kpath, kind: flagJSON, value: p[`default`]}
+		leaf.usage, _ = p[`description`].(string)
+		switch typ {
+		case flagString, flagNumber, flagInteger, flagBoolean:
+			leaf.kind = typ
+		case flagArray:
+			leaf.kind = flagArray
+			items, _ := p[`items`].(map[string]any)
+			leaf.items, _ = items[`type`].(string)
+		}
+		f.leaves = append(f.leaves, leaf)
+	}
+}
+
+// structure adds the leaves for the fields of the struct type (v may be
+// invalid when there are no current values).
+func (f *Flags) structure(path []string, t reflect.Type, v reflect.Value) {
+	for _, fd := range typeFields(t) {
+		sf := t.FieldByIndex(fd.index)
+		var fv reflect.Value
+		if v.IsValid() {
+			fv, _ = fieldByIndex(v, fd.index)
+		}
+		kpath := append(path[:len(path):len(path)], fd.name)
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				if fv.IsNil() {
+					fv = reflect.Value{}
+				} else {
+					fv = fv.Elem()
+				}
+			}
+		}
+		if ft.Kind() == reflect.Struct && !typeMarshals(ft) {
+			f.structure(kpath, ft, fv)
+			continue
+		}
+		leaf := &flagLeaf{
+			path:  kpath,
+			kind:  kindOf(ft),
+			usage: sf.Tag.Get(`usage`),
+		}
+		if leaf.kind == flagArray {
+			leaf.items = kindOf(ft.Elem())
+		}
+		if fv.IsValid() && !isEmptyValue(fv) {
+			leaf.value, _ = generic(fv.Interface())
+		}
+		f.leaves = append(f.leaves, leaf)
+	}
+}
+
+// kindOf returns the flag kind for the Go type.
+func kindOf(t reflect.Type) string {
+	if typeMarshals(t) {
+		return flagJSON
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return flagString
+	case reflect.Bool:
+		return flagBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return flagInteger
+	case reflect.Float32, reflect.Float64:
+		return flagNumber
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return flagArray
+		}
+	}
+	return flagJSON
+}
+
+// typeMarshals returns true if the type (or a pointer to it) renders
+// itself as JSON or text.
+func typeMarshals(t reflect.Type) bool {
+	for _, t := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if t.Implements(marshalerType) || t.Implements(textMarshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Define defines every flag on the standard flag.FlagSet.
+func (f *Flags) Define(fs *flag.FlagSet) {
+	f.Visit(func(name, usage string, v flag.Value) { fs.Var(v, name, usage) })
+}
+
+// Visit calls the function for every flag (in order) so that they can
+// be defined with any flag library. Each flag.Value also implements
+// IsBoolFlag and Type (as required by pflag and cobra).
+func (f *Flags) Visit(fn func(name, usage string, v flag.Value)) {
+	for _, l := range f.leaves {
+		fn(strings.Join(l.path, `.`), l.usage, flagValue{l})
+	}
+}
+
+// Value returns the document (in generic form) from the current flag
+// values. Flags without values (no default and never set) are left
+// out.
+func (f *Flags) Value() any {
+	doc := map[string]any{}
+	for _, l := range f.leaves {
+		if l.value == nil {
+			continue
+		}
+		m := doc
+		for _, k := range l.path[:len(l.path)-1] {
+			sub, is := m[k].(map[string]any)
+			if !is {
+				sub = map[string]any{}
+				m[k] = sub
+			}
+			m = sub
+		}
+		m[l.path[len(l.path)-1]] = l.value
+	}
+	return doc
+}
+
+// JSON returns the document from the current flag values (see Value).
+func (f *Flags) JSON() ([]byte, error) { return Marshal(f.Value()) }
+
+// Unmarshal unmarshals the document from the current flag values into
+// the value passed by pointer (usually the same struct type used with
+// NewFlags).
+func (f *Flags) Unmarshal(v any) error {
+	buf, err := f.JSON()
+	if err != nil {
+		return err
+	}
+	return Unmarshal(buf, v)
+}
+
+// flagValue implements flag.Value (and pflag.Value) for a leaf.
+type flagValue struct{ leaf *flagLeaf }
+
+func (v flagValue) Type() string     { return v.leaf.kind }
+func (v flagValue) IsBoolFlag() bool { return v.leaf.kind == flagBoolean }
+
+func (v flagValue) String() string {
+	if v.leaf == nil || v.leaf.value == nil {
+		return ``
+	}
+	if s, is := v.leaf.value.(string); is {
+		return s
+	}
+	if a, is := v.leaf.value.([]any); is && v.leaf.kind == flagArray {
+		items := make([]string, len(a))
+		for i, item := range a {
+			items[i] = flagValue{&flagLeaf{value: item}}.String()
+		}
+		return strings.Join(items, `,`)
+	}
+	buf, _ := Marshal(v.leaf.value)
+	return string(buf)
+}
+
+// Set parses the flag argument according to the kind of flag. Arrays
+// are comma separated.
+func (v flagValue) Set(arg string) error {
+	if v.leaf.kind != flagArray {
+		val, err := parseFlag(v.leaf.kind, arg)
+		if err != nil {
+			return err
+		}
+		v.leaf.value = val
+		return nil
+	}
+	var items []any
+	if arg != `` {
+		for _, s := range strings.Split(arg, `,`) {
+			val, err := parseFlag(v.leaf.items, s)
+			if err != nil {
+				return err
+			}
+			items = append(items, val)
+		}
+	}
+	v.leaf.value = items
+	return nil
+}
+
+// parseFlag parses a single flag argument into its generic JSON form.
+func parseFlag(kind, arg string) (any, error) {
+	switch kind {
+	case flagString:
+		return arg, nil
+	case flagBoolean:
+		return strconv.ParseBool(arg)
+	case flagInteger:
+		return strconv.ParseInt(arg, 10, 64)
+	case flagNumber:
+		return strconv.ParseFloat(arg, 64)
+	}
+	var val any
+	if err := Unmarshal([]byte(arg), &val); err != nil {
+		return arg, nil
+	}
+	return val, nil
+}
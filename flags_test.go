@@ -0,0 +1,79 @@
+package json_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleNewFlags() {
+	type Config struct {
+		Name   string   `json:"name" usage:"service name"`
+		Tags   []string `json:"tags,omitempty" usage:"comma separated"`
+		Server struct {
+			Host string `json:"host" usage:"listen host"`
+			Port int    `json:"port" usage:"listen port"`
+		} `json:"server"`
+		Debug bool `json:"debug" usage:"verbose logging"`
+	}
+
+	conf := Config{Name: "api"}
+	conf.Server.Port = 8080
+
+	flags, _ := json.NewFlags(&conf)
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	flags.Define(fs)
+	fs.PrintDefaults()
+
+	fs.Parse([]string{"-server.host", "localhost", "-debug", "-tags", "a,b"})
+	buf, _ := flags.JSON()
+	fmt.Println(string(buf))
+
+	var got Config
+	flags.Unmarshal(&got)
+	fmt.Println(got.Name, got.Server.Host, got.Server.Port, got.Debug, got.Tags)
+
+	// Output:
+	//   -debug
+	//     	verbose logging
+	//   -name value
+	//     	service name (default api)
+	//   -server.host value
+	//     	listen host
+	//   -server.port value
+	//     	listen port (default 8080)
+	//   -tags value
+	//     	comma separated
+	// {"debug":true,"name":"api","server":{"host":"localhost","port":8080},"tags":["a","b"]}
+	// api localhost 8080 true [a b]
+}
+
+func ExampleNewFlags_schema() {
+	flags, _ := json.NewFlags(`{
+		"type": "object",
+		"properties": {
+			"retries": {"type": "integer", "default": 3, "description": "attempts"},
+			"labels": {"description": "extra labels"},
+			"limits": {
+				"properties": {
+					"rate": {"type": "number", "description": "per second"}
+				}
+			}
+		}
+	}`)
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.Define(fs)
+	fs.Parse([]string{"-limits.rate", "2.5", "-labels", `{"env":"dev"}`})
+	fs.VisitAll(func(f *flag.Flag) { fmt.Println(f.Name, f.Value, f.Usage) })
+	buf, _ := flags.JSON()
+	fmt.Println(string(buf))
+
+	// Output:
+	// labels {"env":"dev"} extra labels
+	// limits.rate 2.5 per second
+	// retries 3 attempts
+	// {"labels":{"env":"dev"},"limits":{"rate":2.5},"retries":3}
+}
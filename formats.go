@@ -0,0 +1,319 @@
+package json
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// UUID, IP, URL, and Email are the common string formats (see JSON
+// Schema) as types that are validated when unmarshaled (or parsed) and
+// always marshaled in canonical form so that fields of them never need
+// any more validation. Each implements AsJSON (String is the JSON
+// string) and encoding.TextMarshaler (for the text alone and map keys).
+// Empty strings unmarshal to the zero value of IP, URL, and Email which
+// marshal as empty strings again. Null leaves any of them untouched.
+//
+//     type User struct {
+//       ID    json.UUID  `json:"id"`
+//       Email json.Email `json:"email"`
+//       Site  json.URL   `json:"site,omitempty"`
+//       Last  json.IP    `json:"last"`
+//     }
+//
+var (
+	_ AsJSON = (*UUID)(nil)
+	_ AsJSON = (*IP)(nil)
+	_ AsJSON = (*URL)(nil)
+	_ AsJSON = (*Email)(nil)
+)
+
+// textJSON returns the text of a format as a JSON string.
+func textJSON(text []byte) []byte { return []byte(quote(string(text))) }
+
+// unquoteText returns the text of the JSON string of a format (false
+// for null).
+func unquoteText(buf []byte) (string, bool, error) {
+	if string(buf) == `null` {
+		return "", false, nil
+	}
+	var s string
+	if err := Unmarshal(buf, &s); err != nil {
+		return "", false, err
+	}
+	return s, true, nil
+}
+
+// ------------------------------------ UUID -----------------------------------
+
+// UUID is a universally unique identifier (RFC 4122) of any version. It
+// parses with or without braces or a urn:uuid: prefix in either case and
+// is always formatted in lowercase with hyphens (8-4-4-4-12).
+type UUID [16]byte
+
+// ParseUUID parses the UUID (see UUID).
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	str := strings.TrimSpace(s)
+	if len(str) > 9 && strings.EqualFold(str[:9], `urn:uuid:`) {
+		str = str[9:]
+	}
+	if len(str) == 38 && str[0] == '{' && str[37] == '}' {
+		str = str[1:37]
+	}
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return u, fmt.Errorf(`invalid UUID: %q`, s)
+	}
+	str = str[:8] + str[9:13] + str[14:18] + str[19:23] + str[24:]
+	if _, err := hex.Decode(u[:], []byte(str)); err != nil {
+		return u, fmt.Errorf(`invalid UUID: %q`, s)
+	}
+	return u, nil
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface with the
+// canonical form.
+func (u UUID) MarshalText() ([]byte, error) {
+	h := hex.EncodeToString(u[:])
+	return []byte(h[:8] + `-` + h[8:12] + `-` + h[12:16] + `-` + h[16:20] + `-` + h[20:]), nil
+}
+
+// UnmarshalText fulfills the encoding.TextUnmarshaler interface (see
+// ParseUUID).
+func (u *UUID) UnmarshalText(text []byte) error {
+	v, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// MarshalJSON implements AsJSON.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	text, _ := u.MarshalText()
+	return textJSON(text), nil
+}
+
+// UnmarshalJSON implements AsJSON validating the UUID.
+func (u *UUID) UnmarshalJSON(buf []byte) error {
+	s, ok, err := unquoteText(buf)
+	if !ok {
+		return err
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// JSON implements AsJSON (see This.JSON).
+func (u UUID) JSON() ([]byte, error) { return This{u}.JSON() }
+
+// String implements AsJSON (see This.String).
+func (u UUID) String() string { return This{u}.String() }
+
+// Print implements AsJSON (see This.Print).
+func (u UUID) Print() { This{u}.Print() }
+
+// Log implements AsJSON (see This.Log).
+func (u UUID) Log() string { return This{u}.Log() }
+
+// ------------------------------------- IP ------------------------------------
+
+// IP is an IPv4 or IPv6 address (with an optional IPv6 zone) formatted
+// as by netip.Addr (IPv6 compressed and in lowercase). The methods of
+// netip.Addr (Is4, IsLoopback, and so on) are promoted.
+type IP struct{ netip.Addr }
+
+// ParseIP parses the IP address (see netip.ParseAddr).
+func ParseIP(s string) (IP, error) {
+	a, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return IP{}, fmt.Errorf(`invalid IP: %q`, s)
+	}
+	return IP{a}, nil
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface with the
+// canonical form (empty for the zero IP).
+func (ip IP) MarshalText() ([]byte, error) { return ip.Addr.MarshalText() }
+
+// UnmarshalText fulfills the encoding.TextUnmarshaler interface (see
+// ParseIP).
+func (ip *IP) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*ip = IP{}
+		return nil
+	}
+	v, err := ParseIP(string(text))
+	if err != nil {
+		return err
+	}
+	*ip = v
+	return nil
+}
+
+// MarshalJSON implements AsJSON.
+func (ip IP) MarshalJSON() ([]byte, error) {
+	text, err := ip.MarshalText()
+	return textJSON(text), err
+}
+
+// UnmarshalJSON implements AsJSON validating the IP.
+func (ip *IP) UnmarshalJSON(buf []byte) error {
+	s, ok, err := unquoteText(buf)
+	if !ok {
+		return err
+	}
+	return ip.UnmarshalText([]byte(s))
+}
+
+// JSON implements AsJSON (see This.JSON).
+func (ip IP) JSON() ([]byte, error) { return This{ip}.JSON() }
+
+// String implements AsJSON (see This.String).
+func (ip IP) String() string { return This{ip}.String() }
+
+// Print implements AsJSON (see This.Print).
+func (ip IP) Print() { This{ip}.Print() }
+
+// Log implements AsJSON (see This.Log).
+func (ip IP) Log() string { return This{ip}.Log() }
+
+// ------------------------------------ URL ------------------------------------
+
+// URL is an absolute URL (with a scheme) with the scheme and host in
+// lowercase. The fields and methods of url.URL (Host, Query, and so on)
+// are promoted.
+type URL struct{ url.URL }
+
+// ParseURL parses the absolute URL (see url.Parse).
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || !u.IsAbs() {
+		return URL{}, fmt.Errorf(`invalid URL: %q`, s)
+	}
+	u.Host = strings.ToLower(u.Host)
+	return URL{*u}, nil
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface with the
+// canonical form (empty for the zero URL).
+func (u URL) MarshalText() ([]byte, error) { return []byte(u.URL.String()), nil }
+
+// UnmarshalText fulfills the encoding.TextUnmarshaler interface (see
+// ParseURL).
+func (u *URL) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = URL{}
+		return nil
+	}
+	v, err := ParseURL(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// MarshalJSON implements AsJSON.
+func (u URL) MarshalJSON() ([]byte, error) {
+	text, _ := u.MarshalText()
+	return textJSON(text), nil
+}
+
+// UnmarshalJSON implements AsJSON validating the URL.
+func (u *URL) UnmarshalJSON(buf []byte) error {
+	s, ok, err := unquoteText(buf)
+	if !ok {
+		return err
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// JSON implements AsJSON (see This.JSON).
+func (u URL) JSON() ([]byte, error) { return This{u}.JSON() }
+
+// String implements AsJSON (see This.String).
+func (u URL) String() string { return This{u}.String() }
+
+// Print implements AsJSON (see This.Print).
+func (u URL) Print() { This{u}.Print() }
+
+// Log implements AsJSON (see This.Log).
+func (u URL) Log() string { return This{u}.Log() }
+
+// ----------------------------------- Email -----------------------------------
+
+// Email is a single bare email address (RFC 5322 without any display
+// name or angle brackets) with the domain in lowercase (the local part
+// is case-sensitive and kept as is).
+type Email struct{ addr string }
+
+// ParseEmail parses the bare email address (see net/mail.ParseAddress).
+func ParseEmail(s string) (Email, error) {
+	str := strings.TrimSpace(s)
+	a, err := mail.ParseAddress(str)
+	if err != nil || a.Name != "" || a.Address != str {
+		return Email{}, fmt.Errorf(`invalid email: %q`, s)
+	}
+	at := strings.LastIndexByte(str, '@')
+	return Email{str[:at] + strings.ToLower(str[at:])}, nil
+}
+
+// Local returns the part of the address before the last @.
+func (e Email) Local() string {
+	if at := strings.LastIndexByte(e.addr, '@'); at >= 0 {
+		return e.addr[:at]
+	}
+	return ""
+}
+
+// Domain returns the part of the address after the last @.
+func (e Email) Domain() string {
+	return e.addr[strings.LastIndexByte(e.addr, '@')+1:]
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface with the
+// canonical form (empty for the zero Email).
+func (e Email) MarshalText() ([]byte, error) { return []byte(e.addr), nil }
+
+// UnmarshalText fulfills the encoding.TextUnmarshaler interface (see
+// ParseEmail).
+func (e *Email) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = Email{}
+		return nil
+	}
+	v, err := ParseEmail(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// MarshalJSON implements AsJSON.
+func (e Email) MarshalJSON() ([]byte, error) { return textJSON([]byte(e.addr)), nil }
+
+// UnmarshalJSON implements AsJSON validating the Email.
+func (e *Email) UnmarshalJSON(buf []byte) error {
+	s, ok, err := unquoteText(buf)
+	if !ok {
+		return err
+	}
+	return e.UnmarshalText([]byte(s))
+}
+
+// JSON implements AsJSON (see This.JSON).
+func (e Email) JSON() ([]byte, error) { return This{e}.JSON() }
+
+// String implements AsJSON (see This.String).
+func (e Email) String() string { return This{e}.String() }
+
+// Print implements AsJSON (see This.Print).
+func (e Email) Print() { This{e}.Print() }
+
+// Log implements AsJSON (see This.Log).
+func (e Email) Log() string { return This{e}.Log() }
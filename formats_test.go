@@ -0,0 +1,59 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleUUID() {
+	type User struct {
+		ID    json.UUID  `json:"id"`
+		Email json.Email `json:"email"`
+		Site  json.URL   `json:"site"`
+		Last  json.IP    `json:"last"`
+	}
+
+	var u User
+	err := json.Unmarshal([]byte(`{
+		"id": "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}",
+		"email": "Ann.Lee@Example.COM",
+		"site": "HTTPS://Example.com/a?b=1",
+		"last": "2001:0DB8::0001"
+	}`), &u)
+	fmt.Println(err)
+	fmt.Println(json.This{u})
+	fmt.Println(u.Email.Local(), u.Site.Host, u.Last.Is6())
+	u.ID.Print()
+
+	for _, in := range []string{
+		`{"id":"6ba7b810-9dad-11d1-80b4"}`,
+		`{"email":"Ann <ann@example.com>"}`,
+		`{"site":"/relative"}`,
+		`{"last":"256.0.0.1"}`,
+	} {
+		fmt.Println(json.Unmarshal([]byte(in), &u))
+	}
+
+	// Output:
+	// <nil>
+	// {"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","email":"Ann.Lee@example.com","site":"https://example.com/a?b=1","last":"2001:db8::1"}
+	// Ann.Lee example.com true
+	// "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	// invalid UUID: "6ba7b810-9dad-11d1-80b4"
+	// invalid email: "Ann <ann@example.com>"
+	// invalid URL: "/relative"
+	// invalid IP: "256.0.0.1"
+}
+
+func ExampleIP_zero() {
+	var v struct {
+		IP  json.IP  `json:"ip"`
+		URL json.URL `json:"url"`
+	}
+	fmt.Println(json.This{v})
+	fmt.Println(json.Unmarshal([]byte(`{"ip":"","url":null}`), &v), v.IP.IsValid())
+	// Output:
+	// {"ip":"","url":""}
+	// <nil> false
+}
@@ -0,0 +1,413 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// GeoPosition is a GeoJSON position: longitude and latitude (in that
+// order) in decimal degrees (WGS 84) with an optional altitude.
+type GeoPosition []float64
+
+// BBox is a GeoJSON bounding box: west, south, east, and north (the
+// smallest and largest longitude and latitude). Bounds only ever
+// returns two dimensions and never crosses the antimeridian.
+type BBox []float64
+
+// Contains returns true if the position is within (or on the edge of)
+// the box.
+func (b BBox) Contains(p GeoPosition) bool {
+	if len(b) < 4 || len(p) < 2 {
+		return false
+	}
+	return b[0] <= p[0] && p[0] <= b[2] && b[1] <= p[1] && p[1] <= b[3]
+}
+
+// Intersects returns true if the boxes overlap (or touch).
+func (b BBox) Intersects(o BBox) bool {
+	if len(b) < 4 || len(o) < 4 {
+		return false
+	}
+	return b[0] <= o[2] && o[0] <= b[2] && b[1] <= o[3] && o[1] <= b[3]
+}
+
+// extend returns the box grown to contain the position (or the box of
+// the position alone if nil).
+func (b BBox) extend(p GeoPosition) BBox {
+	if b == nil {
+		return BBox{p[0], p[1], p[0], p[1]}
+	}
+	b[0], b[1] = math.Min(b[0], p[0]), math.Min(b[1], p[1])
+	b[2], b[3] = math.Max(b[2], p[0]), math.Max(b[3], p[1])
+	return b
+}
+
+// The types of GeoJSON geometry (see Geometry).
+const (
+	GeoPoint           = `Point`
+	GeoMultiPoint      = `MultiPoint`
+	GeoLineString      = `LineString`
+	GeoMultiLineString = `MultiLineString`
+	GeoPolygon         = `Polygon`
+	GeoMultiPolygon    = `MultiPolygon`
+	GeoCollection      = `GeometryCollection`
+)
+
+// Geometry is any GeoJSON geometry (RFC 7946) with the coordinates in
+// the field for its Type (the others are ignored). Geometry is
+// validated (see Validate) when unmarshaled and when marshaled so that
+// mapping data pulled through Fetch (or sent with it) is known to be
+// usable. The winding order of polygon rings (counterclockwise exterior
+// in RFC 7946) is not enforced since the RFC requires parsers to
+// accept either.
+//
+//     var fc json.FeatureCollection
+//     err := json.Fetch(&json.Request{URL: quakes, Into: &fc})
+//     area := json.BBox{-125, 32, -114, 42}
+//     for _, f := range fc.Features {
+//       if area.Intersects(f.Bounds()) {
+//         // ...
+//       }
+//     }
+//
+type Geometry struct {
+	Type            string
+	Point           GeoPosition
+	MultiPoint      []GeoPosition
+	LineString      []GeoPosition
+	MultiLineString [][]GeoPosition
+	Polygon         [][]GeoPosition // exterior ring then any holes
+	MultiPolygon    [][][]GeoPosition
+	Geometries      []*Geometry // GeometryCollection
+	BBox            BBox        // optional, as given (see Bounds)
+}
+
+// NewPoint returns a Point Geometry.
+func NewPoint(lon, lat float64) *Geometry {
+	return &Geometry{Type: GeoPoint, Point: GeoPosition{lon, lat}}
+}
+
+// NewLineString returns a LineString Geometry.
+func NewLineString(line ...GeoPosition) *Geometry {
+	return &Geometry{Type: GeoLineString, LineString: line}
+}
+
+// NewPolygon returns a Polygon Geometry with the exterior ring first
+// followed by any holes (each closed with its first position last).
+func NewPolygon(rings ...[]GeoPosition) *Geometry {
+	return &Geometry{Type: GeoPolygon, Polygon: rings}
+}
+
+// Validate returns an error if the Geometry does not have the
+// coordinates required for its Type: positions of at least longitude
+// and latitude (within range), lines of at least two positions, and
+// closed rings of at least four.
+func (g Geometry) Validate() error {
+	err := func() error {
+		switch g.Type {
+		case GeoPoint:
+			return validPosition(g.Point)
+		case GeoMultiPoint:
+			for _, p := range g.MultiPoint {
+				if err := validPosition(p); err != nil {
+					return err
+				}
+			}
+		case GeoLineString:
+			return validLine(g.LineString)
+		case GeoMultiLineString:
+			for _, l := range g.MultiLineString {
+				if err := validLine(l); err != nil {
+					return err
+				}
+			}
+		case GeoPolygon:
+			return validPolygon(g.Polygon)
+		case GeoMultiPolygon:
+			for _, p := range g.MultiPolygon {
+				if err := validPolygon(p); err != nil {
+					return err
+				}
+			}
+		case GeoCollection:
+			for _, c := range g.Geometries {
+				if c == nil {
+					return fmt.Errorf(`null geometry`)
+				}
+				if err := c.Validate(); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf(`unknown type %q`, g.Type)
+		}
+		return nil
+	}()
+	if err != nil {
+		return fmt.Errorf(`invalid GeoJSON %s: %w`, g.Type, err)
+	}
+	return nil
+}
+
+func validPosition(p GeoPosition) error {
+	if len(p) < 2 {
+		return fmt.Errorf(`position %v without longitude and latitude`, p)
+	}
+	for _, n := range p {
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return fmt.Errorf(`position %v not a number`, p)
+		}
+	}
+	if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+		return fmt.Errorf(`position %v out of range`, p)
+	}
+	return nil
+}
+
+func validLine(l []GeoPosition) error {
+	if len(l) < 2 {
+		return fmt.Errorf(`line of %d positions (at least 2)`, len(l))
+	}
+	for _, p := range l {
+		if err := validPosition(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validPolygon(rings [][]GeoPosition) error {
+	for _, r := range rings {
+		if len(r) < 4 {
+			return fmt.Errorf(`ring of %d positions (at least 4)`, len(r))
+		}
+		if err := validLine(r); err != nil {
+			return err
+		}
+		first, last := r[0], r[len(r)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf(`ring not closed (first and last positions differ)`)
+		}
+	}
+	return nil
+}
+
+// each calls the function with every position of the Geometry.
+func (g Geometry) each(fn func(p GeoPosition)) {
+	lines := func(ls ...[]GeoPosition) {
+		for _, l := range ls {
+			for _, p := range l {
+				fn(p)
+			}
+		}
+	}
+	switch g.Type {
+	case GeoPoint:
+		lines([]GeoPosition{g.Point})
+	case GeoMultiPoint:
+		lines(g.MultiPoint)
+	case GeoLineString:
+		lines(g.LineString)
+	case GeoMultiLineString:
+		lines(g.MultiLineString...)
+	case GeoPolygon:
+		lines(g.Polygon...)
+	case GeoMultiPolygon:
+		for _, p := range g.MultiPolygon {
+			lines(p...)
+		}
+	case GeoCollection:
+		for _, c := range g.Geometries {
+			if c != nil {
+				c.each(fn)
+			}
+		}
+	}
+}
+
+// Bounds returns the smallest BBox containing every position of the
+// Geometry (nil if it has none). The BBox field is ignored.
+func (g Geometry) Bounds() BBox {
+	var b BBox
+	g.each(func(p GeoPosition) {
+		if len(p) >= 2 {
+			b = b.extend(p)
+		}
+	})
+	return b
+}
+
+// geometryJSON is the JSON form of a Geometry.
+type geometryJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []*Geometry     `json:"geometries,omitempty"`
+	BBox        BBox            `json:"bbox,omitempty"`
+}
+
+// coordinates returns a pointer to the field of the coordinates for the
+// Type (nil for a GeometryCollection or unknown Type).
+func (g *Geometry) coordinates() any {
+	switch g.Type {
+	case GeoPoint:
+		return &g.Point
+	case GeoMultiPoint:
+		return &g.MultiPoint
+	case GeoLineString:
+		return &g.LineString
+	case GeoMultiLineString:
+		return &g.MultiLineString
+	case GeoPolygon:
+		return &g.Polygon
+	case GeoMultiPolygon:
+		return &g.MultiPolygon
+	}
+	return nil
+}
+
+// MarshalJSON fulfills the json.Marshaler interface per RFC 7946
+// returning any Validate error.
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	if g.Type == GeoCollection {
+		geoms := g.Geometries
+		if geoms == nil {
+			geoms = []*Geometry{}
+		}
+		return Marshal(struct {
+			Type       string      `json:"type"`
+			Geometries []*Geometry `json:"geometries"`
+			BBox       BBox        `json:"bbox,omitempty"`
+		}{g.Type, geoms, g.BBox})
+	}
+	buf, err := Marshal(g.coordinates())
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(geometryJSON{Type: g.Type, Coordinates: buf, BBox: g.BBox})
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface per RFC 7946
+// returning any Validate error.
+func (g *Geometry) UnmarshalJSON(buf []byte) error {
+	var in geometryJSON
+	if err := Unmarshal(buf, &in); err != nil {
+		return err
+	}
+	v := Geometry{Type: in.Type, Geometries: in.Geometries, BBox: in.BBox}
+	if c := v.coordinates(); c != nil {
+		if in.Coordinates == nil {
+			return fmt.Errorf(`invalid GeoJSON %s: no coordinates`, in.Type)
+		}
+		if err := Unmarshal(in.Coordinates, c); err != nil {
+			return err
+		}
+	}
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	*g = v
+	return nil
+}
+
+// Feature is a GeoJSON Feature: a Geometry (nil for unlocated) with
+// any properties and an optional ID (string or number).
+type Feature struct {
+	ID         any
+	Geometry   *Geometry
+	Properties map[string]any
+	BBox       BBox // optional, as given (see Bounds)
+}
+
+// featureJSON is the JSON form of a Feature.
+type featureJSON struct {
+	Type       string         `json:"type"`
+	ID         any            `json:"id,omitempty"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+	BBox       BBox           `json:"bbox,omitempty"`
+}
+
+// Bounds returns that of the Geometry (nil if none).
+func (f Feature) Bounds() BBox {
+	if f.Geometry == nil {
+		return nil
+	}
+	return f.Geometry.Bounds()
+}
+
+// MarshalJSON fulfills the json.Marshaler interface per RFC 7946 (with
+// geometry and properties always present even if null).
+func (f Feature) MarshalJSON() ([]byte, error) {
+	return Marshal(featureJSON{`Feature`, f.ID, f.Geometry, f.Properties, f.BBox})
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface per RFC 7946
+// validating the Geometry.
+func (f *Feature) UnmarshalJSON(buf []byte) error {
+	var in featureJSON
+	if err := Unmarshal(buf, &in); err != nil {
+		return err
+	}
+	if in.Type != `Feature` {
+		return fmt.Errorf(`invalid GeoJSON Feature: type %q`, in.Type)
+	}
+	*f = Feature{in.ID, in.Geometry, in.Properties, in.BBox}
+	return nil
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection (the usual top-level
+// value of mapping APIs).
+type FeatureCollection struct {
+	Features []*Feature
+	BBox     BBox // optional, as given (see Bounds)
+}
+
+// featureCollectionJSON is the JSON form of a FeatureCollection.
+type featureCollectionJSON struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+	BBox     BBox       `json:"bbox,omitempty"`
+}
+
+// Bounds returns the smallest BBox containing every Feature (nil if
+// none have a Geometry).
+func (fc FeatureCollection) Bounds() BBox {
+	var b BBox
+	for _, f := range fc.Features {
+		if f == nil {
+			continue
+		}
+		if fb := f.Bounds(); fb != nil {
+			b = b.extend(GeoPosition{fb[0], fb[1]}).extend(GeoPosition{fb[2], fb[3]})
+		}
+	}
+	return b
+}
+
+// MarshalJSON fulfills the json.Marshaler interface per RFC 7946 (with
+// features always an array).
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	features := fc.Features
+	if features == nil {
+		features = []*Feature{}
+	}
+	return Marshal(featureCollectionJSON{`FeatureCollection`, features, fc.BBox})
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface per RFC 7946
+// validating every Feature.
+func (fc *FeatureCollection) UnmarshalJSON(buf []byte) error {
+	var in featureCollectionJSON
+	if err := Unmarshal(buf, &in); err != nil {
+		return err
+	}
+	if in.Type != `FeatureCollection` {
+		return fmt.Errorf(`invalid GeoJSON FeatureCollection: type %q`, in.Type)
+	}
+	*fc = FeatureCollection{in.Features, in.BBox}
+	return nil
+}
@@ -0,0 +1,69 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleFeatureCollection() {
+	var fc json.FeatureCollection
+	err := json.Unmarshal([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [-122.4, 37.8]},
+				"properties": {"mag": 2.5}
+			},
+			{
+				"type": "Feature",
+				"geometry": {
+					"type": "Polygon",
+					"coordinates": [[[-120,35],[-118,35],[-118,36],[-120,35]]]
+				},
+				"properties": null
+			}
+		]
+	}`), &fc)
+	fmt.Println(err)
+	fmt.Println(fc.Bounds())
+	fmt.Println(fc.Features[0].Bounds().Intersects(json.BBox{-123, 37, -122, 38}))
+
+	fc.Features = append(fc.Features, &json.Feature{
+		ID:       7,
+		Geometry: json.NewLineString(json.GeoPosition{0, 0}, json.GeoPosition{1, 1, 10}),
+	})
+	fmt.Println(string(json.MustMarshal(fc.Features[2])))
+
+	// Output:
+	// <nil>
+	// [-122.4 35 -118 37.8]
+	// true
+	// {"type":"Feature","id":7,"geometry":{"type":"LineString","coordinates":[[0,0],[1,1,10]]},"properties":null}
+}
+
+func ExampleGeometry_Validate() {
+	for _, in := range []string{
+		`{"type":"Point","coordinates":[200,10]}`,
+		`{"type":"LineString","coordinates":[[0,0]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1]}]}`,
+		`{"type":"Circle","coordinates":[0,0]}`,
+	} {
+		var g json.Geometry
+		fmt.Println(json.Unmarshal([]byte(in), &g))
+	}
+
+	_, err := json.Marshal(json.NewPolygon([]json.GeoPosition{{0, 0}, {1, 0}, {0, 0}}))
+	fmt.Println(err)
+
+	// Output:
+	// invalid GeoJSON Point: position [200 10] out of range
+	// invalid GeoJSON LineString: line of 1 positions (at least 2)
+	// invalid GeoJSON Polygon: ring not closed (first and last positions differ)
+	// invalid GeoJSON Point: position [1] without longitude and latitude
+	// invalid GeoJSON Circle: unknown type "Circle"
+	// json: error calling MarshalJSON for type *json.Geometry: invalid GeoJSON Polygon: ring of 3 positions (at least 4)
+}
@@ -3,8 +3,10 @@ module github.com/rwxrob/json
 go 1.18
 
 require (
-	github.com/rwxrob/to v0.7.0
-	github.com/rwxrob/yq v0.3.0
+	github.com/mikefarah/yq/v4 v4.25.1
+	github.com/rwxrob/fn v0.3.3
+	gopkg.in/op/go-logging.v1 v1.0.0-20160211212156-b2cb9fa56473
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
@@ -16,24 +18,10 @@ require (
 	github.com/magiconair/properties v1.8.6 // indirect
 	github.com/mattn/go-colorable v0.1.12 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
-	github.com/mikefarah/yq/v4 v4.25.1 // indirect
-	github.com/rwxrob/bonzai v0.14.1 // indirect
-	github.com/rwxrob/compcmd v0.3.0 // indirect
-	github.com/rwxrob/compfile v0.1.12 // indirect
-	github.com/rwxrob/fn v0.3.3 // indirect
-	github.com/rwxrob/fs v0.5.2 // indirect
-	github.com/rwxrob/help v0.5.0 // indirect
-	github.com/rwxrob/scan v0.9.0 // indirect
-	github.com/rwxrob/structs v0.6.0 // indirect
-	github.com/rwxrob/term v0.2.7 // indirect
 	github.com/timtadh/data-structures v0.5.3 // indirect
 	github.com/timtadh/lexmachine v0.2.2 // indirect
-	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e // indirect
 	golang.org/x/net v0.0.0-20220524220425-1d687d428aca // indirect
 	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
-	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467 // indirect
 	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/xerrors v0.0.0-20220517211312-f3a8303e98df // indirect
-	gopkg.in/op/go-logging.v1 v1.0.0-20160211212156-b2cb9fa56473 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
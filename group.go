@@ -0,0 +1,284 @@
+package json
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RequestGroup runs a set of named requests (see Fetch) with as much
+// parallelism as their dependencies allow, making it a tiny workflow
+// engine for API scripting. A request depends on another when its URL,
+// Query, Header, or Body values contain a {{placeholder}} with a Path
+// (see ParsePath) starting with the name of the other, which is
+// replaced with the value from its response before the request is
+// sent (strings as is, everything else as JSON). Secret placeholders
+// are left alone (see Secret):
+//
+//     g := new(json.RequestGroup)
+//     g.Add(`user`, &json.Request{URL: api + `/users/me`})
+//     g.Add(`team`, &json.Request{URL: api + `/teams/{{.user.team_id}}`})
+//     g.Add(`posts`, &json.Request{URL: api + `/users/{{.user.id}}/posts`})
+//     results, err := g.Run()
+//
+// Here team and posts are both sent (in parallel) as soon as user is
+// done. Requests that do not depend on each other are all sent at once.
+type RequestGroup struct {
+	names []string
+	reqs  map[string]*Request
+}
+
+// Add adds the named Request to the group. The Request is not changed
+// (placeholders are filled in on a copy) and its Into (if any) receives
+// the response as usual.
+func (g *RequestGroup) Add(name string, it *Request) {
+	if g.reqs == nil {
+		g.reqs = map[string]*Request{}
+	}
+	if _, has := g.reqs[name]; !has {
+		g.names = append(g.names, name)
+	}
+	g.reqs[name] = it
+}
+
+// Run sends every request of the group (each as soon as the requests it
+// depends on are done) and returns the responses (in generic form)
+// keyed by name along with the first error (in the order added). The
+// responses of requests that succeeded are always returned. Requests
+// depending on any that failed are never sent. Run fails before sending
+// anything if the dependencies are unknown, circular, or cannot be
+// parsed.
+func (g *RequestGroup) Run() (map[string]any, error) {
+	deps := map[string][]string{}
+	for _, name := range g.names {
+		d, err := g.reqs[name].placeholders()
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, name, err)
+		}
+		for _, dep := range d {
+			if _, has := g.reqs[dep]; !has {
+				return nil, fmt.Errorf(`%s: unknown request %q`, name, dep)
+			}
+		}
+		deps[name] = d
+	}
+	if cycle := groupCycle(g.names, deps); cycle != nil {
+		return nil, fmt.Errorf(`circular dependencies: %s`, strings.Join(cycle, ` -> `))
+	}
+
+	var mu sync.Mutex
+	results := map[string]any{}
+	errs := map[string]error{}
+	done := map[string]chan struct{}{}
+	for _, name := range g.names {
+		done[name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range g.names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer close(done[name])
+			for _, dep := range deps[name] {
+				<-done[dep]
+			}
+			mu.Lock()
+			var err error
+			for _, dep := range deps[name] {
+				if errs[dep] != nil {
+					err = fmt.Errorf(`depends on failed request %q`, dep)
+					break
+				}
+			}
+			var it *Request
+			if err == nil {
+				it, err = g.reqs[name].fill(results)
+			}
+			mu.Unlock()
+
+			var v any
+			if err == nil {
+				err = g.fetch(it, &v)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[name] = fmt.Errorf(`%s: %w`, name, err)
+				return
+			}
+			results[name] = v
+		}(name)
+	}
+	wg.Wait()
+
+	for _, name := range g.names {
+		if errs[name] != nil {
+			return results, errs[name]
+		}
+	}
+	return results, nil
+}
+
+// fetch fetches the Request into the generic value also unmarshaling
+// it into the original Into (if any).
+func (g *RequestGroup) fetch(it *Request, v *any) error {
+	into := it.Into
+	it.Into = v
+	if err := Fetch(it); err != nil {
+		return err
+	}
+	if into == nil {
+		return nil
+	}
+	buf, err := Marshal(*v)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(buf, into)
+}
+
+// placeholderExp matches a {{placeholder}} (see RequestGroup).
+var placeholderExp = regexp.MustCompile(`{{\s*(.*?)\s*}}`)
+
+// templated returns every string of the Request that may contain
+// placeholders.
+func (it *Request) templated() []string {
+	s := []string{it.URL}
+	for _, vals := range []url.Values{it.Query, it.Body} {
+		for _, vs := range vals {
+			s = append(s, vs...)
+		}
+	}
+	for _, v := range it.Header {
+		s = append(s, v)
+	}
+	return s
+}
+
+// placeholders returns the names of the requests (sorted and unique)
+// referred to by the placeholders of the Request.
+func (it *Request) placeholders() ([]string, error) {
+	names := map[string]bool{}
+	for _, s := range it.templated() {
+		for _, m := range placeholderExp.FindAllStringSubmatch(s, -1) {
+			if isSecret(m[0]) {
+				continue
+			}
+			p, err := ParsePath(m[1])
+			if err != nil {
+				return nil, err
+			}
+			if len(p) == 0 || p[0].Index != nil || p[0].Each {
+				return nil, fmt.Errorf(`placeholder must start with a request name: %s`, m[0])
+			}
+			names[p[0].Key] = true
+		}
+	}
+	return sortedKeys(names), nil
+}
+
+// fill returns a copy of the Request with every placeholder replaced
+// by its value from the results. Secret placeholders are resolved in
+// the same pass (and not again when sent, see filled) so that values
+// from responses can never smuggle one in to be resolved and sent back.
+func (it *Request) fill(results map[string]any) (*Request, error) {
+	var err error
+	expand := func(s string) string {
+		return placeholderExp.ReplaceAllStringFunc(s, func(m string) string {
+			if isSecret(m) {
+				v, serr := resolveSecrets(m)
+				if serr != nil {
+					err = serr
+				}
+				return v
+			}
+			p, perr := ParsePath(placeholderExp.FindStringSubmatch(m)[1])
+			if perr != nil {
+				err = perr
+				return m
+			}
+			vals, serr := p.Select(results)
+			switch {
+			case serr != nil:
+				err = serr
+				return m
+			case len(vals) != 1:
+				err = fmt.Errorf(`%s selects %d values (must be exactly one)`, m, len(vals))
+				return m
+			}
+			if str, is := vals[0].(string); is {
+				return str
+			}
+			return This{vals[0]}.String()
+		})
+	}
+	values := func(in url.Values) url.Values {
+		if in == nil {
+			return nil
+		}
+		out := url.Values{}
+		for k, vs := range in {
+			for _, v := range vs {
+				out.Add(k, expand(v))
+			}
+		}
+		return out
+	}
+
+	c := *it
+	c.filled = true
+	c.URL = expand(it.URL)
+	c.Query = values(it.Query)
+	c.Body = values(it.Body)
+	if it.Header != nil {
+		c.Header = map[string]string{}
+		for k, v := range it.Header {
+			c.Header[k] = expand(v)
+		}
+	}
+	return &c, err
+}
+
+// groupCycle returns the names forming the first circular dependency
+// found (nil if none).
+func groupCycle(names []string, deps map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(n string) []string
+	visit = func(n string) []string {
+		switch state[n] {
+		case visited:
+			return nil
+		case visiting:
+			for i, s := range stack {
+				if s == n {
+					return append(append([]string{}, stack[i:]...), n)
+				}
+			}
+		}
+		state[n] = visiting
+		stack = append(stack, n)
+		for _, d := range deps[n] {
+			if c := visit(d); c != nil {
+				return c
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = visited
+		return nil
+	}
+	for _, n := range names {
+		if c := visit(n); c != nil {
+			return c
+		}
+	}
+	return nil
+}
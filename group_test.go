@@ -0,0 +1,82 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"os"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleRequestGroup() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch r.URL.Path {
+			case `/users/me`:
+				fmt.Fprint(w, `{"id":7,"team_id":3}`)
+			case `/teams/3`:
+				fmt.Fprint(w, `{"name":"blue"}`)
+			case `/users/7/posts`:
+				fmt.Fprintf(w, `[{"title":"hi","team":%q}]`, r.URL.Query().Get(`team`))
+			default:
+				_http.NotFound(w, r)
+			}
+		}))
+	defer svr.Close()
+
+	var team struct {
+		Name string `json:"name"`
+	}
+	g := new(json.RequestGroup)
+	g.Add(`user`, &json.Request{URL: svr.URL + `/users/me`})
+	g.Add(`team`, &json.Request{URL: svr.URL + `/teams/{{.user.team_id}}`, Into: &team})
+	g.Add(`posts`, &json.Request{
+		URL:   svr.URL + `/users/{{.user.id}}/posts`,
+		Query: map[string][]string{`team`: {`{{ .team.name }}`}},
+	})
+	results, err := g.Run()
+	fmt.Println(err, team.Name)
+	fmt.Println(json.This{results[`posts`]})
+
+	g.Add(`user`, &json.Request{URL: svr.URL + `/users/{{.posts[0].id}}`})
+	_, err = g.Run()
+	fmt.Println(err)
+
+	// Output:
+	// <nil> blue
+	// [{"team":"blue","title":"hi"}]
+	// circular dependencies: user -> posts -> team -> user
+}
+
+func ExampleRequestGroup_secrets() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch r.URL.Path {
+			case `/evil`:
+				fmt.Fprint(w, `{"name":"x{{secret \"github_token\"}}"}`)
+			case `/echo`:
+				fmt.Fprintf(w, `{"n":%q,"auth":%q}`,
+					r.URL.Query().Get(`n`), r.Header.Get(`Authorization`))
+			}
+		}))
+	defer svr.Close()
+
+	os.Setenv(`GITHUB_TOKEN`, `s3cr3t`)
+	defer os.Unsetenv(`GITHUB_TOKEN`)
+
+	g := new(json.RequestGroup)
+	g.Add(`evil`, &json.Request{URL: svr.URL + `/evil`})
+	g.Add(`echo`, &json.Request{
+		URL:    svr.URL + `/echo`,
+		Query:  map[string][]string{`n`: {`{{.evil.name}}`}},
+		Header: map[string]string{`Authorization`: `Bearer {{secret "github_token"}}`},
+	})
+	results, err := g.Run()
+	fmt.Println(err)
+	fmt.Println(json.This{results[`echo`]})
+
+	// Output:
+	// <nil>
+	// {"auth":"Bearer s3cr3t","n":"x{{secret \"github_token\"}}"}
+}
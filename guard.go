@@ -0,0 +1,92 @@
+package json
+
+import "sync"
+
+// Guard is the thread-safe variant of This for values shared across
+// goroutines (for logging while others mutate them, for example). Every
+// method of AsJSON holds a read lock while marshaling so output is
+// never torn by a concurrent Update. (Document is always safe for
+// concurrent use.) The zero value holds the zero value of T.
+//
+//     cfg := json.NewGuard(Config{Port: 8080})
+//     go cfg.Update(func(c *Config) { c.Port = 9090 })
+//     cfg.Log()
+//
+type Guard[T any] struct {
+	mu sync.RWMutex
+	v  T
+}
+
+// NewGuard returns a new Guard for the value.
+func NewGuard[T any](v T) *Guard[T] { return &Guard[T]{v: v} }
+
+var _ AsJSON = (*Guard[any])(nil)
+
+// Get returns a (shallow) copy of the value. Use View instead when the
+// value contains maps, slices, or pointers that may be updated.
+func (g *Guard[T]) Get() T {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.v
+}
+
+// Set replaces the value.
+func (g *Guard[T]) Set(v T) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.v = v
+}
+
+// Update calls the function with a pointer to the value while holding
+// the write lock. The pointer must not be kept.
+func (g *Guard[T]) Update(fn func(v *T)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fn(&g.v)
+}
+
+// View calls the function with the value while holding the read lock.
+// The value (or anything it references) must not be changed or kept.
+func (g *Guard[T]) View(fn func(v T)) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	fn(g.v)
+}
+
+// MarshalJSON implements AsJSON rendering only the guarded value.
+func (g *Guard[T]) MarshalJSON() ([]byte, error) { return g.JSON() }
+
+// UnmarshalJSON implements AsJSON replacing the value.
+func (g *Guard[T]) UnmarshalJSON(buf []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Unmarshal(buf, &g.v)
+}
+
+// JSON implements AsJSON (see This.JSON).
+func (g *Guard[T]) JSON() ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return This{g.v}.JSON()
+}
+
+// String implements AsJSON (see This.String).
+func (g *Guard[T]) String() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return This{g.v}.String()
+}
+
+// Print implements AsJSON (see This.Print).
+func (g *Guard[T]) Print() {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	This{g.v}.Print()
+}
+
+// Log implements AsJSON (see This.Log).
+func (g *Guard[T]) Log() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return This{g.v}.Log()
+}
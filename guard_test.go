@@ -0,0 +1,33 @@
+package json_test
+
+import (
+	"sync"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleGuard() {
+	type Stats struct {
+		Hits  int            `json:"hits"`
+		Paths map[string]int `json:"paths"`
+	}
+	stats := json.NewGuard(Stats{Paths: map[string]int{}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats.Update(func(s *Stats) {
+				s.Hits++
+				s.Paths["/"]++
+			})
+			_ = stats.String() // safe while others update
+		}()
+	}
+	wg.Wait()
+	stats.Print()
+
+	// Output:
+	// {"hits":100,"paths":{"/":100}}
+}
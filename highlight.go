@@ -0,0 +1,151 @@
+package json
+
+// TokenKind is the kind of a Span (see Highlight).
+type TokenKind int
+
+const (
+	TokenPunct   TokenKind = iota // { } [ ] : ,
+	TokenKey                      // object key (with quotes)
+	TokenString                   // string value (with quotes)
+	TokenNumber                   // number
+	TokenLiteral                  // true, false, or null
+	TokenInvalid                  // anything else (not valid JSON)
+)
+
+// String fulfills the fmt.Stringer interface.
+func (k TokenKind) String() string {
+	switch k {
+	case TokenPunct:
+		return `punct`
+	case TokenKey:
+		return `key`
+	case TokenString:
+		return `string`
+	case TokenNumber:
+		return `number`
+	case TokenLiteral:
+		return `literal`
+	}
+	return `invalid`
+}
+
+// Span is a single token of JSON from the Start byte offset up to (but
+// not including) End (see Highlight).
+type Span struct {
+	Kind  TokenKind `json:"kind"`
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+}
+
+// Highlight returns the Span of every token of the JSON (in order,
+// skipping whitespace) so that TUIs, web UIs, and editors can render
+// colored JSON with their own themes:
+//
+//     for _, s := range json.Highlight(buf) {
+//       out.WriteString(style[s.Kind].Render(string(buf[s.Start:s.End])))
+//     }
+//
+// Highlight never fails. Partial or invalid JSON (as typed in an
+// editor, for example) is tokenized as far as possible marking
+// anything unrecognized as TokenInvalid and treating an unterminated
+// string as running to the end of its line.
+func Highlight(buf []byte) []Span {
+	var out []Span
+	add := func(k TokenKind, start, end int) {
+		out = append(out, Span{k, start, end})
+	}
+	for i := 0; i < len(buf); {
+		c := buf[i]
+		switch {
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+
+		case c == '{' || c == '}' || c == '[' || c == ']' || c == ':' || c == ',':
+			add(TokenPunct, i, i+1)
+			i++
+
+		case c == '"':
+			n := i + 1
+			for n < len(buf) && buf[n] != '"' && buf[n] != '\n' {
+				if buf[n] == '\\' {
+					n++
+				}
+				n++
+			}
+			if n < len(buf) && buf[n] == '"' {
+				n++
+			}
+			if n > len(buf) {
+				n = len(buf)
+			}
+			kind := TokenString
+			if next := skipSpace(buf, n); next < len(buf) && buf[next] == ':' {
+				kind = TokenKey
+			}
+			add(kind, i, n)
+			i = n
+
+		case c == '-' || '0' <= c && c <= '9':
+			n := i
+			for n < len(buf) && isNumberByte(buf[n]) {
+				n++
+			}
+			kind := TokenNumber
+			if !validNumber(string(buf[i:n])) {
+				kind = TokenInvalid
+			}
+			add(kind, i, n)
+			i = n
+
+		default:
+			n := i
+			for n < len(buf) && 'a' <= buf[n] && buf[n] <= 'z' {
+				n++
+			}
+			switch string(buf[i:n]) {
+			case `true`, `false`, `null`:
+				add(TokenLiteral, i, n)
+				i = n
+				continue
+			}
+			if n == i {
+				n++
+			}
+			for n < len(buf) && !isDelimByte(buf[n]) {
+				n++
+			}
+			add(TokenInvalid, i, n)
+			i = n
+		}
+	}
+	return out
+}
+
+// skipSpace returns the offset of the first non-whitespace byte at or
+// after i.
+func skipSpace(buf []byte, i int) int {
+	for i < len(buf) {
+		switch buf[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isNumberByte(c byte) bool {
+	return '0' <= c && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// isDelimByte returns true for whitespace and punctuation that end an
+// invalid token.
+func isDelimByte(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '{', '}', '[', ']', ':', ',', '"':
+		return true
+	}
+	return false
+}
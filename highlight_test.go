@@ -0,0 +1,33 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleHighlight() {
+	buf := []byte(`{"id": 1, "tags": ["a", true], "x": nope}`)
+	for _, s := range json.Highlight(buf) {
+		fmt.Printf("%-7v %s\n", s.Kind, buf[s.Start:s.End])
+	}
+
+	// Output:
+	// punct   {
+	// key     "id"
+	// punct   :
+	// number  1
+	// punct   ,
+	// key     "tags"
+	// punct   :
+	// punct   [
+	// string  "a"
+	// punct   ,
+	// literal true
+	// punct   ]
+	// punct   ,
+	// key     "x"
+	// punct   :
+	// invalid nope
+	// punct   }
+}
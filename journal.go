@@ -0,0 +1,340 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JournalMax is the maximum number of changes (calls to Set, Delete, or
+// Patch) journaled by every Document for Undo and History. The oldest
+// are dropped first. Zero disables the journal.
+var JournalMax = 1000
+
+// PatchOp is a single JSON Patch (RFC 6902) operation: add, remove,
+// replace, move, copy, or test. Path and From are JSON Pointers (RFC
+// 6901) such as /items/0/name (~1 for slash and ~0 for tilde within
+// keys).
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON fulfills the encoding/json.Marshaler interface always
+// including the value (even null) for add, replace, and test.
+func (o PatchOp) MarshalJSON() ([]byte, error) {
+	switch o.Op {
+	case `add`, `replace`, `test`:
+		return Marshal(struct {
+			Op    string `json:"op"`
+			Path  string `json:"path"`
+			Value any    `json:"value"`
+		}{o.Op, o.Path, o.Value})
+	}
+	type plain PatchOp
+	return Marshal(plain(o))
+}
+
+type journalEntry struct {
+	do   []PatchOp
+	undo []PatchOp
+}
+
+// Patch applies every JSON Patch operation in order notifying
+// subscribers of the changes (moves are a remove followed by an add).
+// Either every operation is applied or none are (a failing operation
+// or test rolls back those before it) and an error identifies the
+// operation that failed.
+func (d *Document) Patch(ops []PatchOp) error {
+	d.mu.Lock()
+	changes, undo, err := d.applyAll(ops)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	do := make([]PatchOp, len(ops))
+	copy(do, ops)
+	d.record(journalEntry{do, undo})
+	d.notify(changes...)
+	return nil
+}
+
+// Undo reverts the last change (from Set, Delete, or Patch) still in
+// the journal notifying subscribers (see JournalMax).
+func (d *Document) Undo() error {
+	d.mu.Lock()
+	if len(d.done) == 0 {
+		d.mu.Unlock()
+		return fmt.Errorf(`nothing to undo`)
+	}
+	e := d.done[len(d.done)-1]
+	changes, _, err := d.applyAll(e.undo)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.done = d.done[:len(d.done)-1]
+	d.undone = append(d.undone, e)
+	d.notify(changes...)
+	return nil
+}
+
+// Redo reapplies the last change reverted by Undo (if no other changes
+// have been made since) notifying subscribers.
+func (d *Document) Redo() error {
+	d.mu.Lock()
+	if len(d.undone) == 0 {
+		d.mu.Unlock()
+		return fmt.Errorf(`nothing to redo`)
+	}
+	e := d.undone[len(d.undone)-1]
+	changes, _, err := d.applyAll(e.do)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.undone = d.undone[:len(d.undone)-1]
+	d.done = append(d.done, e)
+	d.notify(changes...)
+	return nil
+}
+
+// History returns the exact JSON Patch operations of every change in
+// the journal (oldest first, excluding those undone) which, applied to
+// the document as it was before them, produce the current document.
+// The values must not be modified.
+func (d *Document) History() []PatchOp {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ops []PatchOp
+	for _, e := range d.done {
+		ops = append(ops, e.do...)
+	}
+	return ops
+}
+
+// record adds the entry to the journal (copying the values of the
+// operations since they may still be in the document) and clears those
+// that were undone.
+func (d *Document) record(e journalEntry) {
+	if JournalMax <= 0 {
+		d.done, d.undone = nil, nil
+		return
+	}
+	for i, op := range e.do {
+		if op.Value != nil {
+			e.do[i].Value, _ = generic(op.Value)
+		}
+	}
+	d.done = append(d.done, e)
+	if n := len(d.done) - JournalMax; n > 0 {
+		d.done = append(d.done[:0:0], d.done[n:]...)
+	}
+	d.undone = nil
+}
+
+// applyAll applies the operations (with the Document locked) returning
+// the changes and the operations that revert them, or rolls them all
+// back on error.
+func (d *Document) applyAll(ops []PatchOp) ([]Change, []PatchOp, error) {
+	var changes []Change
+	var undo []PatchOp
+	for i, op := range ops {
+		cs, inv, err := d.apply(op)
+		if err != nil {
+			for _, u := range undo {
+				d.apply(u)
+			}
+			return nil, nil, fmt.Errorf(`patch operation %d (%s %s): %w`, i, op.Op, op.Path, err)
+		}
+		changes = append(changes, cs...)
+		undo = append(inv, undo...)
+	}
+	return changes, undo, nil
+}
+
+// apply applies a single operation (with the Document locked) returning
+// the changes and the operations that revert it. Values are always
+// copied into the document.
+func (d *Document) apply(op PatchOp) ([]Change, []PatchOp, error) {
+	p, err := resolvePointer(d.root, op.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch op.Op {
+
+	case `add`:
+		val, err := generic(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(p) == 0 {
+			old := d.root
+			d.root = val
+			return []Change{{`replace`, p, val}},
+				[]PatchOp{{Op: `replace`, Path: ``, Value: old}}, nil
+		}
+		parent, err := getIn(d.root, p[:len(p)-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		last := p[len(p)-1]
+		switch t := parent.(type) {
+		case []any:
+			i := *last.Index
+			if i < 0 || i > len(t) {
+				return nil, nil, fmt.Errorf(`index out of range: %v`, last)
+			}
+			a := make([]any, 0, len(t)+1)
+			a = append(append(append(a, t[:i]...), val), t[i:]...)
+			d.root, _, _ = setIn(d.root, p[:len(p)-1], a, &Path{})
+			return []Change{{`add`, p, val}},
+				[]PatchOp{{Op: `remove`, Path: pointerOf(p)}}, nil
+		case map[string]any:
+			old, has := t[last.Key]
+			t[last.Key] = val
+			if has {
+				return []Change{{`replace`, p, val}},
+					[]PatchOp{{Op: `replace`, Path: pointerOf(p), Value: old}}, nil
+			}
+			return []Change{{`add`, p, val}},
+				[]PatchOp{{Op: `remove`, Path: pointerOf(p)}}, nil
+		}
+		return nil, nil, fmt.Errorf(`cannot add to %T`, parent)
+
+	case `remove`:
+		old, err := getIn(d.root, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(p) == 0 {
+			d.root = nil
+			return []Change{{`remove`, p, nil}},
+				[]PatchOp{{Op: `add`, Path: ``, Value: old}}, nil
+		}
+		if d.root, err = deleteIn(d.root, p, &Path{}); err != nil {
+			return nil, nil, err
+		}
+		return []Change{{`remove`, p, nil}},
+			[]PatchOp{{Op: `add`, Path: pointerOf(p), Value: old}}, nil
+
+	case `replace`:
+		old, err := getIn(d.root, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		val, err := generic(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d.root, _, err = setIn(d.root, p, val, &Path{}); err != nil {
+			return nil, nil, err
+		}
+		return []Change{{`replace`, p, val}},
+			[]PatchOp{{Op: `replace`, Path: pointerOf(p), Value: old}}, nil
+
+	case `move`, `copy`:
+		from, err := resolvePointer(d.root, op.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		v, err := getIn(d.root, from)
+		if err != nil {
+			return nil, nil, err
+		}
+		var changes []Change
+		var undo []PatchOp
+		if op.Op == `move` {
+			if strings.HasPrefix(op.Path+`/`, op.From+`/`) && op.Path != op.From {
+				return nil, nil, fmt.Errorf(`cannot move into itself`)
+			}
+			changes, undo, err = d.apply(PatchOp{Op: `remove`, Path: op.From})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		cs, inv, err := d.apply(PatchOp{Op: `add`, Path: op.Path, Value: v})
+		if err != nil {
+			for _, u := range undo {
+				d.apply(u)
+			}
+			return nil, nil, err
+		}
+		return append(changes, cs...), append(inv, undo...), nil
+
+	case `test`:
+		v, err := getIn(d.root, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		want, err := generic(op.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !reflect.DeepEqual(v, want) {
+			return nil, nil, fmt.Errorf(`test failed`)
+		}
+		return nil, nil, nil
+
+	}
+	return nil, nil, fmt.Errorf(`unknown patch operation: %q`, op.Op)
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer(`~`, `~0`, `/`, `~1`)
+	pointerUnescaper = strings.NewReplacer(`~1`, `/`, `~0`, `~`)
+)
+
+// pointerOf returns the JSON Pointer for the normalized path.
+func pointerOf(p Path) string {
+	var b strings.Builder
+	for _, s := range p {
+		b.WriteByte('/')
+		if s.Index != nil {
+			b.WriteString(strconv.Itoa(*s.Index))
+			continue
+		}
+		b.WriteString(pointerEscaper.Replace(s.Key))
+	}
+	return b.String()
+}
+
+// resolvePointer returns the normalized path of the JSON Pointer within
+// cur (which determines whether a token is an array index or object
+// key). The final token may be - (past the end of an array).
+func resolvePointer(cur any, ptr string) (Path, error) {
+	p := Path{}
+	if ptr == `` {
+		return p, nil
+	}
+	if !strings.HasPrefix(ptr, `/`) {
+		return nil, fmt.Errorf(`invalid JSON pointer: %q`, ptr)
+	}
+	for _, tok := range strings.Split(ptr[1:], `/`) {
+		tok = pointerUnescaper.Replace(tok)
+		a, is := cur.([]any)
+		if !is {
+			p = append(p, PathStep{Key: tok})
+			m, _ := cur.(map[string]any)
+			cur = m[tok]
+			continue
+		}
+		i := len(a)
+		if tok != `-` {
+			n, err := strconv.Atoi(tok)
+			if err != nil || n < 0 || tok != strconv.Itoa(n) {
+				return nil, fmt.Errorf(`invalid array index in JSON pointer: %q`, tok)
+			}
+			i = n
+		}
+		p = append(p, PathStep{Index: &i})
+		cur = nil
+		if i < len(a) {
+			cur = a[i]
+		}
+	}
+	return p, nil
+}
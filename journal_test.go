@@ -0,0 +1,76 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDocument_Undo() {
+	doc, _ := json.NewDocument(map[string]any{"hosts": []string{"a", "b"}})
+
+	doc.Set(`.name`, "app")
+	doc.Set(`.owner.email`, "me@example.com")
+	doc.Delete(`.hosts[0]`)
+	err := doc.Patch([]json.PatchOp{
+		{Op: "add", Path: "/hosts/0", Value: "z"},
+		{Op: "move", From: "/name", Path: "/title"},
+		{Op: "test", Path: "/title", Value: "nope"},
+	})
+	fmt.Println(err)
+	doc.Patch([]json.PatchOp{
+		{Op: "add", Path: "/hosts/0", Value: "z"},
+		{Op: "move", From: "/name", Path: "/title"},
+	})
+	fmt.Println(json.This{doc})
+
+	for _, op := range doc.History() {
+		fmt.Println(json.This{op})
+	}
+
+	doc.Undo()
+	fmt.Println(json.This{doc})
+	doc.Undo()
+	doc.Undo()
+	fmt.Println(json.This{doc})
+	doc.Redo()
+	fmt.Println(json.This{doc})
+	doc.Undo()
+	doc.Undo()
+	fmt.Println(json.This{doc})
+	fmt.Println(doc.Undo())
+
+	// Output:
+	// patch operation 2 (test /title): test failed
+	// {"hosts":["z","b"],"owner":{"email":"me@example.com"},"title":"app"}
+	// {"op":"add","path":"/name","value":"app"}
+	// {"op":"add","path":"/owner","value":{"email":"me@example.com"}}
+	// {"op":"remove","path":"/hosts/0"}
+	// {"op":"add","path":"/hosts/0","value":"z"}
+	// {"op":"move","path":"/title","from":"/name"}
+	// {"hosts":["b"],"name":"app","owner":{"email":"me@example.com"}}
+	// {"hosts":["a","b"],"name":"app"}
+	// {"hosts":["a","b"],"name":"app","owner":{"email":"me@example.com"}}
+	// {"hosts":["a","b"]}
+	// nothing to undo
+}
+
+func ExampleDocument_Patch_subscribe() {
+	doc, _ := json.NewDocument(map[string]any{"a": 1, "b": []int{1, 2, 3}})
+	doc.Subscribe(`.`, func(c json.Change) { fmt.Println(c.Op, c.Path, c.Value) })
+	doc.Patch([]json.PatchOp{
+		{Op: "replace", Path: "/a", Value: 2},
+		{Op: "copy", From: "/b/0", Path: "/b/-"},
+		{Op: "remove", Path: "/b/1"},
+	})
+	doc.Undo()
+	fmt.Println(json.This{doc})
+	// Output:
+	// replace .a 2
+	// add .b[3] 1
+	// remove .b[1] <nil>
+	// add .b[1] 2
+	// remove .b[3] <nil>
+	// replace .a 1
+	// {"a":1,"b":[1,2,3]}
+}
@@ -0,0 +1,1938 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JQ is a compiled jq filter supporting the commonly used subset of the
+// jq language so that existing jq one-liners can be used with this
+// package (see ParseJQ and QueryOptions.JQ). It works whatever the
+// DefaultQueryEngine since it does not depend on yq. The following are
+// supported:
+//
+//     . .. .a ."a" .[e] .[n:m] .[] ? | , //
+//     == != < <= > >= and or + - * / %
+//     [e] {a, "b": e, (e): e} literals (e)
+//     if c then e elif c then e else e end
+//
+// And the following functions:
+//
+//     add all any arrays ascii_downcase ascii_upcase booleans empty
+//     endswith(s) error(e) first first(f) flatten floor from_entries
+//     fromjson group_by(f) has(k) join(s) keys keys_unsorted last
+//     last(f) length limit(n; f) ltrimstr(s) map(f) map_values(f) max
+//     max_by(f) min min_by(f) not nulls numbers objects range(n)
+//     range(n; m) recurse reverse rtrimstr(s) select(f) sort sort_by(f)
+//     split(s) startswith(s) strings test(re) to_entries tojson
+//     tonumber tostring type unique unique_by(f) values with_entries(f)
+//
+// Variables, definitions, reduce, foreach, paths, assignment, and
+// string interpolation are not supported.
+type JQ struct {
+	expr string
+	fn   jqFilter
+}
+
+// jqFilter produces every output for a single input.
+type jqFilter func(v any) ([]any, error)
+
+// ParseJQ compiles a jq filter (see JQ).
+func ParseJQ(expr string) (*JQ, error) {
+	toks, err := jqLex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &jqParser{toks: toks, expr: expr}
+	fn, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.is(`as`):
+		return nil, p.errorf(`variables not supported`)
+	case !p.done():
+		return nil, p.errorf(`unexpected %q`, p.peek().text)
+	}
+	return &JQ{expr, fn}, nil
+}
+
+// MustParseJQ is the same as ParseJQ but panics on any error.
+func MustParseJQ(expr string) *JQ {
+	q, err := ParseJQ(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String fulfills the fmt.Stringer interface returning the original
+// expression.
+func (q *JQ) String() string { return q.expr }
+
+// Select returns every output of the filter for a value decoded into
+// generic form (as with Path.Select).
+func (q *JQ) Select(v any) ([]any, error) { return q.fn(v) }
+
+// queryJQ is QueryWith for jq expressions.
+func (s This) queryJQ(expr string, o QueryOptions) (string, error) {
+	q, err := ParseJQ(expr)
+	if err != nil {
+		return "", err
+	}
+	src, err := s.querySource()
+	if err != nil {
+		return "", err
+	}
+	docs, err := cachedDoc(`jq`, src, parseJQDocs)
+	if err != nil {
+		return "", err
+	}
+	out := new(strings.Builder)
+	for _, doc := range docs.([]any) {
+		results, err := q.Select(doc)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range results {
+			if err := o.write(out, r); err != nil {
+				return "", err
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// parseJQDocs decodes all the JSON documents in the source.
+func parseJQDocs(src string) (any, error) {
+	dec := DefaultEngine.NewDecoder(strings.NewReader(src))
+	var docs []any
+	for dec.More() {
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// QueryJQ is the same as Query but with a jq expression (see JQ) and
+// with the output that jq itself produces (JSON indented by 2 spaces).
+func (s This) QueryJQ(expr string) (string, error) {
+	return s.QueryWith(expr, QueryOptions{JQ: true, Format: QueryJSON, Indent: 2})
+}
+
+// ------------------------------- lexer ------------------------------
+
+type jqToken struct {
+	kind byte // i(dent) f(ield) s(tring) n(umber) p(unct)
+	text string
+	pos  int
+}
+
+// jqPuncts are checked in order so longer ones must come first.
+var jqPuncts = []string{
+	`..`, `//`, `==`, `!=`, `<=`, `>=`,
+	`.`, `|`, `,`, `<`, `>`, `+`, `-`, `*`, `/`, `%`,
+	`(`, `)`, `[`, `]`, `{`, `}`, `:`, `;`, `?`, `$`,
+}
+
+func isJQIdent(r byte, first bool) bool {
+	return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' ||
+		!first && '0' <= r && r <= '9'
+}
+
+func jqLex(expr string) ([]jqToken, error) {
+	var toks []jqToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '#':
+			for i < len(expr) && expr[i] != '\n' {
+				i++
+			}
+
+		case c == '.' && i+1 < len(expr) && isJQIdent(expr[i+1], true):
+			n := i + 1
+			for n < len(expr) && isJQIdent(expr[n], false) {
+				n++
+			}
+			toks = append(toks, jqToken{'f', expr[i+1 : n], i})
+			i = n
+
+		case isJQIdent(c, true):
+			n := i
+			for n < len(expr) && isJQIdent(expr[n], false) {
+				n++
+			}
+			toks = append(toks, jqToken{'i', expr[i:n], i})
+			i = n
+
+		case '0' <= c && c <= '9':
+			n := i
+			for n < len(expr) && strings.IndexByte(`0123456789.eE`, expr[n]) >= 0 {
+				if (expr[n] == 'e' || expr[n] == 'E') && n+1 < len(expr) &&
+					(expr[n+1] == '-' || expr[n+1] == '+') {
+					n++
+				}
+				n++
+			}
+			toks = append(toks, jqToken{'n', expr[i:n], i})
+			i = n
+
+		case c == '"':
+			n := i + 1
+			for ; n < len(expr) && expr[n] != '"'; n++ {
+				if expr[n] == '\\' {
+					if n+1 < len(expr) && expr[n+1] == '(' {
+						return nil, fmt.Errorf(`string interpolation not supported at %d: %q`, n, expr)
+					}
+					n++
+				}
+			}
+			if n >= len(expr) {
+				return nil, fmt.Errorf(`unterminated string at %d: %q`, i, expr)
+			}
+			var s string
+			if err := Unmarshal([]byte(expr[i:n+1]), &s); err != nil {
+				return nil, fmt.Errorf(`invalid string at %d: %q`, i, expr)
+			}
+			toks = append(toks, jqToken{'s', s, i})
+			i = n + 1
+
+		default:
+			found := false
+			for _, p := range jqPuncts {
+				if strings.HasPrefix(expr[i:], p) {
+					toks = append(toks, jqToken{'p', p, i})
+					i += len(p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf(`unexpected %q at %d: %q`, c, i, expr)
+			}
+		}
+	}
+	return toks, nil
+}
+
+// ------------------------------- parser -----------------------------
+
+type jqParser struct {
+	toks []jqToken
+	i    int
+	expr string
+}
+
+func (p *jqParser) done() bool { return p.i >= len(p.toks) }
+
+func (p *jqParser) peek() jqToken {
+	if p.done() {
+		return jqToken{pos: len(p.expr)}
+	}
+	return p.toks[p.i]
+}
+
+// is returns true if the next token is the punctuation or keyword.
+func (p *jqParser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == 'p' || t.kind == 'i') && t.text == text
+}
+
+// accept consumes the next token if it is the punctuation or keyword.
+func (p *jqParser) accept(text string) bool {
+	if p.is(text) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *jqParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf(`expected %q`, text)
+	}
+	return nil
+}
+
+func (p *jqParser) errorf(format string, args ...any) error {
+	return fmt.Errorf(`jq: `+format+` at %d: %q`,
+		append(args, p.peek().pos, p.expr)...)
+}
+
+// pipe: comma ('|' comma)*
+func (p *jqParser) pipe() (jqFilter, error) {
+	left, err := p.comma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(`|`) {
+		right, err := p.comma()
+		if err != nil {
+			return nil, err
+		}
+		left = jqPipe(left, right)
+	}
+	return left, nil
+}
+
+func jqPipe(left, right jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		ins, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var outs []any
+		for _, in := range ins {
+			o, err := right(in)
+			if err != nil {
+				return nil, err
+			}
+			outs = append(outs, o...)
+		}
+		return outs, nil
+	}
+}
+
+// comma: alt (',' alt)*
+func (p *jqParser) comma() (jqFilter, error) {
+	left, err := p.alt()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(`,`) {
+		right, err := p.alt()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(v any) ([]any, error) {
+			a, err := l(v)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(v)
+			if err != nil {
+				return nil, err
+			}
+			return append(a, b...), nil
+		}
+	}
+	return left, nil
+}
+
+// alt: or ('//' alt)?
+func (p *jqParser) alt() (jqFilter, error) {
+	left, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(`//`) {
+		return left, nil
+	}
+	right, err := p.alt()
+	if err != nil {
+		return nil, err
+	}
+	return func(v any) ([]any, error) {
+		a, err := left(v)
+		var keep []any
+		if err == nil {
+			for _, x := range a {
+				if jqTrue(x) {
+					keep = append(keep, x)
+				}
+			}
+		}
+		if len(keep) > 0 {
+			return keep, nil
+		}
+		return right(v)
+	}, nil
+}
+
+// or: and ('or' and)*
+func (p *jqParser) or() (jqFilter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(`or`) {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = jqLogic(left, right, true)
+	}
+	return left, nil
+}
+
+// and: compare ('and' compare)*
+func (p *jqParser) and() (jqFilter, error) {
+	left, err := p.compare()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(`and`) {
+		right, err := p.compare()
+		if err != nil {
+			return nil, err
+		}
+		left = jqLogic(left, right, false)
+	}
+	return left, nil
+}
+
+// jqLogic short-circuits (or when or is true, and otherwise).
+func jqLogic(left, right jqFilter, or bool) jqFilter {
+	return func(v any) ([]any, error) {
+		ls, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var outs []any
+		for _, l := range ls {
+			if jqTrue(l) == or {
+				outs = append(outs, or)
+				continue
+			}
+			rs, err := right(v)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rs {
+				outs = append(outs, jqTrue(r))
+			}
+		}
+		return outs, nil
+	}
+}
+
+var jqCompareOps = []string{`==`, `!=`, `<=`, `>=`, `<`, `>`}
+
+// compare: additive (op additive)?
+func (p *jqParser) compare() (jqFilter, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range jqCompareOps {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		return jqBinary(left, right, func(a, b any) (any, error) {
+			c := jqCompare(a, b)
+			switch op {
+			case `==`:
+				return c == 0, nil
+			case `!=`:
+				return c != 0, nil
+			case `<=`:
+				return c <= 0, nil
+			case `>=`:
+				return c >= 0, nil
+			case `<`:
+				return c < 0, nil
+			}
+			return c > 0, nil
+		}), nil
+	}
+	return left, nil
+}
+
+// additive: multiplicative (('+'|'-') multiplicative)*
+func (p *jqParser) additive() (jqFilter, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(`+`) || p.is(`-`) {
+		op := p.peek().text
+		p.i++
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary(left, right, func(a, b any) (any, error) {
+			return jqArith(op, a, b)
+		})
+	}
+	return left, nil
+}
+
+// multiplicative: postfix (('*'|'/'|'%') postfix)*
+func (p *jqParser) multiplicative() (jqFilter, error) {
+	left, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(`*`) || p.is(`/`) || p.is(`%`) {
+		op := p.peek().text
+		p.i++
+		right, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary(left, right, func(a, b any) (any, error) {
+			return jqArith(op, a, b)
+		})
+	}
+	return left, nil
+}
+
+// jqBinary applies the operation to every combination of outputs (the
+// right side varying slowest, as with jq).
+func jqBinary(left, right jqFilter, op func(a, b any) (any, error)) jqFilter {
+	return func(v any) ([]any, error) {
+		rs, err := right(v)
+		if err != nil {
+			return nil, err
+		}
+		ls, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var outs []any
+		for _, r := range rs {
+			for _, l := range ls {
+				o, err := op(l, r)
+				if err != nil {
+					return nil, err
+				}
+				outs = append(outs, o)
+			}
+		}
+		return outs, nil
+	}
+}
+
+// postfix: term ( .a | ."a" | [e] | [] | [n:m] | ? )*
+func (p *jqParser) postfix() (jqFilter, error) {
+	fn, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+
+		case t.kind == 'f':
+			p.i++
+			fn = jqPipe(fn, jqField(t.text))
+
+		case t.kind == 'p' && t.text == `.` && p.i+1 < len(p.toks) &&
+			p.toks[p.i+1].kind == 's':
+			p.i++
+			fn = jqPipe(fn, jqField(p.peek().text))
+			p.i++
+
+		case t.kind == 'p' && t.text == `.` && p.i+1 < len(p.toks) &&
+			p.toks[p.i+1].text == `[`:
+			p.i++
+
+		case p.is(`[`):
+			p.i++
+			idx, err := p.brackets()
+			if err != nil {
+				return nil, err
+			}
+			term := fn
+			fn = func(v any) ([]any, error) {
+				ins, err := term(v)
+				if err != nil {
+					return nil, err
+				}
+				var outs []any
+				for _, in := range ins {
+					o, err := idx(v, in)
+					if err != nil {
+						return nil, err
+					}
+					outs = append(outs, o...)
+				}
+				return outs, nil
+			}
+
+		case p.is(`?`):
+			p.i++
+			fn = jqTry(fn)
+
+		default:
+			return fn, nil
+		}
+	}
+}
+
+// jqTry suppresses errors (returning nothing instead).
+func jqTry(fn jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		outs, err := fn(v)
+		if err != nil {
+			return nil, nil
+		}
+		return outs, nil
+	}
+}
+
+// brackets parses the inside of [] after a term (the opening bracket
+// already consumed) returning a function of the original input (for
+// evaluating the index expressions) and the value being indexed.
+func (p *jqParser) brackets() (func(orig, v any) ([]any, error), error) {
+	if p.accept(`]`) {
+		return func(_, v any) ([]any, error) { return jqIterate(v) }, nil
+	}
+	var from, to jqFilter
+	var err error
+	if !p.is(`:`) {
+		from, err = p.pipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !p.accept(`:`) {
+		if err := p.expect(`]`); err != nil {
+			return nil, err
+		}
+		return func(orig, v any) ([]any, error) {
+			keys, err := from(orig)
+			if err != nil {
+				return nil, err
+			}
+			outs := make([]any, 0, len(keys))
+			for _, k := range keys {
+				o, err := jqIndex(v, k)
+				if err != nil {
+					return nil, err
+				}
+				outs = append(outs, o)
+			}
+			return outs, nil
+		}, nil
+	}
+	if !p.is(`]`) {
+		to, err = p.pipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(`]`); err != nil {
+		return nil, err
+	}
+	return func(orig, v any) ([]any, error) {
+		var lo, hi any
+		if from != nil {
+			f, err := jqOne(from, orig)
+			if err != nil {
+				return nil, err
+			}
+			lo = f
+		}
+		if to != nil {
+			t, err := jqOne(to, orig)
+			if err != nil {
+				return nil, err
+			}
+			hi = t
+		}
+		o, err := jqSlice(v, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		return []any{o}, nil
+	}, nil
+}
+
+// jqOne returns the first output of the filter (or null).
+func jqOne(fn jqFilter, v any) (any, error) {
+	outs, err := fn(v)
+	if err != nil || len(outs) == 0 {
+		return nil, err
+	}
+	return outs[0], nil
+}
+
+// term parses a single term (without postfix).
+func (p *jqParser) term() (jqFilter, error) {
+	t := p.peek()
+	if p.done() {
+		return nil, p.errorf(`unexpected end`)
+	}
+	switch t.kind {
+
+	case 'f':
+		p.i++
+		return jqField(t.text), nil
+
+	case 's':
+		p.i++
+		return jqConst(t.text), nil
+
+	case 'n':
+		p.i++
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(`invalid number %q`, t.text)
+		}
+		return jqConst(n), nil
+
+	case 'i':
+		return p.word()
+
+	}
+
+	switch t.text {
+
+	case `.`:
+		p.i++
+		if p.peek().kind == 's' {
+			key := p.peek().text
+			p.i++
+			return jqField(key), nil
+		}
+		return func(v any) ([]any, error) { return []any{v}, nil }, nil
+
+	case `..`:
+		p.i++
+		return jqRecurse, nil
+
+	case `(`:
+		p.i++
+		fn, err := p.pipe()
+		if err != nil {
+			return nil, err
+		}
+		return fn, p.expect(`)`)
+
+	case `-`:
+		p.i++
+		fn, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		return jqPipe(fn, func(v any) ([]any, error) {
+			n, is := jqNumber(v)
+			if !is {
+				return nil, fmt.Errorf(`%s cannot be negated`, jqType(v))
+			}
+			return []any{-n}, nil
+		}), nil
+
+	case `[`:
+		p.i++
+		if p.accept(`]`) {
+			return jqConst([]any{}), nil
+		}
+		fn, err := p.pipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(`]`); err != nil {
+			return nil, err
+		}
+		return func(v any) ([]any, error) {
+			outs, err := fn(v)
+			if err != nil {
+				return nil, err
+			}
+			if outs == nil {
+				outs = []any{}
+			}
+			return []any{outs}, nil
+		}, nil
+
+	case `{`:
+		p.i++
+		return p.object()
+
+	case `$`:
+		return nil, p.errorf(`variables not supported`)
+
+	}
+	return nil, p.errorf(`unexpected %q`, t.text)
+}
+
+// object parses object construction (after the opening brace).
+func (p *jqParser) object() (jqFilter, error) {
+	type entry struct{ key, val jqFilter }
+	var entries []entry
+	for !p.accept(`}`) {
+		if len(entries) > 0 {
+			if err := p.expect(`,`); err != nil {
+				return nil, err
+			}
+		}
+		t := p.peek()
+		var e entry
+		switch {
+		case t.kind == 'i' || t.kind == 's':
+			p.i++
+			e.key = jqConst(t.text)
+			e.val = jqField(t.text)
+		case p.accept(`(`):
+			key, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(`)`); err != nil {
+				return nil, err
+			}
+			e.key = key
+		default:
+			return nil, p.errorf(`invalid object key`)
+		}
+		if p.accept(`:`) {
+			val, err := p.objectValue()
+			if err != nil {
+				return nil, err
+			}
+			e.val = val
+		} else if e.val == nil {
+			return nil, p.errorf(`expected ":"`)
+		}
+		entries = append(entries, e)
+	}
+	return func(v any) ([]any, error) {
+		objs := []map[string]any{{}}
+		for _, e := range entries {
+			keys, err := e.key(v)
+			if err != nil {
+				return nil, err
+			}
+			vals, err := e.val(v)
+			if err != nil {
+				return nil, err
+			}
+			var next []map[string]any
+			for _, o := range objs {
+				for _, k := range keys {
+					ks, is := k.(string)
+					if !is {
+						return nil, fmt.Errorf(`object keys must be strings, not %s`, jqType(k))
+					}
+					for _, val := range vals {
+						c := make(map[string]any, len(o)+1)
+						for ok, ov := range o {
+							c[ok] = ov
+						}
+						c[ks] = val
+						next = append(next, c)
+					}
+				}
+			}
+			objs = next
+		}
+		outs := make([]any, len(objs))
+		for i, o := range objs {
+			outs[i] = o
+		}
+		return outs, nil
+	}, nil
+}
+
+// objectValue parses an object value which (like jq) allows pipes
+// within it but stops at the next comma.
+func (p *jqParser) objectValue() (jqFilter, error) {
+	left, err := p.alt()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(`|`) {
+		right, err := p.alt()
+		if err != nil {
+			return nil, err
+		}
+		left = jqPipe(left, right)
+	}
+	return left, nil
+}
+
+// word parses keywords, literals, and function calls.
+func (p *jqParser) word() (jqFilter, error) {
+	name := p.peek().text
+	p.i++
+	switch name {
+	case `true`:
+		return jqConst(true), nil
+	case `false`:
+		return jqConst(false), nil
+	case `null`:
+		return jqConst(nil), nil
+	case `if`:
+		return p.conditional()
+	case `def`, `reduce`, `foreach`, `as`, `try`, `label`, `import`, `include`:
+		return nil, p.errorf(`%s not supported`, name)
+	}
+	var args []jqFilter
+	if p.accept(`(`) {
+		for {
+			arg, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(`)`) {
+				break
+			}
+			if err := p.expect(`;`); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fn, has := jqFuncs[name+`/`+strconv.Itoa(len(args))]
+	if !has {
+		return nil, p.errorf(`%s/%d is not defined`, name, len(args))
+	}
+	return fn(args), nil
+}
+
+// conditional parses if/elif/else/end (after the if).
+func (p *jqParser) conditional() (jqFilter, error) {
+	cond, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(`then`); err != nil {
+		return nil, err
+	}
+	then, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	var other jqFilter
+	switch {
+	case p.accept(`elif`):
+		if other, err = p.conditional(); err != nil {
+			return nil, err
+		}
+		return jqIf(cond, then, other), nil
+	case p.accept(`else`):
+		if other, err = p.pipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(`end`); err != nil {
+		return nil, err
+	}
+	return jqIf(cond, then, other), nil
+}
+
+func jqIf(cond, then, other jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		cs, err := cond(v)
+		if err != nil {
+			return nil, err
+		}
+		var outs []any
+		for _, c := range cs {
+			var o []any
+			switch {
+			case jqTrue(c):
+				o, err = then(v)
+			case other != nil:
+				o, err = other(v)
+			default:
+				o = []any{v}
+			}
+			if err != nil {
+				return nil, err
+			}
+			outs = append(outs, o...)
+		}
+		return outs, nil
+	}
+}
+
+// ------------------------------ builtins ----------------------------
+
+func jqConst(c any) jqFilter {
+	return func(any) ([]any, error) { return []any{c}, nil }
+}
+
+func jqField(key string) jqFilter {
+	return func(v any) ([]any, error) {
+		o, err := jqIndex(v, key)
+		if err != nil {
+			return nil, err
+		}
+		return []any{o}, nil
+	}
+}
+
+func jqIndex(v, k any) (any, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		if ks, is := k.(string); is {
+			return t[ks], nil
+		}
+	case []any:
+		if n, is := jqNumber(k); is {
+			i := int(math.Floor(n))
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				return nil, nil
+			}
+			return t[i], nil
+		}
+	}
+	if ks, is := k.(string); is {
+		return nil, fmt.Errorf(`cannot index %s with "%s"`, jqType(v), ks)
+	}
+	return nil, fmt.Errorf(`cannot index %s with %s`, jqType(v), jqType(k))
+}
+
+func jqSlice(v, lo, hi any) (any, error) {
+	bounds := func(n int) (int, int, error) {
+		from, to := 0, n
+		for i, b := range []any{lo, hi} {
+			if b == nil {
+				continue
+			}
+			f, is := jqNumber(b)
+			if !is {
+				return 0, 0, fmt.Errorf(`slice indices must be numbers`)
+			}
+			x := int(math.Floor(f))
+			if x < 0 {
+				x += n
+			}
+			if x < 0 {
+				x = 0
+			}
+			if x > n {
+				x = n
+			}
+			if i == 0 {
+				from = x
+			} else {
+				to = x
+			}
+		}
+		if to < from {
+			to = from
+		}
+		return from, to, nil
+	}
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		from, to, err := bounds(len(t))
+		if err != nil {
+			return nil, err
+		}
+		return append([]any{}, t[from:to]...), nil
+	case string:
+		r := []rune(t)
+		from, to, err := bounds(len(r))
+		if err != nil {
+			return nil, err
+		}
+		return string(r[from:to]), nil
+	}
+	return nil, fmt.Errorf(`cannot slice %s`, jqType(v))
+}
+
+func jqIterate(v any) ([]any, error) {
+	switch t := v.(type) {
+	case []any:
+		return append([]any{}, t...), nil
+	case map[string]any:
+		keys := jqKeys(t)
+		outs := make([]any, len(keys))
+		for i, k := range keys {
+			outs[i] = t[k]
+		}
+		return outs, nil
+	}
+	return nil, fmt.Errorf(`cannot iterate over %s`, jqType(v))
+}
+
+func jqRecurse(v any) ([]any, error) {
+	outs := []any{v}
+	switch v.(type) {
+	case []any, map[string]any:
+		items, _ := jqIterate(v)
+		for _, item := range items {
+			o, _ := jqRecurse(item)
+			outs = append(outs, o...)
+		}
+	}
+	return outs, nil
+}
+
+func jqKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jqTrue returns false only for false and null.
+func jqTrue(v any) bool {
+	b, is := v.(bool)
+	return v != nil && (!is || b)
+}
+
+// jqNumber returns the value as a float64 if it is a number.
+func jqNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case interface{ Float64() (float64, error) }: // json.Number
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jqType returns the jq type name of the value.
+func jqType(v any) string {
+	switch v.(type) {
+	case nil:
+		return `null`
+	case bool:
+		return `boolean`
+	case string:
+		return `string`
+	case []any:
+		return `array`
+	case map[string]any:
+		return `object`
+	}
+	if _, is := jqNumber(v); is {
+		return `number`
+	}
+	return fmt.Sprintf(`%T`, v)
+}
+
+// jqOrder is the jq sort order of the types.
+func jqOrder(v any) int {
+	switch t := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if t {
+			return 2
+		}
+		return 1
+	case string:
+		return 4
+	case []any:
+		return 5
+	case map[string]any:
+		return 6
+	}
+	return 3
+}
+
+// jqCompare orders any two values as jq does.
+func jqCompare(a, b any) int {
+	oa, ob := jqOrder(a), jqOrder(b)
+	if oa != ob {
+		return oa - ob
+	}
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case []any:
+		y := b.([]any)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := jqCompare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]any:
+		y := b.(map[string]any)
+		kx, ky := jqKeys(x), jqKeys(y)
+		ax, ay := make([]any, len(kx)), make([]any, len(ky))
+		for i, k := range kx {
+			ax[i] = k
+		}
+		for i, k := range ky {
+			ay[i] = k
+		}
+		if c := jqCompare(ax, ay); c != 0 {
+			return c
+		}
+		for _, k := range kx {
+			if c := jqCompare(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	if oa == 3 {
+		na, _ := jqNumber(a)
+		nb, _ := jqNumber(b)
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+	return 0
+}
+
+func jqArith(op string, a, b any) (any, error) {
+	na, anum := jqNumber(a)
+	nb, bnum := jqNumber(b)
+	if anum && bnum {
+		switch op {
+		case `+`:
+			return na + nb, nil
+		case `-`:
+			return na - nb, nil
+		case `*`:
+			return na * nb, nil
+		case `/`:
+			if nb == 0 {
+				return nil, fmt.Errorf(`cannot divide %v by zero`, na)
+			}
+			return na / nb, nil
+		case `%`:
+			if int(nb) == 0 {
+				return nil, fmt.Errorf(`cannot divide %v by zero`, na)
+			}
+			return float64(int(na) % int(nb)), nil
+		}
+	}
+	switch op {
+	case `+`:
+		if a == nil {
+			return b, nil
+		}
+		if b == nil {
+			return a, nil
+		}
+		switch x := a.(type) {
+		case string:
+			if y, is := b.(string); is {
+				return x + y, nil
+			}
+		case []any:
+			if y, is := b.([]any); is {
+				return append(append([]any{}, x...), y...), nil
+			}
+		case map[string]any:
+			if y, is := b.(map[string]any); is {
+				m := make(map[string]any, len(x)+len(y))
+				for k, v := range x {
+					m[k] = v
+				}
+				for k, v := range y {
+					m[k] = v
+				}
+				return m, nil
+			}
+		}
+	case `-`:
+		x, xa := a.([]any)
+		y, ya := b.([]any)
+		if xa && ya {
+			out := []any{}
+			for _, i := range x {
+				keep := true
+				for _, j := range y {
+					if jqCompare(i, j) == 0 {
+						keep = false
+						break
+					}
+				}
+				if keep {
+					out = append(out, i)
+				}
+			}
+			return out, nil
+		}
+	case `/`:
+		x, xs := a.(string)
+		y, ys := b.(string)
+		if xs && ys {
+			return jqSplit(x, y), nil
+		}
+	}
+	return nil, fmt.Errorf(`%s (%s) and %s (%s) cannot be combined with %s`,
+		jqType(a), jqShort(a), jqType(b), jqShort(b), op)
+}
+
+func jqShort(v any) string {
+	buf, _ := Marshal(v)
+	if len(buf) > 11 {
+		return string(buf[:10]) + `...`
+	}
+	return string(buf)
+}
+
+func jqSplit(s, sep string) []any {
+	out := []any{}
+	if s == `` {
+		return out
+	}
+	for _, p := range strings.Split(s, sep) {
+		out = append(out, p)
+	}
+	return out
+}
+
+// jqFuncs are the builtin functions by name/arity.
+var jqFuncs map[string]func(args []jqFilter) jqFilter
+
+func init() {
+	// simple functions of the input alone
+	simple := map[string]func(v any) (any, error){
+		`not`:    func(v any) (any, error) { return !jqTrue(v), nil },
+		`type`:   func(v any) (any, error) { return jqType(v), nil },
+		`length`: jqLength,
+		`keys`:   jqKeysOf,
+		`add`: func(v any) (any, error) {
+			items, err := jqIterate(v)
+			if err != nil {
+				return nil, err
+			}
+			var sum any
+			for _, i := range items {
+				if sum, err = jqArith(`+`, sum, i); err != nil {
+					return nil, err
+				}
+			}
+			return sum, nil
+		},
+		`any`: func(v any) (any, error) {
+			items, err := jqIterate(v)
+			for _, i := range items {
+				if jqTrue(i) {
+					return true, nil
+				}
+			}
+			return false, err
+		},
+		`all`: func(v any) (any, error) {
+			items, err := jqIterate(v)
+			for _, i := range items {
+				if !jqTrue(i) {
+					return false, nil
+				}
+			}
+			return true, err
+		},
+		`first`: func(v any) (any, error) { return jqIndex(v, 0.0) },
+		`last`:  func(v any) (any, error) { return jqIndex(v, -1.0) },
+		`reverse`: func(v any) (any, error) {
+			if s, is := v.(string); is {
+				r := []rune(s)
+				for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+					r[i], r[j] = r[j], r[i]
+				}
+				return string(r), nil
+			}
+			if v == nil {
+				return []any{}, nil
+			}
+			a, is := v.([]any)
+			if !is {
+				return nil, fmt.Errorf(`cannot reverse %s`, jqType(v))
+			}
+			out := make([]any, len(a))
+			for i, item := range a {
+				out[len(a)-1-i] = item
+			}
+			return out, nil
+		},
+		`sort`: func(v any) (any, error) { return jqSortBy(v, nil) },
+		`unique`: func(v any) (any, error) {
+			return jqGroupBy(v, nil, func(g []any) any { return g[0] })
+		},
+		`min`: func(v any) (any, error) { return jqExtreme(v, nil, -1) },
+		`max`: func(v any) (any, error) { return jqExtreme(v, nil, 1) },
+		`flatten`: func(v any) (any, error) {
+			a, is := v.([]any)
+			if !is {
+				return nil, fmt.Errorf(`cannot flatten %s`, jqType(v))
+			}
+			return jqFlatten(a), nil
+		},
+		`floor`: func(v any) (any, error) {
+			n, is := jqNumber(v)
+			if !is {
+				return nil, fmt.Errorf(`%s has no floor`, jqType(v))
+			}
+			return math.Floor(n), nil
+		},
+		`tostring`: func(v any) (any, error) {
+			if s, is := v.(string); is {
+				return s, nil
+			}
+			buf, err := Marshal(v)
+			return string(buf), err
+		},
+		`tojson`: func(v any) (any, error) {
+			buf, err := Marshal(v)
+			return string(buf), err
+		},
+		`fromjson`: func(v any) (any, error) {
+			s, is := v.(string)
+			if !is {
+				return nil, fmt.Errorf(`%s cannot be parsed as JSON`, jqType(v))
+			}
+			var out any
+			err := Unmarshal([]byte(s), &out)
+			return out, err
+		},
+		`tonumber`: func(v any) (any, error) {
+			if n, is := jqNumber(v); is {
+				return n, nil
+			}
+			s, is := v.(string)
+			if !is {
+				return nil, fmt.Errorf(`%s cannot be parsed as a number`, jqType(v))
+			}
+			return strconv.ParseFloat(strings.TrimSpace(s), 64)
+		},
+		`ascii_downcase`: jqCase('A', 'Z', 'a'-'A'),
+		`ascii_upcase`:   jqCase('a', 'z', 'A'-'a'),
+		`to_entries`: func(v any) (any, error) {
+			m, is := v.(map[string]any)
+			if !is {
+				return nil, fmt.Errorf(`%s has no keys`, jqType(v))
+			}
+			out := []any{}
+			for _, k := range jqKeys(m) {
+				out = append(out, map[string]any{`key`: k, `value`: m[k]})
+			}
+			return out, nil
+		},
+		`from_entries`: jqFromEntries,
+	}
+	simple[`keys_unsorted`] = simple[`keys`]
+
+	jqFuncs = map[string]func([]jqFilter) jqFilter{}
+	for name, fn := range simple {
+		fn := fn
+		jqFuncs[name+`/0`] = func([]jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				o, err := fn(v)
+				if err != nil {
+					return nil, err
+				}
+				return []any{o}, nil
+			}
+		}
+	}
+
+	// type selectors
+	for name, typ := range map[string]string{
+		`arrays`: `array`, `objects`: `object`, `strings`: `string`,
+		`numbers`: `number`, `booleans`: `boolean`, `nulls`: `null`,
+	} {
+		typ := typ
+		jqFuncs[name+`/0`] = func([]jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				if jqType(v) == typ {
+					return []any{v}, nil
+				}
+				return nil, nil
+			}
+		}
+	}
+
+	// string functions with a single string argument
+	for name, fn := range map[string]func(s, a string) any{
+		`startswith`: func(s, a string) any { return strings.HasPrefix(s, a) },
+		`endswith`:   func(s, a string) any { return strings.HasSuffix(s, a) },
+		`ltrimstr`:   func(s, a string) any { return strings.TrimPrefix(s, a) },
+		`rtrimstr`:   func(s, a string) any { return strings.TrimSuffix(s, a) },
+		`split`:      func(s, a string) any { return jqSplit(s, a) },
+	} {
+		name, fn := name, fn
+		jqFuncs[name+`/1`] = func(args []jqFilter) jqFilter {
+			return jqWithArg(args[0], func(v, a any) (any, error) {
+				s, sok := v.(string)
+				as, aok := a.(string)
+				if !sok || !aok {
+					if name == `ltrimstr` || name == `rtrimstr` {
+						return v, nil
+					}
+					return nil, fmt.Errorf(`%s() requires string inputs`, name)
+				}
+				return fn(s, as), nil
+			})
+		}
+	}
+
+	fns := map[string]func(args []jqFilter) jqFilter{
+		`empty/0`: func([]jqFilter) jqFilter {
+			return func(any) ([]any, error) { return nil, nil }
+		},
+		`values/0`: func([]jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				if v == nil {
+					return nil, nil
+				}
+				return []any{v}, nil
+			}
+		},
+		`recurse/0`: func([]jqFilter) jqFilter { return jqRecurse },
+		`error/1`: func(args []jqFilter) jqFilter {
+			return jqWithArg(args[0], func(_, a any) (any, error) {
+				if s, is := a.(string); is {
+					return nil, errors.New(s)
+				}
+				return nil, fmt.Errorf(`%s (not a string)`, jqShort(a))
+			})
+		},
+		`select/1`: func(args []jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				cs, err := args[0](v)
+				if err != nil {
+					return nil, err
+				}
+				var outs []any
+				for _, c := range cs {
+					if jqTrue(c) {
+						outs = append(outs, v)
+					}
+				}
+				return outs, nil
+			}
+		},
+		`map/1`: func(args []jqFilter) jqFilter {
+			return jqCollect(jqPipe(jqIterate, args[0]))
+		},
+		`map_values/1`: func(args []jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				return jqMapValues(v, args[0])
+			}
+		},
+		`with_entries/1`: func(args []jqFilter) jqFilter {
+			return jqPipe(jqPipe(jqFuncs[`to_entries/0`](nil),
+				jqFuncs[`map/1`](args)), jqFuncs[`from_entries/0`](nil))
+		},
+		`has/1`: func(args []jqFilter) jqFilter {
+			return jqWithArg(args[0], func(v, k any) (any, error) {
+				switch t := v.(type) {
+				case map[string]any:
+					if ks, is := k.(string); is {
+						_, has := t[ks]
+						return has, nil
+					}
+				case []any:
+					if n, is := jqNumber(k); is {
+						return n >= 0 && int(n) < len(t), nil
+					}
+				}
+				return nil, fmt.Errorf(`cannot check whether %s has a %s key`,
+					jqType(v), jqType(k))
+			})
+		},
+		`join/1`: func(args []jqFilter) jqFilter {
+			return jqWithArg(args[0], func(v, sep any) (any, error) {
+				items, err := jqIterate(v)
+				if err != nil {
+					return nil, err
+				}
+				s, _ := sep.(string)
+				parts := make([]string, len(items))
+				for i, item := range items {
+					switch t := item.(type) {
+					case nil:
+					case string:
+						parts[i] = t
+					case bool, float64:
+						parts[i] = fmt.Sprint(t)
+					default:
+						if _, is := jqNumber(t); !is {
+							return nil, fmt.Errorf(`cannot join with %s`, jqType(t))
+						}
+						parts[i] = fmt.Sprint(t)
+					}
+				}
+				return strings.Join(parts, s), nil
+			})
+		},
+		`test/1`: func(args []jqFilter) jqFilter {
+			return jqWithArg(args[0], func(v, re any) (any, error) {
+				s, sok := v.(string)
+				rs, rok := re.(string)
+				if !sok || !rok {
+					return nil, fmt.Errorf(`%s cannot be matched, as it is not a string`, jqType(v))
+				}
+				r, err := regexp.Compile(rs)
+				if err != nil {
+					return nil, err
+				}
+				return r.MatchString(s), nil
+			})
+		},
+		`sort_by/1`: func(args []jqFilter) jqFilter {
+			return jqWithInput(func(v any) (any, error) { return jqSortBy(v, args[0]) })
+		},
+		`group_by/1`: func(args []jqFilter) jqFilter {
+			return jqWithInput(func(v any) (any, error) {
+				return jqGroupBy(v, args[0], func(g []any) any { return g })
+			})
+		},
+		`unique_by/1`: func(args []jqFilter) jqFilter {
+			return jqWithInput(func(v any) (any, error) {
+				return jqGroupBy(v, args[0], func(g []any) any { return g[0] })
+			})
+		},
+		`min_by/1`: func(args []jqFilter) jqFilter {
+			return jqWithInput(func(v any) (any, error) { return jqExtreme(v, args[0], -1) })
+		},
+		`max_by/1`: func(args []jqFilter) jqFilter {
+			return jqWithInput(func(v any) (any, error) { return jqExtreme(v, args[0], 1) })
+		},
+		`first/1`: func(args []jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				outs, err := args[0](v)
+				if err != nil || len(outs) == 0 {
+					return nil, err
+				}
+				return outs[:1], nil
+			}
+		},
+		`last/1`: func(args []jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				outs, err := args[0](v)
+				if err != nil || len(outs) == 0 {
+					return nil, err
+				}
+				return outs[len(outs)-1:], nil
+			}
+		},
+		`limit/2`: func(args []jqFilter) jqFilter {
+			return func(v any) ([]any, error) {
+				n, err := jqOne(args[0], v)
+				if err != nil {
+					return nil, err
+				}
+				max, _ := jqNumber(n)
+				outs, err := args[1](v)
+				if err != nil {
+					return nil, err
+				}
+				if int(max) < len(outs) {
+					outs = outs[:int(math.Max(max, 0))]
+				}
+				return outs, nil
+			}
+		},
+		`range/1`: func(args []jqFilter) jqFilter {
+			return jqRange(jqConst(0.0), args[0])
+		},
+		`range/2`: func(args []jqFilter) jqFilter {
+			return jqRange(args[0], args[1])
+		},
+	}
+	for name, fn := range fns {
+		jqFuncs[name] = fn
+	}
+}
+
+// jqWithArg calls the function for every output of the argument.
+func jqWithArg(arg jqFilter, fn func(v, a any) (any, error)) jqFilter {
+	return func(v any) ([]any, error) {
+		as, err := arg(v)
+		if err != nil {
+			return nil, err
+		}
+		outs := make([]any, 0, len(as))
+		for _, a := range as {
+			o, err := fn(v, a)
+			if err != nil {
+				return nil, err
+			}
+			outs = append(outs, o)
+		}
+		return outs, nil
+	}
+}
+
+func jqWithInput(fn func(v any) (any, error)) jqFilter {
+	return func(v any) ([]any, error) {
+		o, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		return []any{o}, nil
+	}
+}
+
+// jqCollect returns all outputs of the filter as a single array.
+func jqCollect(fn jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		outs, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		if outs == nil {
+			outs = []any{}
+		}
+		return []any{outs}, nil
+	}
+}
+
+// jqCase shifts the ASCII letters in the range (leaving all else).
+func jqCase(from, to, shift rune) func(v any) (any, error) {
+	return func(v any) (any, error) {
+		s, is := v.(string)
+		if !is {
+			return nil, fmt.Errorf(`%s cannot be case converted`, jqType(v))
+		}
+		return strings.Map(func(r rune) rune {
+			if from <= r && r <= to {
+				return r + shift
+			}
+			return r
+		}, s), nil
+	}
+}
+
+func jqLength(v any) (any, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0.0, nil
+	case bool:
+		return nil, fmt.Errorf(`boolean (%v) has no length`, t)
+	case string:
+		return float64(len([]rune(t))), nil
+	case []any:
+		return float64(len(t)), nil
+	case map[string]any:
+		return float64(len(t)), nil
+	}
+	n, _ := jqNumber(v)
+	return math.Abs(n), nil
+}
+
+func jqKeysOf(v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		out := []any{}
+		for _, k := range jqKeys(t) {
+			out = append(out, k)
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(t))
+		for i := range t {
+			out[i] = float64(i)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf(`%s has no keys`, jqType(v))
+}
+
+func jqFromEntries(v any) (any, error) {
+	items, err := jqIterate(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	for _, item := range items {
+		e, is := item.(map[string]any)
+		if !is {
+			return nil, fmt.Errorf(`cannot use %s as an entry`, jqType(item))
+		}
+		var key any
+		for _, name := range []string{`key`, `k`, `name`, `Name`, `Key`, `K`} {
+			if k, has := e[name]; has && k != nil {
+				key = k
+				break
+			}
+		}
+		var val any
+		for _, name := range []string{`value`, `v`, `Value`, `V`} {
+			if x, has := e[name]; has {
+				val = x
+				break
+			}
+		}
+		switch k := key.(type) {
+		case string:
+			m[k] = val
+		case bool, nil:
+			m[fmt.Sprint(k)] = val
+		default:
+			if _, is := jqNumber(k); !is {
+				return nil, fmt.Errorf(`cannot use %s as object key`, jqType(k))
+			}
+			m[fmt.Sprint(k)] = val
+		}
+	}
+	return m, nil
+}
+
+func jqMapValues(v any, fn jqFilter) ([]any, error) {
+	switch t := v.(type) {
+	case []any:
+		out := []any{}
+		for _, item := range t {
+			o, err := fn(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(o) > 0 {
+				out = append(out, o[0])
+			}
+		}
+		return []any{out}, nil
+	case map[string]any:
+		out := map[string]any{}
+		for k, item := range t {
+			o, err := fn(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(o) > 0 {
+				out[k] = o[0]
+			}
+		}
+		return []any{out}, nil
+	}
+	return nil, fmt.Errorf(`cannot iterate over %s`, jqType(v))
+}
+
+func jqFlatten(a []any) []any {
+	out := []any{}
+	for _, item := range a {
+		if sub, is := item.([]any); is {
+			out = append(out, jqFlatten(sub)...)
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// jqKeyed pairs array items with the (array of) outputs of the filter
+// (or the items themselves when the filter is nil).
+func jqKeyed(v any, by jqFilter) ([]any, [][]any, error) {
+	a, is := v.([]any)
+	if !is {
+		return nil, nil, fmt.Errorf(`cannot sort %s, as it is not an array`, jqType(v))
+	}
+	keys := make([][]any, len(a))
+	for i, item := range a {
+		if by == nil {
+			keys[i] = []any{item}
+			continue
+		}
+		k, err := by(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[i] = k
+	}
+	return a, keys, nil
+}
+
+func jqSortBy(v any, by jqFilter) (any, error) {
+	a, keys, err := jqKeyed(v, by)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(a))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return jqCompare(keys[idx[i]], keys[idx[j]]) < 0
+	})
+	out := make([]any, len(a))
+	for i, x := range idx {
+		out[i] = a[x]
+	}
+	return out, nil
+}
+
+func jqGroupBy(v any, by jqFilter, each func([]any) any) (any, error) {
+	a, keys, err := jqKeyed(v, by)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(a))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return jqCompare(keys[idx[i]], keys[idx[j]]) < 0
+	})
+	out := []any{}
+	var group []any
+	for n, i := range idx {
+		if n > 0 && jqCompare(keys[idx[n-1]], keys[i]) != 0 {
+			out = append(out, each(group))
+			group = nil
+		}
+		group = append(group, a[i])
+	}
+	if len(group) > 0 {
+		out = append(out, each(group))
+	}
+	return out, nil
+}
+
+func jqExtreme(v any, by jqFilter, dir int) (any, error) {
+	a, keys, err := jqKeyed(v, by)
+	if err != nil || len(a) == 0 {
+		return nil, err
+	}
+	best := 0
+	for i := 1; i < len(a); i++ {
+		c := jqCompare(keys[i], keys[best])
+		if c*dir > 0 || (c == 0 && dir > 0) {
+			best = i
+		}
+	}
+	return a[best], nil
+}
+
+func jqRange(from, upto jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		f, err := jqOne(from, v)
+		if err != nil {
+			return nil, err
+		}
+		u, err := jqOne(upto, v)
+		if err != nil {
+			return nil, err
+		}
+		lo, lok := jqNumber(f)
+		hi, hok := jqNumber(u)
+		if !lok || !hok {
+			return nil, fmt.Errorf(`range bounds must be numeric`)
+		}
+		var outs []any
+		for n := lo; n < hi; n++ {
+			outs = append(outs, n)
+		}
+		return outs, nil
+	}
+}
@@ -0,0 +1,108 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleThis_QueryJQ() {
+	this := json.This{`{
+		"users": [
+			{"name": "ann", "age": 31, "tags": ["admin"]},
+			{"name": "bob", "age": 25, "tags": []},
+			{"name": "cy", "age": 40}
+		]
+	}`}
+
+	for _, expr := range []string{
+		`.users[0].name`,
+		`[.users[] | select(.age > 30) | .name]`,
+		`.users | map({name, old: (.age >= 30)}) | .[-1]`,
+		`.users | length`,
+		`[.users[].tags // [] | length] | add`,
+		`.users | sort_by(-.age) | map(.name | ascii_upcase) | join(",")`,
+		`.users[1] | if .age < 30 then "young" else "old" end`,
+		`.users[2].tags[0]?`,
+		`.users | map(.name) | .[1:]`,
+		`.nope.deeper`,
+	} {
+		out, err := this.QueryJQ(expr)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Print(out)
+	}
+
+	// Output:
+	// "ann"
+	// [
+	//   "ann",
+	//   "cy"
+	// ]
+	// {
+	//   "name": "cy",
+	//   "old": true
+	// }
+	// 3
+	// 1
+	// "CY,ANN,BOB"
+	// "young"
+	// null
+	// [
+	//   "bob",
+	//   "cy"
+	// ]
+	// null
+}
+
+func ExampleJQ_Select() {
+	var doc any
+	json.Unmarshal([]byte(`{"a":{"x":1,"y":2},"b":[3,4]}`), &doc)
+
+	for _, expr := range []string{
+		`.a | keys`,
+		`.a | to_entries | map(.value * 10)`,
+		`.b[] , .a.x`,
+		`[.. | numbers] | max`,
+		`{(.a | keys[]): .b[0]}`,
+		`.b | has(1), has(5)`,
+		`.a | with_entries(select(.value > 1))`,
+		`.b + [5] - [3]`,
+		`.a.x as $x`,
+		`.b | .[`,
+		`.a[0]`,
+	} {
+		q, err := json.ParseJQ(expr)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		out, err := q.Select(doc)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, o := range out {
+			fmt.Println(json.This{o})
+		}
+	}
+
+	// Output:
+	// ["x","y"]
+	// [10,20]
+	// 3
+	// 4
+	// 1
+	// 4
+	// {"x":3}
+	// {"y":3}
+	// true
+	// false
+	// {"y":2}
+	// [4,5]
+	// jq: variables not supported at 5: ".a.x as $x"
+	// jq: unexpected end at 7: ".b | .["
+	// cannot index object with number
+}
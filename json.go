@@ -1,6 +1,6 @@
 /*
 Package json contains interface specifications for representing any Go
-type as JSON where possible. Using the goprintasjson tool allows for quick code generation of scaffolding to make any Go type easily used as JSON.
+type as JSON where possible. Using the goprintasjson tool (or GeneratePackage for an entire package) allows for quick code generation of scaffolding to make any Go type easily used as JSON.
 */
 package json
 
@@ -9,10 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
-
-	"github.com/rwxrob/to"
-	"github.com/rwxrob/yq"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // AsJSON specifies a type that must support marshaling using the
@@ -37,8 +37,9 @@ import (
 // output makes for more consistent debugging, documentation, and
 // testing.
 //
-// AsJSON implementations must Print and Log the output of String from
-// the same interface.
+// AsJSON implementations must Print the output of String from the same
+// interface. Log should do the same but may summarize big payloads
+// (see MarshalLog) and must return exactly what was logged.
 //
 // MarshalJSON and UnmarshalJSON must be explicitly defined and use the
 // rwxrob/json package to avoid confusion. Use of the helper json.This
@@ -80,40 +81,386 @@ func Escape(in string) string {
 	return out
 }
 
-// Marshal mimics json.Marshal from the encoding/json package without
-// the broken, unnecessary HTML escapes and extraneous newline that the
-// json.Encoder adds. Call this from your own MarshalJSON methods to get
-// JSON rendering that is more readable and compliant with the JSON
-// specification (unless you are using the extremely rare case of
-// dumping that into HTML, for some reason). Note that this cannot be
-// called from any structs MarshalJSON method on itself because it will
-// cause infinite functional recursion. Write a proper MarshalJSON
-// method or create a dummy struct and call json.Marshal on that
-// instead.
-func Marshal(v any) ([]byte, error) {
+// EscapeStrict is the same as Escape but guarantees that the result is
+// always a valid JSON string body that any standard parser accepts and
+// that decodes back to the original string exactly. In addition to
+// what Escape handles, every other control character is escaped (as
+// \u00XX), as are U+2028 and U+2029 (which terminate JavaScript string
+// literals), and every invalid UTF-8 byte is replaced with \ufffd. Use
+// it instead of Escape when the input cannot be trusted.
+func EscapeStrict(in string) string {
+	const hex = `0123456789abcdef`
+	var out strings.Builder
+	out.Grow(len(in) + 2)
+	for i := 0; i < len(in); {
+		r, size := utf8.DecodeRuneInString(in[i:])
+		i += size
+		switch {
+		case r == utf8.RuneError && size == 1:
+			out.WriteString(`\ufffd`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\b':
+			out.WriteString(`\b`)
+		case r == '\f':
+			out.WriteString(`\f`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '"':
+			out.WriteString(`\"`)
+		case r < 0x20:
+			out.WriteString(`\u00`)
+			out.WriteByte(hex[r>>4])
+			out.WriteByte(hex[r&0xF])
+		case r == '\u2028':
+			out.WriteString(`\u2028`)
+		case r == '\u2029':
+			out.WriteString(`\u2029`)
+		default:
+			out.WriteString(in[i-size : i])
+		}
+	}
+	return out.String()
+}
+
+// Unescape reverses Escape (and EscapeStrict) returning the string
+// that the body of a JSON string represents. In addition to \t, \b, \f,
+// \n, \r, \\, \", and \/ every \uXXXX sequence is decoded with UTF-16
+// surrogate pairs combined into a single rune (and any unpaired
+// surrogate replaced with U+FFFD as encoding/json does). An error is
+// returned for any other or incomplete escape.
+func Unescape(in string) (string, error) {
+	i := strings.IndexByte(in, '\\')
+	if i < 0 {
+		return in, nil
+	}
+	var out strings.Builder
+	out.Grow(len(in))
+	out.WriteString(in[:i])
+	for i < len(in) {
+		c := in[i]
+		if c != '\\' {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		if i+1 >= len(in) {
+			return "", fmt.Errorf(`incomplete escape at offset %d`, i)
+		}
+		switch in[i+1] {
+		case 't':
+			out.WriteByte('\t')
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case '\\', '"', '/':
+			out.WriteByte(in[i+1])
+		case 'u':
+			r, ok := unhex4(in[i+2:])
+			if !ok {
+				return "", fmt.Errorf(`invalid \u escape at offset %d`, i)
+			}
+			i += 6
+			if utf16.IsSurrogate(r) {
+				if strings.HasPrefix(in[i:], `\u`) {
+					if r2, ok := unhex4(in[i+2:]); ok {
+						if d := utf16.DecodeRune(r, r2); d != utf8.RuneError {
+							out.WriteRune(d)
+							i += 6
+							continue
+						}
+					}
+				}
+				r = utf8.RuneError
+			}
+			out.WriteRune(r)
+			continue
+		default:
+			return "", fmt.Errorf(`invalid escape \%c at offset %d`, in[i+1], i)
+		}
+		i += 2
+	}
+	return out.String(), nil
+}
+
+// unhex4 returns the rune of the first four hex digits of the string.
+func unhex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range []byte(s[:4]) {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// Marshal mimics json.Marshal from the encoding/json package (observing
+// the InvalidUTF8 policy) without the broken, unnecessary HTML escapes
+// and extraneous newline that the json.Encoder adds. Call this from your
+// own MarshalJSON methods to get JSON rendering that is more readable
+// and compliant with the JSON specification (unless you are using the
+// extremely rare case of dumping that into HTML, for some reason).
+//
+// As with encoding/json, calling Marshal on the value itself from its
+// own MarshalJSON method recurses forever. Use MarshalSelf for that (or
+// convert it to a type without the method as GeneratePackage does).
+//
+// Fields of time.Time (or *time.Time) with the unix, unixmilli, or
+// unixmicro tag option are numbers of seconds, milliseconds, or
+// microseconds since the Unix epoch (instead of RFC 3339 strings) for
+// APIs with numeric timestamps (always, whatever the DefaultEngine).
+// Unmarshal decodes them (including any fraction) as UTC times and
+// still accepts strings:
+//
+//     type Event struct {
+//       Created time.Time  `json:"created,unix"`
+//       Updated *time.Time `json:"updated,unixmilli,omitempty"`
+//     }
+//
+func Marshal(v any) ([]byte, error) { return MarshalWith(DefaultEngine, v) }
+
+// MarshalWith is the same as Marshal but uses the specific Engine
+// passed instead of the DefaultEngine. The Engine is bypassed (in
+// favor of the native encoder of this package) whenever InvalidUTF8 is
+// not UTF8Replace or the type has tag options (such as unix or scale)
+// since no Engine knows about these.
+func MarshalWith(e Engine, v any) (out []byte, err error) {
+	if LogRoutes != nil {
+		defer func() { logMarshal(`marshal`, v, len(out), err) }()
+	}
+	if DefaultMetrics != nil {
+		done := observe(`marshal`, v)
+		defer func() { done(len(out), err) }()
+	}
+	if _, std := e.(StdEngine); std || InvalidUTF8 != UTF8Replace || hasValueOpts(reflect.TypeOf(v)) {
+		buf := new(bytes.Buffer)
+		err := (&encoder{buf: buf, utf8: InvalidUTF8}).value(reflect.ValueOf(v), 0)
+		return buf.Bytes(), err
+	}
+	return marshal(e, v)
+}
+
+// marshal is MarshalWith without any of the package policies applied.
+func marshal(e Engine, v any) ([]byte, error) {
 	buf := new(bytes.Buffer)
-	enc := json.NewEncoder(buf)
+	enc := e.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(v)
-	return []byte(strings.TrimSpace(buf.String())), err
+	return bytes.TrimSpace(buf.Bytes()), err
+}
+
+// MustMarshal is the same as Marshal but panics on any error. Use it
+// only for quick scripts and examples.
+func MustMarshal(v any) []byte {
+	buf, err := Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+// MarshalSelf is the same as Marshal but renders the value itself (its
+// fields, etc.) as if it had no MarshalJSON (or MarshalText) method so
+// that it can be called from that method without recursing forever and
+// with no need for dummy copy structs (or types without the method):
+//
+//     func (u User) MarshalJSON() ([]byte, error) {
+//       u.Password = ""
+//       return json.MarshalSelf(u)
+//     }
+//
+// Only the value passed is affected (any values within it still render
+// with their own methods). The native encoder of this package is always
+// used (whatever the DefaultEngine).
+func MarshalSelf(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := (&encoder{buf: buf, utf8: InvalidUTF8, self: true}).value(reflect.ValueOf(v), 0)
+	return buf.Bytes(), err
 }
 
 // MarshalIndent mimics json.Marshal from the encoding/json package but
-// without the escapes, etc. See Marshal.
+// without the escapes, etc. Any types registered with RegisterCompact
+// are rendered on a single line within the indented output. See
+// Marshal.
 func MarshalIndent(v any, a, b string) ([]byte, error) {
+	return MarshalIndentWith(DefaultEngine, v, a, b)
+}
+
+// MarshalIndentWith is the same as MarshalIndent but uses the specific
+// Engine passed instead of the DefaultEngine. As with MarshalWith, the
+// Engine is bypassed (in favor of the native encoder) whenever the
+// value may contain types registered with RegisterCompact, InvalidUTF8
+// is not UTF8Replace, or the type has tag options.
+func MarshalIndentWith(e Engine, v any, a, b string) (out []byte, err error) {
+	if LogRoutes != nil {
+		defer func() { logMarshal(`marshal`, v, len(out), err) }()
+	}
+	if DefaultMetrics != nil {
+		done := observe(`marshal`, v)
+		defer func() { done(len(out), err) }()
+	}
+	t := reflect.TypeOf(v)
+	if _, std := e.(StdEngine); std || hasCompact(t) || InvalidUTF8 != UTF8Replace ||
+		hasValueOpts(t) {
+		buf := bytes.NewBuffer(make([]byte, 0, SizeHint(v)))
+		enc := &encoder{buf: buf, prefix: a, indent: b, utf8: InvalidUTF8}
+		err := enc.value(reflect.ValueOf(v), 0)
+		return buf.Bytes(), err
+	}
 	buf := new(bytes.Buffer)
-	enc := json.NewEncoder(buf)
+	enc := e.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
 	enc.SetIndent(a, b)
-	err := enc.Encode(v)
-	return []byte(strings.TrimSpace(buf.String())), err
+	err = enc.Encode(v)
+	return bytes.TrimSpace(buf.Bytes()), err
+}
+
+// MarshalHTMLSafe is the explicit opt-in for the rare case when JSON
+// output is to be embedded directly into HTML (inside <script> tags,
+// for example). It escapes <, >, and & as well as U+2028 and U+2029
+// (which are valid JSON but terminate JavaScript string literals) but
+// otherwise matches Marshal exactly (every package policy included).
+// See Marshal.
+func MarshalHTMLSafe(v any) ([]byte, error) {
+	buf, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := new(bytes.Buffer)
+	json.HTMLEscape(out, buf)
+	return out.Bytes(), nil
 }
 
-// Unmarshal mimics json.Unmarshal from the encoding/json package.
+// LogMaxString is the number of bytes after which string values are
+// truncated by MarshalLog (0 for no limit).
+var LogMaxString = 256
+
+// LogMaxItems is the number of items after which arrays (and slices)
+// are elided by MarshalLog (0 for no limit).
+var LogMaxItems = 25
+
+// MarshalLog is the same as Marshal but is meant for logging big
+// payloads so that they remain readable and cheap. Any string value
+// longer than LogMaxString is truncated and marked with the number of
+// bytes dropped ("…(+N bytes)") and any array longer than LogMaxItems
+// has the remaining items replaced with a single string noting how
+// many were elided ("…(+N items)"). Note that anything rendering itself
+// (MarshalJSON, MarshalText) is never truncated.
+func MarshalLog(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	e := &encoder{
+		buf:      buf,
+		maxStr:   LogMaxString,
+		maxItems: LogMaxItems,
+		utf8:     InvalidUTF8,
+	}
+	err := e.value(reflect.ValueOf(v), 0)
+	return buf.Bytes(), err
+}
+
+// Unmarshal mimics json.Unmarshal from the encoding/json package but
+// tolerates a byte order mark (BOM) and UTF-16 and UTF-32 input (see
+// ToUTF8) and observes the InvalidUTF8 policy.
 func Unmarshal(buf []byte, v any) error {
-	return json.Unmarshal(buf, v)
+	return UnmarshalWith(DefaultEngine, buf, v)
+}
+
+// MustUnmarshal is the same as Unmarshal but panics on any error. Use
+// it only for quick scripts and examples.
+func MustUnmarshal(buf []byte, v any) {
+	if err := Unmarshal(buf, v); err != nil {
+		panic(err)
+	}
+}
+
+// UnmarshalWith is the same as Unmarshal but uses the specific Engine
+// passed instead of the DefaultEngine.
+func UnmarshalWith(e Engine, buf []byte, v any) (err error) {
+	if LogRoutes != nil {
+		defer func(n int) { logMarshal(`unmarshal`, v, n, err) }(len(buf))
+	}
+	if DefaultMetrics != nil {
+		done := observe(`unmarshal`, v)
+		defer func(n int) { done(n, err) }(len(buf))
+	}
+	buf, err = ToUTF8(buf)
+	if err != nil {
+		return err
+	}
+	if hasValueOpts(reflect.TypeOf(v)) {
+		if buf, err = untag(buf, reflect.TypeOf(v)); err != nil {
+			return err
+		}
+	}
+	switch InvalidUTF8 {
+	case UTF8Error:
+		if err := checkValid(buf); err != nil {
+			return err
+		}
+	case UTF8Raw:
+		if esc := escapeRaw(buf); esc != nil {
+			if i := rawRangeAt(buf); i >= 0 {
+				return RawRangeError{i}
+			}
+			if err := e.Unmarshal(esc, v); err != nil {
+				return err
+			}
+			unescapeRaw(reflect.ValueOf(v))
+			return nil
+		}
+	}
+	return e.Unmarshal(buf, v)
+}
+
+// As unmarshals the JSON into a new value of the given type and returns
+// it so that a variable does not need to be declared first and passed
+// by pointer:
+//
+//     names, err := json.As[map[string]string](buf)
+//
+func As[T any](buf []byte) (T, error) {
+	var v T
+	err := Unmarshal(buf, &v)
+	return v, err
 }
 
+// UnmarshalAs is the same as As (named to be found next to Unmarshal).
+func UnmarshalAs[T any](buf []byte) (T, error) { return As[T](buf) }
+
+// MustAs is the same as As but panics on any error. Use it only for
+// quick scripts and examples.
+func MustAs[T any](buf []byte) T {
+	v, err := As[T](buf)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Slice unmarshals a JSON array into a new slice of the given type.
+// See As.
+func Slice[T any](buf []byte) ([]T, error) { return As[[]T](buf) }
+
 // This encapsulates anything with the AsJSON interface from this package
 // by simply assigning a new variable with that item as the only value
 // in the structure:
@@ -124,36 +471,73 @@ func Unmarshal(buf []byte, v any) error {
 //
 type This struct{ This any }
 
+var _ AsJSON = (*This)(nil)
+
+// MarshalJSON implements AsJSON rendering only the encapsulated value
+// (not the This struct around it).
+func (s This) MarshalJSON() ([]byte, error) { return s.JSON() }
+
 // UnmarshalJSON implements AsJSON
 func (s *This) UnmarshalJSON(buf []byte) error {
-	return json.Unmarshal(buf, &s.This)
+	return Unmarshal(buf, &s.This)
 }
 
-// JSON implements AsJSON.
-func (s This) JSON() ([]byte, error) { return json.Marshal(s.This) }
+// JSON implements AsJSON using Marshal.
+func (s This) JSON() ([]byte, error) { return Marshal(s.This) }
+
+// MustJSON is the same as JSON but panics on any error. Use it only for
+// quick scripts and examples.
+func (s This) MustJSON() []byte {
+	buf, err := s.JSON()
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
 
-// String implements AsJSON and logs any error.
+// String implements AsJSON and logs any error. Use StringE when the
+// error must be detected.
 func (s This) String() string {
-	byt, err := s.JSON()
+	str, err := s.StringE()
 	if err != nil {
 		log.Print(err)
 	}
-	return string(byt)
+	return str
+}
+
+// StringE is the same as String but returns any error (and an empty
+// string) instead of logging it.
+func (s This) StringE() (string, error) {
+	byt, err := s.JSON()
+	if err != nil {
+		return "", err
+	}
+	return string(byt), nil
 }
 
 // Print implements AsJSON printing with fmt.Println (adding a line
-// return).
+// return). Use PrintE when the error must be detected.
 func (s This) Print() { fmt.Println(s.String()) }
 
-// Log implements AsJSON.
-func (s This) Log() { log.Print(s.String()) }
-
-// Query provides YAML/JSON query responses.
-func (s This) Query(q string) (string, error) {
-	return yq.EvaluateToString(to.String(s.This), q)
+// PrintE is the same as Print but returns any error (printing nothing)
+// instead of logging it.
+func (s This) PrintE() error {
+	str, err := s.StringE()
+	if err != nil {
+		return err
+	}
+	fmt.Println(str)
+	return nil
 }
 
-// QueryPrint prints YAML/JSON query responses.
-func (s This) QueryPrint(q string) error {
-	return yq.Evaluate(to.String(s.This), q)
+// Log implements AsJSON logging the output of MarshalLog so that big
+// payloads are summarized and returning the string that was logged.
+func (s This) Log() string {
+	byt, err := MarshalLog(s.This)
+	if err != nil {
+		log.Print(err)
+	}
+	str := string(byt)
+	log.Print(str)
+	return str
 }
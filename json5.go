@@ -0,0 +1,429 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FromJSON5 returns the JSON5 (json5.org) document as compact JSON so
+// that configuration written by people decodes into the same Go values
+// as Unmarshal. Everything JSON5 adds is accepted: comments, trailing
+// commas, unquoted (identifier) keys, single-quoted strings, strings
+// continued over lines with a backslash, the \x, \v, \0, and \' escapes,
+// hexadecimal numbers, leading or trailing decimal points, and leading
+// plus signs. Infinity and NaN are errors since JSON has no way to
+// write them. Errors have the line and column of the problem.
+//
+//     // config.json5
+//     {
+//       name: 'app',
+//       port: 0x1F90,
+//       ratio: .5,
+//       banner: 'multi \
+//     line',
+//     }
+//
+func FromJSON5(buf []byte) ([]byte, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	p := &json5{buf: buf}
+	if err := p.space(); err != nil {
+		return nil, err
+	}
+	if err := p.value(0); err != nil {
+		return nil, err
+	}
+	if err := p.space(); err != nil {
+		return nil, err
+	}
+	if p.i < len(p.buf) {
+		return nil, p.errorf(`unexpected %q after top-level value`, p.rune())
+	}
+	return p.out.Bytes(), nil
+}
+
+// UnmarshalJSON5 is the same as Unmarshal but for JSON5 (see
+// FromJSON5).
+func UnmarshalJSON5(buf []byte, v any) error {
+	out, err := FromJSON5(buf)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(out, v)
+}
+
+// json5 is the state of FromJSON5 writing JSON as it reads JSON5.
+type json5 struct {
+	buf []byte
+	i   int
+	out bytes.Buffer
+}
+
+// errorf returns the error at the current line and column.
+func (p *json5) errorf(format string, args ...any) error {
+	line := bytes.Count(p.buf[:p.i], []byte{'\n'}) + 1
+	col := p.i - bytes.LastIndexByte(p.buf[:p.i], '\n')
+	return fmt.Errorf(`json5: `+format+` at line %d column %d`, append(args, line, col)...)
+}
+
+// rune returns the current rune (or utf8.RuneError at the end).
+func (p *json5) rune() rune {
+	r, _ := utf8.DecodeRune(p.buf[p.i:])
+	return r
+}
+
+// space skips whitespace (including that of Unicode) and comments.
+func (p *json5) space() error {
+	for p.i < len(p.buf) {
+		switch r, n := utf8.DecodeRune(p.buf[p.i:]); {
+		case r == '\uFEFF' || unicode.IsSpace(r):
+			p.i += n
+		case bytes.HasPrefix(p.buf[p.i:], []byte(`//`)):
+			end := bytes.IndexByte(p.buf[p.i:], '\n')
+			if end < 0 {
+				end = len(p.buf) - p.i
+			}
+			p.i += end
+		case bytes.HasPrefix(p.buf[p.i:], []byte(`/*`)):
+			end := bytes.Index(p.buf[p.i+2:], []byte(`*/`))
+			if end < 0 {
+				return p.errorf(`unterminated comment`)
+			}
+			p.i += 2 + end + 2
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *json5) value(depth int) error {
+	if depth > maxDepth {
+		return p.errorf(`nesting too deep`)
+	}
+	if p.i >= len(p.buf) {
+		return p.errorf(`unexpected end`)
+	}
+	switch c := p.buf[p.i]; {
+	case c == '{':
+		return p.object(depth)
+	case c == '[':
+		return p.array(depth)
+	case c == '"' || c == '\'':
+		s, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.out.WriteString(quote(s))
+		return nil
+	case c == '-' || c == '+' || c == '.' || '0' <= c && c <= '9':
+		return p.number()
+	}
+	start := p.i
+	switch id := p.ident(); id {
+	case `true`, `false`, `null`:
+		p.out.WriteString(id)
+		return nil
+	case `Infinity`, `NaN`:
+		p.i = start
+		return p.errorf(`%s has no JSON equivalent`, id)
+	}
+	p.i = start
+	return p.errorf(`unexpected %q`, p.rune())
+}
+
+func (p *json5) object(depth int) error {
+	p.i++
+	p.out.WriteByte('{')
+	for n := 0; ; n++ {
+		if err := p.space(); err != nil {
+			return err
+		}
+		if p.i < len(p.buf) && p.buf[p.i] == '}' {
+			p.i++
+			p.out.WriteByte('}')
+			return nil
+		}
+		if n > 0 {
+			p.out.WriteByte(',')
+		}
+		var key string
+		if p.i < len(p.buf) && (p.buf[p.i] == '"' || p.buf[p.i] == '\'') {
+			var err error
+			if key, err = p.string(); err != nil {
+				return err
+			}
+		} else if key = p.ident(); key == "" {
+			return p.errorf(`expected key but found %q`, p.rune())
+		}
+		p.out.WriteString(quote(key))
+		if err := p.space(); err != nil {
+			return err
+		}
+		if p.i >= len(p.buf) || p.buf[p.i] != ':' {
+			return p.errorf(`expected : after key %q`, key)
+		}
+		p.i++
+		p.out.WriteByte(':')
+		if err := p.space(); err != nil {
+			return err
+		}
+		if err := p.value(depth + 1); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if p.i < len(p.buf) && p.buf[p.i] == ',' {
+			p.i++
+		} else if p.i >= len(p.buf) || p.buf[p.i] != '}' {
+			return p.errorf(`expected , or } in object`)
+		}
+	}
+}
+
+func (p *json5) array(depth int) error {
+	p.i++
+	p.out.WriteByte('[')
+	for n := 0; ; n++ {
+		if err := p.space(); err != nil {
+			return err
+		}
+		if p.i < len(p.buf) && p.buf[p.i] == ']' {
+			p.i++
+			p.out.WriteByte(']')
+			return nil
+		}
+		if n > 0 {
+			p.out.WriteByte(',')
+		}
+		if err := p.value(depth + 1); err != nil {
+			return err
+		}
+		if err := p.space(); err != nil {
+			return err
+		}
+		if p.i < len(p.buf) && p.buf[p.i] == ',' {
+			p.i++
+		} else if p.i >= len(p.buf) || p.buf[p.i] != ']' {
+			return p.errorf(`expected , or ] in array`)
+		}
+	}
+}
+
+// ident returns the identifier (ECMAScript IdentifierName without
+// escapes) at the current offset (empty if none).
+func (p *json5) ident() string {
+	start := p.i
+	for p.i < len(p.buf) {
+		r, n := utf8.DecodeRune(p.buf[p.i:])
+		if !(r == '$' || r == '_' || unicode.IsLetter(r) ||
+			p.i > start && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) ||
+				unicode.Is(unicode.Mc, r) || unicode.Is(unicode.Pc, r) ||
+				r == '\u200C' || r == '\u200D')) {
+			break
+		}
+		p.i += n
+	}
+	return string(p.buf[start:p.i])
+}
+
+// string returns the content of the quoted string.
+func (p *json5) string() (string, error) {
+	q := p.buf[p.i]
+	p.i++
+	var s strings.Builder
+	for {
+		if p.i >= len(p.buf) {
+			return "", p.errorf(`unterminated string`)
+		}
+		r, n := utf8.DecodeRune(p.buf[p.i:])
+		switch {
+		case r == rune(q):
+			p.i++
+			return s.String(), nil
+		case r == '\n' || r == '\r':
+			return "", p.errorf(`line break in string (end the line with \ to continue)`)
+		case r == '\\':
+			p.i++
+			if err := p.escape(&s); err != nil {
+				return "", err
+			}
+			continue
+		}
+		s.WriteRune(r)
+		p.i += n
+	}
+}
+
+// escape writes the escaped character after the backslash.
+func (p *json5) escape(s *strings.Builder) error {
+	if p.i >= len(p.buf) {
+		return p.errorf(`unterminated string`)
+	}
+	r, n := utf8.DecodeRune(p.buf[p.i:])
+	p.i += n
+	switch r {
+	case 'b':
+		s.WriteByte('\b')
+	case 'f':
+		s.WriteByte('\f')
+	case 'n':
+		s.WriteByte('\n')
+	case 'r':
+		s.WriteByte('\r')
+	case 't':
+		s.WriteByte('\t')
+	case 'v':
+		s.WriteByte('\v')
+	case '0':
+		if p.i < len(p.buf) && '0' <= p.buf[p.i] && p.buf[p.i] <= '9' {
+			return p.errorf(`octal escape`)
+		}
+		s.WriteByte(0)
+	case 'x', 'u':
+		size := 2
+		if r == 'u' {
+			size = 4
+		}
+		code, ok := hexValue(p.buf[p.i:], size)
+		if !ok {
+			return p.errorf(`invalid \%c escape`, r)
+		}
+		p.i += size
+		if r == 'u' && utf16.IsSurrogate(code) && bytes.HasPrefix(p.buf[p.i:], []byte(`\u`)) {
+			if low, ok := hexValue(p.buf[p.i+2:], 4); ok {
+				if combined := utf16.DecodeRune(code, low); combined != utf8.RuneError {
+					p.i += 6
+					code = combined
+				}
+			}
+		}
+		s.WriteRune(code)
+	case '\r':
+		if p.i < len(p.buf) && p.buf[p.i] == '\n' {
+			p.i++
+		}
+	case '\n', '\u2028', '\u2029':
+		// line continuation
+	default:
+		if '1' <= r && r <= '9' {
+			return p.errorf(`octal escape`)
+		}
+		s.WriteRune(r)
+	}
+	return nil
+}
+
+// number writes the JSON5 number as a JSON number.
+func (p *json5) number() error {
+	start := p.i
+	neg := false
+	if c := p.buf[p.i]; c == '+' || c == '-' {
+		neg = c == '-'
+		p.i++
+	}
+	if id := p.ident(); id == `Infinity` || id == `NaN` {
+		p.i = start
+		return p.errorf(`%s has no JSON equivalent`, id)
+	} else if id != "" {
+		p.i = start
+		return p.errorf(`invalid number`)
+	}
+	if p.i+1 < len(p.buf) && p.buf[p.i] == '0' && (p.buf[p.i+1] == 'x' || p.buf[p.i+1] == 'X') {
+		p.i += 2
+		digits := p.i
+		for p.i < len(p.buf) && isHex(p.buf[p.i]) {
+			p.i++
+		}
+		n, ok := new(big.Int).SetString(string(p.buf[digits:p.i]), 16)
+		if !ok {
+			p.i = start
+			return p.errorf(`invalid hexadecimal number`)
+		}
+		if neg {
+			n.Neg(n)
+		}
+		p.out.WriteString(n.String())
+		return nil
+	}
+	digits := func() string {
+		from := p.i
+		for p.i < len(p.buf) && '0' <= p.buf[p.i] && p.buf[p.i] <= '9' {
+			p.i++
+		}
+		return string(p.buf[from:p.i])
+	}
+	whole := digits()
+	frac := ""
+	hasPoint := p.i < len(p.buf) && p.buf[p.i] == '.'
+	if hasPoint {
+		p.i++
+		frac = digits()
+	}
+	if whole == "" && frac == "" || len(whole) > 1 && whole[0] == '0' {
+		p.i = start
+		return p.errorf(`invalid number`)
+	}
+	if whole == "" {
+		whole = `0`
+	}
+	if neg {
+		p.out.WriteByte('-')
+	}
+	p.out.WriteString(whole)
+	if frac != "" {
+		p.out.WriteString(`.` + frac)
+	}
+	if p.i < len(p.buf) && (p.buf[p.i] == 'e' || p.buf[p.i] == 'E') {
+		p.i++
+		sign := ""
+		if p.i < len(p.buf) && (p.buf[p.i] == '+' || p.buf[p.i] == '-') {
+			sign = string(p.buf[p.i])
+			p.i++
+		}
+		exp := digits()
+		if exp == "" {
+			p.i = start
+			return p.errorf(`invalid number`)
+		}
+		p.out.WriteString(`e` + sign + exp)
+	}
+	return nil
+}
+
+// hexValue returns the value of exactly size hexadecimal digits.
+func hexValue(buf []byte, size int) (rune, bool) {
+	if len(buf) < size {
+		return 0, false
+	}
+	var v rune
+	for _, c := range buf[:size] {
+		if !isHex(c) {
+			return 0, false
+		}
+		v = v<<4 | rune(hexDigit(c))
+	}
+	return v, true
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func hexDigit(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c >= 'a':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
@@ -0,0 +1,48 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleFromJSON5() {
+	out, err := json.FromJSON5([]byte(`
+	// config for the app
+	{
+	  name: 'app "one"',
+	  $port: 0x1F90,
+	  ratio: .5,
+	  scale: +2.,
+	  tags: ['a', "b",],
+	  banner: 'multi \
+	line\x21',
+	  "quoted": -1e3, /* done */
+	}`))
+	fmt.Println(string(out), err)
+	// Output:
+	// {"name":"app \"one\"","$port":8080,"ratio":0.5,"scale":2,"tags":["a","b"],"banner":"multi \tline!","quoted":-1e3} <nil>
+}
+
+func ExampleUnmarshalJSON5() {
+	var v struct {
+		Port int  `json:"port"`
+		On   bool `json:"on"`
+	}
+	fmt.Println(json.UnmarshalJSON5([]byte(`{port: 0xff, on: true}`), &v), v)
+
+	for _, in := range []string{
+		`{a: Infinity}`,
+		"{\n  a: 'one\n  two'\n}",
+		`{a: 1 b: 2}`,
+		`[01]`,
+	} {
+		fmt.Println(json.UnmarshalJSON5([]byte(in), &v))
+	}
+	// Output:
+	// <nil> {255 true}
+	// json5: Infinity has no JSON equivalent at line 1 column 5
+	// json5: line break in string (end the line with \ to continue) at line 2 column 10
+	// json5: expected , or } in object at line 1 column 7
+	// json5: invalid number at line 1 column 2
+}
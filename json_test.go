@@ -3,6 +3,9 @@ package json_test
 import (
 	stdjson "encoding/json"
 	"fmt"
+	"log"
+	"os"
+	"time"
 
 	"github.com/rwxrob/fn"
 	json "github.com/rwxrob/json"
@@ -19,6 +22,22 @@ func ExampleEscape() {
 	// <>&\"'\t\b\f\n\r\\\"💢д
 }
 
+func ExampleEscapeStrict() {
+	fmt.Println(json.EscapeStrict("tab\there \x00\x1f \u2028 \xff 💢"))
+	// Output:
+	// tab\there \u0000\u001f \u2028 \ufffd 💢
+}
+
+func ExampleUnescape() {
+	s, err := json.Unescape(`tab\there \"q\" \u00e9 \ud83d\udca2 \ud800`)
+	fmt.Println(s, err)
+	_, err = json.Unescape(`bad \x`)
+	fmt.Println(err)
+	// Output:
+	// tab	here "q" é 💢 � <nil>
+	// invalid escape \x at offset 4
+}
+
 func ExampleMarshal() {
 	m := map[string]string{"<foo>": "&bar"}
 
@@ -42,6 +61,17 @@ func ExampleMarshal() {
 
 }
 
+func ExampleMustMarshal() {
+	fmt.Println(string(json.MustMarshal(map[string]string{"<foo>": "&bar"})))
+
+	defer func() { fmt.Println(recover()) }()
+	json.MustMarshal(func() {})
+
+	// Output:
+	// {"<foo>":"&bar"}
+	// json: unsupported type: func()
+}
+
 func ExampleMarshalIndent() {
 	m := map[string]string{"<foo>": "&bar"}
 
@@ -68,6 +98,33 @@ func ExampleMarshalIndent() {
 	//  }
 }
 
+func ExampleMarshalHTMLSafe() {
+	m := map[string]string{"<foo>": "&bar\u2028"}
+
+	buf, err := json.MarshalHTMLSafe(m)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	type event struct {
+		At   time.Time `json:"at,unix"`
+		Note string    `json:"note"`
+	}
+	buf, err = json.MarshalHTMLSafe(event{time.Unix(100, 0), "a<b"})
+	fmt.Println(string(buf), err)
+
+	defer func(p json.UTF8Policy) { json.InvalidUTF8 = p }(json.InvalidUTF8)
+	json.InvalidUTF8 = json.UTF8Error
+	_, err = json.MarshalHTMLSafe("a\xffb")
+	fmt.Println(err)
+
+	// Output:
+	// {"\u003cfoo\u003e":"\u0026bar\u2028"}
+	// {"at":100,"note":"a\u003cb"} <nil>
+	// invalid UTF-8 at offset 2
+}
+
 func ExampleUnmarshal() {
 	m := new(map[string]string)
 	if err := json.Unmarshal([]byte(`{"<foo>":"&bar"}`), m); err != nil {
@@ -83,6 +140,66 @@ func ExampleUnmarshal() {
 	// &map[<foo>:&bar]
 }
 
+func ExampleMustUnmarshal() {
+	var m map[string]string
+	json.MustUnmarshal([]byte(`{"<foo>":"&bar"}`), &m)
+	fmt.Println(m)
+
+	defer func() { fmt.Println(recover()) }()
+	json.MustUnmarshal([]byte(`{`), &m)
+
+	// Output:
+	// map[<foo>:&bar]
+	// unexpected end of JSON input
+}
+
+func ExampleAs() {
+	m, err := json.As[map[string]int]([]byte(`{"one":1,"two":2}`))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(m)
+
+	_, err = json.As[int]([]byte(`"one"`))
+	fmt.Println(err)
+
+	// Output:
+	// map[one:1 two:2]
+	// json: cannot unmarshal string into Go value of type int
+}
+
+func ExampleUnmarshalAs() {
+	port, err := json.UnmarshalAs[struct{ Port int }]([]byte(`{"Port":80}`))
+	fmt.Println(port.Port, err)
+	_, err = json.UnmarshalAs[int]([]byte(`"eighty"`))
+	fmt.Println(err != nil)
+	// Output:
+	// 80 <nil>
+	// true
+}
+
+func ExampleMustAs() {
+	fmt.Println(json.MustAs[float64]([]byte(`1.5`)))
+
+	defer func() { fmt.Println(recover()) }()
+	json.MustAs[bool]([]byte(`{`))
+
+	// Output:
+	// 1.5
+	// unexpected end of JSON input
+}
+
+func ExampleSlice() {
+	names, err := json.Slice[string]([]byte(`["foo","bar"]`))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(len(names), names)
+
+	// Output:
+	// 2 [foo bar]
+}
+
 func ExampleThis_string() {
 	this := json.This{"foo"}
 	this.Print()
@@ -140,3 +257,99 @@ func ExampleThis_struct() {
 	// Output:
 	// {"Foo":"foo","Slice":["one","two"]}
 }
+
+func ExampleMarshalLog() {
+	defer func(s, i int) { json.LogMaxString, json.LogMaxItems = s, i }(
+		json.LogMaxString, json.LogMaxItems)
+	json.LogMaxString = 10
+	json.LogMaxItems = 3
+
+	data := struct {
+		Text string
+		Nums []int
+	}{"some really long string", make([]int, 10000)}
+
+	buf, err := json.MarshalLog(data)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	// Output:
+	// {"Text":"some reall…(+13 bytes)","Nums":[0,0,0,"…(+9997 items)"]}
+}
+
+func ExampleThis_Log() {
+
+	// adjust log output for testing
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.Flags())
+
+	defer func(i int) { json.LogMaxItems = i }(json.LogMaxItems)
+	json.LogMaxItems = 2
+
+	this := json.This{[]string{"foo", "bar", "baz"}}
+	logged := this.Log()
+	fmt.Println(logged == `["foo","bar","…(+1 items)"]`)
+
+	// Output:
+	// ["foo","bar","…(+1 items)"]
+	// true
+}
+
+func ExampleThis_MustJSON() {
+	this := json.This{[]string{"foo", "bar"}}
+	fmt.Println(string(this.MustJSON()))
+	// Output:
+	// ["foo","bar"]
+}
+
+func ExampleThis_StringE() {
+	this := json.This{"foo"}
+	fmt.Println(this.StringE())
+
+	this.This = make(chan int)
+	str, err := this.StringE()
+	fmt.Printf("%q %v\n", str, err)
+
+	// Output:
+	// "foo" <nil>
+	// "" json: unsupported type: chan int
+}
+
+func ExampleThis_PrintE() {
+	this := json.This{[]int{1, 2}}
+	if err := this.PrintE(); err != nil {
+		fmt.Println(err)
+	}
+
+	this.This = func() {}
+	if err := this.PrintE(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// [1,2]
+	// json: unsupported type: func()
+}
+
+func ExampleThis_MarshalJSON() {
+	data := map[string]any{"this": json.This{[]string{"foo"}}}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	this := new(json.This)
+	if err := json.Unmarshal(buf, this); err != nil {
+		fmt.Println(err)
+	}
+	this.Print()
+
+	// Output:
+	// {"this":["foo"]}
+	// {"this":["foo"]}
+}
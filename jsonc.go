@@ -0,0 +1,91 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// StripJSONC returns a copy of the JSON with comments (// to the end of
+// the line and /* */) and trailing commas (after a value and before a
+// closing ] or }) replaced with spaces so that VS Code style configuration (JSONC) and
+// other documents edited by people decode as JSON. Line breaks are kept
+// and nothing moves so the offsets of any later errors still point to
+// the same place in the original. Only an unterminated /* comment is an
+// error (anything else invalid is left for Unmarshal to report). It is
+// also an OnBody hook.
+func StripJSONC(buf []byte) ([]byte, error) {
+	out := make([]byte, len(buf))
+	copy(out, buf)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+	comma := -1    // offset of a comma that may be trailing
+	value := false // a value just ended (only commas after one can trail)
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+
+		case c == '"':
+			for i++; i < len(out) && out[i] != '"' && out[i] != '\n'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+			comma, value = -1, true
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end < 0 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte(`*/`))
+			if end < 0 {
+				return nil, fmt.Errorf(`unterminated comment at offset %d`, i)
+			}
+			blank(i, i+2+end+2)
+			i += 2 + end + 1
+
+		case c == ',':
+			comma = -1
+			if value {
+				comma = i
+			}
+			value = false
+
+		case c == ']' || c == '}':
+			if comma >= 0 {
+				out[comma] = ' '
+			}
+			comma, value = -1, true
+
+		case c == '[' || c == '{' || c == ':':
+			comma, value = -1, false
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+
+		default:
+			comma, value = -1, true
+		}
+	}
+	return out, nil
+}
+
+// UnmarshalJSONC is the same as Unmarshal but first removes comments
+// and trailing commas (see StripJSONC).
+func UnmarshalJSONC(buf []byte, v any) error {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return err
+	}
+	if buf, err = StripJSONC(buf); err != nil {
+		return err
+	}
+	return Unmarshal(buf, v)
+}
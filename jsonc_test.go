@@ -0,0 +1,46 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleUnmarshalJSONC() {
+	buf := []byte(`// settings.json
+	{
+	  "editor.tabSize": 2, // spaces
+	  /* "editor.fontSize": 14, */
+	  "files.exclude": {
+	    "**/.git": true,
+	    "**/*.tmp": true,
+	  },
+	  "url": "http://example.com/*not-a-comment*/",
+	}`)
+
+	var v map[string]any
+	err := json.UnmarshalJSONC(buf, &v)
+	fmt.Println(err)
+	fmt.Println(json.This{v})
+
+	fmt.Println(json.UnmarshalJSONC([]byte(`{"a":1 /* oops`), &v))
+
+	// only a comma after a value is trailing
+	var list []any
+	fmt.Println(json.UnmarshalJSONC([]byte(`[,]`), &list))
+	fmt.Println(json.UnmarshalJSONC([]byte(`[1,,]`), &list))
+
+	// Output:
+	// <nil>
+	// {"editor.tabSize":2,"files.exclude":{"**/*.tmp":true,"**/.git":true},"url":"http://example.com/*not-a-comment*/"}
+	// unterminated comment at offset 7
+	// invalid character ',' looking for beginning of value
+	// invalid character ',' looking for beginning of value
+}
+
+func ExampleStripJSONC() {
+	out, _ := json.StripJSONC([]byte(`[1, 2, /* three */ ]`))
+	fmt.Printf("%q\n", out)
+	// Output:
+	// "[1, 2              ]"
+}
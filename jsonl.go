@@ -0,0 +1,106 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LineError is returned when a line of JSON Lines cannot be decoded.
+type LineError struct {
+	Line int // starting from 1
+	Err  error
+}
+
+// Error fulfills the error interface.
+func (e *LineError) Error() string { return fmt.Sprintf(`line %d: %v`, e.Line, e.Err) }
+
+// Unwrap returns the original error.
+func (e *LineError) Unwrap() error { return e.Err }
+
+// LineScanner reads JSON Lines (NDJSON) one value at a time like
+// bufio.Scanner, unmarshaling every line (see Unmarshal) into a new T.
+// Blank lines are skipped. Lines may be no longer than QueryMaxLine.
+//
+//     s := json.NewLineScanner[Event](os.Stdin)
+//     for s.Scan() {
+//       handle(s.Value())
+//     }
+//     if err := s.Err(); err != nil {
+//       return err
+//     }
+//
+type LineScanner[T any] struct {
+	scan *bufio.Scanner
+	line int
+	v    T
+	err  error
+}
+
+// NewLineScanner returns a new LineScanner reading from the reader.
+func NewLineScanner[T any](r io.Reader) *LineScanner[T] {
+	scan := bufio.NewScanner(r)
+	scan.Buffer(nil, QueryMaxLine)
+	return &LineScanner[T]{scan: scan}
+}
+
+// Scan reads the next value returning false when there are no more or
+// when there is an error (see Err).
+func (s *LineScanner[T]) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.scan.Scan() {
+		s.line++
+		buf := bytes.TrimSpace(s.scan.Bytes())
+		if len(buf) == 0 {
+			continue
+		}
+		var v T
+		if err := Unmarshal(buf, &v); err != nil {
+			s.err = &LineError{s.line, err}
+			return false
+		}
+		s.v = v
+		return true
+	}
+	if err := s.scan.Err(); err != nil {
+		s.err = &LineError{s.line + 1, err}
+	}
+	return false
+}
+
+// Value returns the value read by the last call to Scan.
+func (s *LineScanner[T]) Value() T { return s.v }
+
+// Line returns the line number (starting from 1) of the last value.
+func (s *LineScanner[T]) Line() int { return s.line }
+
+// Err returns the first error (a *LineError) or nil if there was none.
+func (s *LineScanner[T]) Err() error { return s.err }
+
+// ReadJSONL calls the function with every value of the JSON Lines
+// (see LineScanner) read from the reader stopping at the first error
+// from either. Use DecodeToChan to receive values on a channel instead.
+func ReadJSONL[T any](r io.Reader, fn func(v T) error) error {
+	s := NewLineScanner[T](r)
+	for s.Scan() {
+		if err := fn(s.Value()); err != nil {
+			return &LineError{s.Line(), err}
+		}
+	}
+	return s.Err()
+}
+
+// WriteJSONL writes every item to the writer as JSON Lines, each on its
+// own line (see EncodeLines).
+func WriteJSONL[T any](w io.Writer, items []T) error {
+	return EncodeLines(w, func(yield func(T) bool) {
+		for _, it := range items {
+			if !yield(it) {
+				return
+			}
+		}
+	})
+}
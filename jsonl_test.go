@@ -0,0 +1,56 @@
+package json_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+type event struct {
+	Type string `json:"type"`
+	N    int    `json:"n"`
+}
+
+func ExampleLineScanner() {
+	in := "{\"type\":\"a\",\"n\":1}\n\n{\"type\":\"b\",\"n\":2}\n{\"type\":3}\n"
+	s := json.NewLineScanner[event](strings.NewReader(in))
+	for s.Scan() {
+		fmt.Println(s.Line(), s.Value().Type, s.Value().N)
+	}
+	var lerr *json.LineError
+	fmt.Println(errors.As(s.Err(), &lerr), lerr.Line)
+
+	// Output:
+	// 1 a 1
+	// 3 b 2
+	// true 4
+}
+
+func ExampleReadJSONL() {
+	in := "{\"type\":\"a\",\"n\":1}\n{\"type\":\"b\",\"n\":2}\n"
+	var events []event
+	err := json.ReadJSONL(strings.NewReader(in), func(e event) error {
+		events = append(events, e)
+		return nil
+	})
+	fmt.Println(len(events), err)
+
+	err = json.ReadJSONL(strings.NewReader(in), func(e event) error {
+		return errors.New(`stop`)
+	})
+	fmt.Println(err)
+
+	// Output:
+	// 2 <nil>
+	// line 1: stop
+}
+
+func ExampleWriteJSONL() {
+	json.WriteJSONL(os.Stdout, []event{{"a", 1}, {"<b>", 2}})
+	// Output:
+	// {"type":"a","n":1}
+	// {"type":"<b>","n":2}
+}
@@ -0,0 +1,87 @@
+package json
+
+import (
+	"sort"
+)
+
+// KeyPaths returns every valid key path (in Path notation, sorted and
+// unique) within the document for driving shell completion (of --query
+// flags, for example). Every item of an array is represented by [] so
+// that the paths of all items are merged. Strings and byte slices are
+// assumed to be JSON documents already; anything else is marshaled.
+// Invalid documents have no paths.
+func KeyPaths(doc any) []string {
+	var g any
+	switch v := doc.(type) {
+	case string:
+		Unmarshal([]byte(v), &g)
+	case []byte:
+		Unmarshal(v, &g)
+	default:
+		g, _ = generic(doc)
+	}
+	seen := map[string]bool{}
+	keyPaths(g, Path{}, seen)
+	return sortedKeys(seen)
+}
+
+func keyPaths(v any, p Path, seen map[string]bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, c := range t {
+			kp := append(p[:len(p):len(p)], PathStep{Key: k})
+			seen[kp.String()] = true
+			keyPaths(c, kp, seen)
+		}
+	case []any:
+		ep := append(p[:len(p):len(p)], PathStep{Each: true})
+		for _, c := range t {
+			keyPaths(c, ep, seen)
+		}
+	}
+}
+
+// SchemaKeyPaths is the same as KeyPaths but for every possible key
+// path described by the JSON Schema (following properties, items, and
+// allOf, anyOf, and oneOf) rather than those in a specific document.
+func SchemaKeyPaths(schema []byte) ([]string, error) {
+	var s map[string]any
+	if err := Unmarshal(schema, &s); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	schemaKeyPaths(s, Path{}, seen)
+	return sortedKeys(seen), nil
+}
+
+func schemaKeyPaths(s map[string]any, p Path, seen map[string]bool) {
+	if props, is := s[`properties`].(map[string]any); is {
+		for k, c := range props {
+			kp := append(p[:len(p):len(p)], PathStep{Key: k})
+			seen[kp.String()] = true
+			if cs, is := c.(map[string]any); is {
+				schemaKeyPaths(cs, kp, seen)
+			}
+		}
+	}
+	if items, is := s[`items`].(map[string]any); is {
+		schemaKeyPaths(items, append(p[:len(p):len(p)], PathStep{Each: true}), seen)
+	}
+	for _, k := range []string{`allOf`, `anyOf`, `oneOf`} {
+		subs, _ := s[k].([]any)
+		for _, sub := range subs {
+			if ss, is := sub.(map[string]any); is {
+				schemaKeyPaths(ss, p, seen)
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
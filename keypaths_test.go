@@ -0,0 +1,46 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleKeyPaths() {
+	doc := `{"name":"ann","hosts":[{"ip":"10.0.0.1"},{"ip":"10.0.0.2","dns-name":"b"}],"a b":1}`
+	for _, p := range json.KeyPaths(doc) {
+		fmt.Println(p)
+	}
+	// Output:
+	// ."a b"
+	// .hosts
+	// .hosts[].dns-name
+	// .hosts[].ip
+	// .name
+}
+
+func ExampleSchemaKeyPaths() {
+	schema := `{
+	  "type": "object",
+	  "properties": {
+	    "name": {"type": "string"},
+	    "tags": {"type": "array", "items": {
+	      "properties": {"key": {}, "value": {}}
+	    }}
+	  },
+	  "oneOf": [{"properties": {"email": {}}}]
+	}`
+	paths, err := json.SchemaKeyPaths([]byte(schema))
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	// Output:
+	// .email
+	// .name
+	// .tags
+	// .tags[].key
+	// .tags[].value
+}
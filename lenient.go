@@ -0,0 +1,140 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Coercion is a single value changed by UnmarshalLenient to fit the
+// type of its field.
+type Coercion struct {
+	Path Path `json:"path"`
+	From any  `json:"from"` // as in the JSON
+	To   any  `json:"to"`
+}
+
+// lenientBools are the strings (lowercase) accepted for booleans.
+var lenientBools = map[string]bool{
+	`true`: true, `yes`: true, `y`: true, `on`: true, `1`: true,
+	`false`: false, `no`: false, `n`: false, `off`: false, `0`: false,
+}
+
+// UnmarshalLenient is the same as Unmarshal but first coerces the
+// values of scraped and legacy APIs into the types of the fields (and
+// elements) they decode into, returning every Coercion made (sorted by
+// Path) so that callers can log (or reject) them. Booleans accept
+// "yes", "no", "y", "n", "on", "off", "true", "false", "1", and "0"
+// (ignoring case and surrounding space) as well as the numbers 1 and 0.
+// Numbers (including named numeric enums) accept strings of numbers
+// ("42", " 3.5 "). Anything else is left for Unmarshal to report as
+// usual. Types that unmarshal themselves are never coerced.
+func UnmarshalLenient(buf []byte, v any) ([]Coercion, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var g any
+	if err := dec.Decode(&g); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, Unmarshal(buf, v) // let it report the trailing data
+	}
+	var cs []Coercion
+	g = lenientValue(g, reflect.TypeOf(v), Path{}, &cs, 0)
+	if len(cs) > 0 {
+		out := new(bytes.Buffer)
+		if err := (&encoder{buf: out, utf8: InvalidUTF8}).value(reflect.ValueOf(g), 0); err != nil {
+			return nil, err
+		}
+		buf = out.Bytes()
+	}
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Path.String() < cs[j].Path.String() })
+	return cs, Unmarshal(buf, v)
+}
+
+// lenientValue returns the value (in generic form with json.Number)
+// coerced for the type at the Path adding every Coercion made.
+func lenientValue(v any, t reflect.Type, p Path, cs *[]Coercion, depth int) any {
+	if t == nil || depth > maxDepth {
+		return v
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalType) {
+		return v
+	}
+	switch t.Kind() {
+
+	case reflect.Bool:
+		var b, ok bool
+		switch x := v.(type) {
+		case string:
+			b, ok = lenientBools[strings.ToLower(strings.TrimSpace(x))]
+		case json.Number:
+			b, ok = x == `1`, x == `1` || x == `0`
+		}
+		if ok {
+			*cs = append(*cs, Coercion{p, lenientFrom(v), b})
+			return b
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s, is := v.(string)
+		if !is {
+			break
+		}
+		s = strings.TrimSpace(s)
+		if f, err := strconv.ParseFloat(s, 64); err == nil && validNumber(s) {
+			*cs = append(*cs, Coercion{p, v, f})
+			return json.Number(s)
+		}
+
+	case reflect.Struct:
+		m, is := v.(map[string]any)
+		if !is {
+			break
+		}
+		fields := typeFields(t)
+		for k, fv := range m {
+			if f, ok := fieldNamed(fields, k); ok {
+				m[k] = lenientValue(fv, t.FieldByIndex(f.index).Type, p.Key(k), cs, depth+1)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if list, is := v.([]any); is {
+			for i, item := range list {
+				list[i] = lenientValue(item, t.Elem(), p.Index(i), cs, depth+1)
+			}
+		}
+
+	case reflect.Map:
+		if m, is := v.(map[string]any); is {
+			for k, item := range m {
+				m[k] = lenientValue(item, t.Elem(), p.Key(k), cs, depth+1)
+			}
+		}
+	}
+	return v
+}
+
+// lenientFrom returns the original value for a Coercion (numbers as
+// float64 as with Unmarshal).
+func lenientFrom(v any) any {
+	if n, is := v.(json.Number); is {
+		f, _ := n.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleUnmarshalLenient() {
+	type Level int
+	type Item struct {
+		Active  bool    `json:"active"`
+		Level   Level   `json:"level"`
+		Price   float64 `json:"price"`
+		Flags   []bool  `json:"flags"`
+		Name    string  `json:"name"`
+		Enabled *bool   `json:"enabled"`
+	}
+
+	var it Item
+	cs, err := json.UnmarshalLenient([]byte(`{
+		"active": "Yes",
+		"level": "3",
+		"price": " 9.99 ",
+		"flags": ["on", "off", 1, 0, true],
+		"name": "1",
+		"enabled": "n"
+	}`), &it)
+	fmt.Println(err)
+	fmt.Println(it.Active, it.Level, it.Price, it.Flags, it.Name, *it.Enabled)
+	for _, c := range cs {
+		fmt.Println(c.Path, json.This{c.From}, `->`, json.This{c.To})
+	}
+
+	_, err = json.UnmarshalLenient([]byte(`{"active":"maybe"}`), &it)
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// true 3 9.99 [true false true false true] 1 false
+	// .active "Yes" -> true
+	// .enabled "n" -> false
+	// .flags[0] "on" -> true
+	// .flags[1] "off" -> false
+	// .flags[2] 1 -> true
+	// .flags[3] 0 -> false
+	// .level "3" -> 3
+	// .price " 9.99 " -> 9.99
+	// json: cannot unmarshal string into Go struct field Item.active of type bool
+}
@@ -0,0 +1,104 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LogLevel is the verbosity of a log message (see LogRoutes).
+type LogLevel int
+
+// Log levels from least to most verbose.
+const (
+	LogError LogLevel = iota // failures only
+	LogInfo                  // one line for every request, query, etc.
+	LogDebug                 // everything
+)
+
+// String fulfills the fmt.Stringer interface.
+func (l LogLevel) String() string {
+	switch l {
+	case LogError:
+		return `error`
+	case LogInfo:
+		return `info`
+	}
+	return `debug`
+}
+
+// LogRoute routes the log messages of the package for a subsystem
+// and/or host (empty matches any) at or below the Level to the Writer.
+// The subsystems are:
+//
+//     req      every Fetch (and other requests) with its host
+//     query    every Query (of This)
+//     marshal  every Marshal and Unmarshal (and With variants)
+//
+type LogRoute struct {
+	Subsystem string
+	Host      string
+	Level     LogLevel
+	Writer    io.Writer
+}
+
+// LogRoutes (empty by default, logging nothing) routes every log
+// message of the package to the Writer of the first matching LogRoute
+// so that, for example, verbose HTTP logging can be enabled without
+// drowning in marshal debugging:
+//
+//     json.LogRoutes = []json.LogRoute{
+//       {Subsystem: `req`, Host: `api.example.com`, Level: json.LogDebug, Writer: f},
+//       {Subsystem: `req`, Level: json.LogInfo, Writer: os.Stderr},
+//       {Level: json.LogError, Writer: os.Stderr},
+//     }
+//
+// Messages are written as single lines (subsystem, level, host, and
+// message) without timestamps (wrap the Writer with a log.Logger for
+// those). Like DefaultMetrics, changing it is not safe for concurrent
+// use and should be done once during initialization.
+var LogRoutes []LogRoute
+
+// logMu keeps lines written to the same Writer from interleaving.
+var logMu sync.Mutex
+
+// logEnabled returns true if anything could be logged at the level for
+// the subsystem (to skip building expensive messages).
+func logEnabled(sub string, level LogLevel) bool {
+	for _, r := range LogRoutes {
+		if (r.Subsystem == "" || r.Subsystem == sub) && level <= r.Level {
+			return true
+		}
+	}
+	return false
+}
+
+// logf writes the message to the first LogRoute matching the subsystem
+// and host (if at or below its Level).
+func logf(sub, host string, level LogLevel, format string, args ...any) {
+	for _, r := range LogRoutes {
+		if (r.Subsystem != "" && r.Subsystem != sub) || (r.Host != "" && r.Host != host) {
+			continue
+		}
+		if level > r.Level || r.Writer == nil {
+			return
+		}
+		msg := fmt.Sprintf(format, args...)
+		if host != "" {
+			msg = host + `: ` + msg
+		}
+		logMu.Lock()
+		defer logMu.Unlock()
+		fmt.Fprintf(r.Writer, "%s %s %s\n", sub, level, msg)
+		return
+	}
+}
+
+// logMarshal logs the result of a marshal or unmarshal.
+func logMarshal(op string, v any, n int, err error) {
+	if err != nil {
+		logf(`marshal`, "", LogError, `%s %T: %v`, op, v, err)
+		return
+	}
+	logf(`marshal`, "", LogDebug, `%s %T (%d bytes)`, op, v, n)
+}
@@ -0,0 +1,50 @@
+package json_test
+
+import (
+	"bytes"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"os"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleLogRoutes() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			if r.URL.Path == `/missing` {
+				_http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true}`)
+		}))
+	defer svr.Close()
+
+	reqs := new(bytes.Buffer)
+	defer func() { json.LogRoutes = nil }()
+	json.LogRoutes = []json.LogRoute{
+		{Subsystem: `req`, Host: `127.0.0.1`, Level: json.LogInfo, Writer: reqs},
+		{Subsystem: `marshal`, Level: json.LogError, Writer: os.Stdout},
+		{Level: json.LogDebug, Writer: os.Stdout},
+	}
+
+	var v map[string]any
+	json.Fetch(&json.Request{URL: svr.URL, Into: &v})
+	json.Fetch(&json.Request{URL: svr.URL + `/missing`, Into: &v})
+	json.Marshal(v)
+	json.Marshal(func() {})
+	json.This{v}.Query(`.ok`)
+
+	for _, line := range strings.Split(strings.TrimSpace(reqs.String()), "\n") {
+		fields := strings.Fields(line)
+		fmt.Println(fields[0], fields[1], fields[2], fields[5])
+	}
+
+	// Output:
+	// marshal error marshal func(): json: unsupported type: func()
+	// query debug .ok (5 bytes)
+	// req info 127.0.0.1: 200
+	// req error 127.0.0.1: 404
+}
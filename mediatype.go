@@ -0,0 +1,97 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MediaType is a vendor media type (application/vnd.github.v3+json,
+// for example) used by APIs to version their JSON (see Request.Vendor).
+type MediaType struct {
+	Vendor  string // github
+	Version string // v3 (empty if none)
+	Suffix  string // json
+}
+
+// ParseMediaType parses the vendor media type (ignoring any parameters
+// and case) returning false if not one (such as application/json). The
+// version is the last part of the subtype if it is v followed by
+// a number (v3 or v2.1, for example).
+func ParseMediaType(ctype string) (MediaType, bool) {
+	var m MediaType
+	mt := mediaType(ctype)
+	if !strings.HasPrefix(mt, `application/vnd.`) {
+		return m, false
+	}
+	sub, suffix, _ := strings.Cut(mt[len(`application/vnd.`):], `+`)
+	m.Suffix = suffix
+	m.Vendor = sub
+	if i := strings.LastIndex(sub, `.v`); i > 0 && isVersion(sub[i+2:]) {
+		m.Vendor, m.Version = sub[:i], sub[i+1:]
+	}
+	return m, m.Vendor != ``
+}
+
+// isVersion returns true if the string is a dotted version number
+// (2 or 2.1, for example).
+func isVersion(s string) bool {
+	for _, part := range strings.Split(s, `.`) {
+		if part == `` || strings.Trim(part, `0123456789`) != `` {
+			return false
+		}
+	}
+	return true
+}
+
+// String fulfills the fmt.Stringer interface with the full media type
+// (with json as the suffix unless another is set) or empty if there is
+// no Vendor.
+func (m MediaType) String() string {
+	if m.Vendor == `` {
+		return ``
+	}
+	s := `application/vnd.` + m.Vendor
+	if m.Version != `` {
+		s += `.` + m.Version
+	}
+	suffix := m.Suffix
+	if suffix == `` {
+		suffix = `json`
+	}
+	return s + `+` + suffix
+}
+
+// MediaVersions maps each vendor (github, for example) to the version
+// of its media types wanted by every Request with that Vendor (and no
+// Version of its own) so that the version of each API used is
+// configured (and upgraded) in one place:
+//
+//     json.MediaVersions[`github`] = `v3`
+//
+var MediaVersions = map[string]string{}
+
+// accept returns the Accept header for the vendor media type of the
+// Request (or empty if it has no Vendor).
+func (it *Request) accept() string {
+	if it.Vendor == `` {
+		return ``
+	}
+	m := MediaType{Vendor: it.Vendor, Version: it.Version}
+	if m.Version == `` {
+		m.Version = MediaVersions[it.Vendor]
+	}
+	return m.String()
+}
+
+// into returns the pointer to unmarshal the response body into based
+// on the media type of the response and the Versions of the Request
+// (or Into if none match).
+func (it *Request) into(m MediaType) (any, error) {
+	if v, has := it.Versions[m.Version]; has && m.Vendor != `` {
+		return v, nil
+	}
+	if it.Into == nil && it.Versions != nil {
+		return nil, fmt.Errorf(`unsupported media type version: %q`, m.Version)
+	}
+	return it.Into, nil
+}
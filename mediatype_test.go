@@ -0,0 +1,26 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleParseMediaType() {
+	for _, ctype := range []string{
+		`application/vnd.github.v3+json`,
+		`application/vnd.api.v2.1+json; charset=utf-8`,
+		`application/vnd.oci.image.manifest.v1+json`,
+		`application/vnd.github+json`,
+		`application/json`,
+	} {
+		m, ok := json.ParseMediaType(ctype)
+		fmt.Printf("%v %q %q %q\n", ok, m.Vendor, m.Version, m)
+	}
+	// Output:
+	// true "github" "v3" "application/vnd.github.v3+json"
+	// true "api" "v2.1" "application/vnd.api.v2.1+json"
+	// true "oci.image.manifest" "v1" "application/vnd.oci.image.manifest.v1+json"
+	// true "github" "" "application/vnd.github+json"
+	// false "" "" ""
+}
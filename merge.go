@@ -0,0 +1,125 @@
+package json
+
+import "reflect"
+
+// MergePatch applies the JSON Merge Patch (RFC 7386) to the JSON
+// document returning the new one (compact, keys sorted). Objects of the
+// patch are merged into those of the document (null removing a key)
+// and everything else (arrays included) replaces what was there. See
+// MergePatchKeys to merge arrays of objects item by item instead.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	return MergePatchKeys(doc, patch, nil)
+}
+
+// MergePatchKeys is the same as MergePatch but merges the arrays at the
+// Paths (see ParsePath, with [] for every item of an array) given as
+// keys of the map by the object key given as the value, which is how
+// most APIs model collections:
+//
+//     json.MergePatchKeys(doc, patch, map[string]string{
+//       `.users`:            `id`,
+//       `.teams[].members`:  `name`,
+//     })
+//
+// Every object of the patch array with the key is merged into the
+// object of the document array with the same value (or added to the
+// end if there is none) and removed instead if it also has
+// "$patch":"delete" (as with a Kubernetes strategic merge patch).
+// Every other item of the patch array is added to the end. Items of the
+// document array not in the patch are kept as is.
+func MergePatchKeys(doc, patch []byte, keys map[string]string) ([]byte, error) {
+	var d, p any
+	if err := Unmarshal(doc, &d); err != nil {
+		return nil, err
+	}
+	if err := Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	norm := map[string]string{}
+	for k, v := range keys {
+		kp, err := ParsePath(k)
+		if err != nil {
+			return nil, err
+		}
+		norm[kp.String()] = v
+	}
+	return Marshal(mergePatch(d, p, Path{}, norm))
+}
+
+// mergePatch returns the value (in generic form) at the Path (with []
+// for array items) merged with the patch.
+func mergePatch(v, patch any, at Path, keys map[string]string) any {
+	switch pt := patch.(type) {
+
+	case map[string]any:
+		out := map[string]any{}
+		if vt, is := v.(map[string]any); is {
+			for k, kv := range vt {
+				out[k] = kv
+			}
+		}
+		for k, pv := range pt {
+			if pv == nil {
+				delete(out, k)
+				continue
+			}
+			out[k] = mergePatch(out[k], pv, at.Key(k), keys)
+		}
+		return out
+
+	case []any:
+		key, has := keys[at.String()]
+		vt, is := v.([]any)
+		if !has || !is {
+			return patch
+		}
+		return mergeItems(vt, pt, at.Each(), key, keys)
+
+	}
+	return patch
+}
+
+// mergeItems returns the items merged with those of the patch by the
+// key (see MergePatchKeys).
+func mergeItems(items, patch []any, at Path, key string, keys map[string]string) []any {
+	out := append([]any{}, items...)
+	for _, p := range patch {
+		obj, is := p.(map[string]any)
+		if !is {
+			out = append(out, p)
+			continue
+		}
+		id, has := obj[key]
+		if !has {
+			out = append(out, p)
+			continue
+		}
+		i := -1
+		for n, item := range out {
+			if it, is := item.(map[string]any); is && reflect.DeepEqual(it[key], id) {
+				i = n
+				break
+			}
+		}
+		if obj[`$patch`] == `delete` {
+			if i >= 0 {
+				out = append(out[:i], out[i+1:]...)
+			}
+			continue
+		}
+		if _, has := obj[`$patch`]; has {
+			c := map[string]any{}
+			for k, v := range obj {
+				c[k] = v
+			}
+			delete(c, `$patch`)
+			obj = c
+		}
+		if i < 0 {
+			out = append(out, mergePatch(nil, obj, at, keys))
+			continue
+		}
+		out[i] = mergePatch(out[i], obj, at, keys)
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMergePatch() {
+	doc := []byte(`{"title":"Hello","author":{"given":"Ann","family":"Lee"},"tags":["a","b"]}`)
+	patch := []byte(`{"title":"Hi","author":{"family":null},"tags":["c"],"phone":"555"}`)
+
+	out, err := json.MergePatch(doc, patch)
+	fmt.Println(string(out), err)
+
+	// Output:
+	// {"author":{"given":"Ann"},"phone":"555","tags":["c"],"title":"Hi"} <nil>
+}
+
+func ExampleMergePatchKeys() {
+	doc := []byte(`{"users":[{"id":1,"name":"ann","roles":["a"]},{"id":2,"name":"bob"},{"id":3,"name":"cat"}]}`)
+	patch := []byte(`{"users":[{"id":2,"name":"rob"},{"id":3,"$patch":"delete"},{"id":4,"name":"dan"}]}`)
+
+	out, err := json.MergePatchKeys(doc, patch, map[string]string{`.users`: `id`})
+	fmt.Println(string(out), err)
+
+	out, _ = json.MergePatch(doc, patch)
+	fmt.Println(string(out))
+
+	// Output:
+	// {"users":[{"id":1,"name":"ann","roles":["a"]},{"id":2,"name":"rob"},{"id":4,"name":"dan"}]} <nil>
+	// {"users":[{"id":2,"name":"rob"},{"$patch":"delete","id":3},{"id":4,"name":"dan"}]}
+}
@@ -0,0 +1,151 @@
+package json
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is a single message for any message bus (Kafka, NATS, etc.)
+// carrying an encoded payload. The Content-Type header (if any)
+// determines the Transcoder used to encode and decode the payload
+// (application/json when not set).
+type Message struct {
+	Subject string // topic, subject, or channel
+	Header  map[string]string
+	Data    []byte
+}
+
+// Publisher is implemented by thin adapters for specific message bus
+// clients so that payloads are published with the same struct tags and
+// content types as everything else using this package (see Publish).
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+// Subscriber is implemented by thin adapters for specific message bus
+// clients. Subscribe must call the function for every message received
+// on the subject until the context is done (or the function returns an
+// error) and return only then (see Subscribe).
+type Subscriber interface {
+	Subscribe(ctx context.Context, subject string, fn func(m Message) error) error
+}
+
+// ContentType returns the Content-Type header of the message (or
+// application/json if not set).
+func (m Message) ContentType() string {
+	if ct := m.Header[`Content-Type`]; ct != "" {
+		return ct
+	}
+	return `application/json`
+}
+
+// Publish encodes the value (as application/json) and publishes it to
+// the subject with the Publisher.
+func Publish[T any](ctx context.Context, p Publisher, subject string, v T) error {
+	buf, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	return p.Publish(ctx, Message{
+		Subject: subject,
+		Header:  map[string]string{`Content-Type`: `application/json`},
+		Data:    buf,
+	})
+}
+
+// Subscribe subscribes to the subject with the Subscriber decoding
+// every message (according to its ContentType) into a new T and passing
+// it to the function. Messages that cannot be decoded end the
+// subscription with the error.
+func Subscribe[T any](ctx context.Context, s Subscriber, subject string, fn func(v T) error) error {
+	return s.Subscribe(ctx, subject, func(m Message) error {
+		var v T
+		if err := Decode(m.ContentType(), m.Data, &v); err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+// MemBus is an in-memory Publisher and Subscriber (useful for tests and
+// for decoupling parts of a single program). Messages published with no
+// subscribers are dropped. Every subscriber has its own unbounded
+// queue so publishing never blocks. The zero value is ready to use.
+type MemBus struct {
+	mu   sync.Mutex
+	subs map[string][]*memSub
+}
+
+type memSub struct {
+	mu    sync.Mutex
+	queue []Message
+	ready chan struct{}
+}
+
+// Publish implements Publisher.
+func (b *MemBus) Publish(ctx context.Context, m Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	subs := b.subs[m.Subject]
+	b.mu.Unlock()
+	for _, s := range subs {
+		s.mu.Lock()
+		s.queue = append(s.queue, m)
+		s.mu.Unlock()
+		select {
+		case s.ready <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe implements Subscriber.
+func (b *MemBus) Subscribe(ctx context.Context, subject string, fn func(m Message) error) error {
+	s := &memSub{ready: make(chan struct{}, 1)}
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = map[string][]*memSub{}
+	}
+	b.subs[subject] = append(b.subs[subject], s)
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[subject]
+		for i, x := range subs {
+			if x == s {
+				b.subs[subject] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}()
+
+	for {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+		for _, m := range queue {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.ready:
+		}
+	}
+}
+
+// Subscribed returns the number of active subscriptions to the subject
+// (useful to wait for subscribers to be ready before publishing).
+func (b *MemBus) Subscribed(subject string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[subject])
+}
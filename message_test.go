@@ -0,0 +1,51 @@
+package json_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSubscribe() {
+	type Event struct {
+		ID   int    `json:"id"`
+		Kind string `json:"kind,omitempty"`
+	}
+
+	bus := new(json.MemBus)
+	ctx := context.Background()
+	done := errors.New("done")
+	finished := make(chan error)
+
+	go func() {
+		finished <- json.Subscribe(ctx, bus, "events", func(e Event) error {
+			fmt.Println(json.This{e})
+			if e.ID == 3 {
+				return done
+			}
+			return nil
+		})
+	}()
+	for bus.Subscribed("events") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	json.Publish(ctx, bus, "events", Event{1, "created"})
+	json.Publish(ctx, bus, "other", Event{99, "ignored"})
+	json.Publish(ctx, bus, "events", Event{ID: 2})
+	bus.Publish(ctx, json.Message{
+		Subject: "events",
+		Header:  map[string]string{"Content-Type": "application/vnd.acme.event+json"},
+		Data:    []byte(`{"id":3,"kind":"vendor"}`),
+	})
+	fmt.Println(<-finished == done)
+
+	// Output:
+	// {"id":1,"kind":"created"}
+	// {"id":2}
+	// {"id":3,"kind":"vendor"}
+	// true
+}
@@ -0,0 +1,75 @@
+package json
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives a Sample for every call to Marshal, MarshalIndent,
+// and Unmarshal (and their With variants) when set as the
+// DefaultMetrics so that services can find which payload types
+// dominate serialization cost (by aggregating by Type).
+type Metrics interface {
+	Observe(s Sample)
+}
+
+// MetricsFunc adapts an ordinary function into Metrics.
+type MetricsFunc func(s Sample)
+
+// Observe implements Metrics.
+func (f MetricsFunc) Observe(s Sample) { f(s) }
+
+// Sample is the instrumentation of a single encode or decode.
+type Sample struct {
+	Op       string        // marshal or unmarshal
+	Type     string        // Go type of value (pointer for unmarshal)
+	Duration time.Duration // including the Metrics overhead
+	Bytes    int           // of JSON produced or consumed
+	Allocs   int64         // heap allocations (-1 when not sampled)
+	Err      error
+}
+
+// DefaultMetrics (nil by default) enables instrumentation of every
+// encode and decode (see Metrics). Like DefaultEngine, changing it is
+// not safe for concurrent use and should be done once during
+// initialization.
+var DefaultMetrics Metrics
+
+// MetricsAllocEvery is how often (every Nth call) allocations are
+// counted for a Sample (see runtime.ReadMemStats which briefly stops
+// the world). The count is for the whole process so it is only
+// accurate when nothing else allocates during the call (like
+// testing.AllocsPerRun) and is best aggregated over many samples.
+// Zero never counts allocations.
+var MetricsAllocEvery = 100
+
+var metricsCalls uint64
+
+// observe starts a Sample for the value returning the function to
+// call when done to report it to the DefaultMetrics.
+func observe(op string, v any) func(n int, err error) {
+	m := DefaultMetrics
+	s := Sample{Op: op, Type: `<nil>`, Allocs: -1}
+	if t := reflect.TypeOf(v); t != nil {
+		s.Type = t.String()
+	}
+	var ms runtime.MemStats
+	every := uint64(MetricsAllocEvery)
+	counted := every > 0 && atomic.AddUint64(&metricsCalls, 1)%every == 0
+	if counted {
+		runtime.ReadMemStats(&ms)
+	}
+	before := ms.Mallocs
+	start := time.Now()
+	return func(n int, err error) {
+		s.Duration = time.Since(start)
+		if counted {
+			runtime.ReadMemStats(&ms)
+			s.Allocs = int64(ms.Mallocs - before)
+		}
+		s.Bytes, s.Err = n, err
+		m.Observe(s)
+	}
+}
@@ -0,0 +1,32 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMetrics() {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	json.DefaultMetrics = json.MetricsFunc(func(s json.Sample) {
+		fmt.Println(s.Op, s.Type, s.Bytes, s.Allocs >= 0, s.Duration > 0, s.Err)
+	})
+	json.MetricsAllocEvery = 1
+	defer func() {
+		json.DefaultMetrics = nil
+		json.MetricsAllocEvery = 100
+	}()
+
+	buf, _ := json.Marshal(User{"ann"})
+	var u User
+	json.Unmarshal(buf, &u)
+	json.Unmarshal([]byte(`{`), &u)
+
+	// Output:
+	// marshal json_test.User 14 true true <nil>
+	// unmarshal *json_test.User 14 true true <nil>
+	// unmarshal *json_test.User 1 true true unexpected end of JSON input
+}
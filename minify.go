@@ -0,0 +1,39 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Minify returns the JSON without any insignificant whitespace working
+// on the tokens alone (never unmarshaling) so that numbers keep every
+// digit as written and objects keep their key order (and any duplicate
+// keys) exactly. The input may have a byte order mark or be UTF-16 or
+// UTF-32 (see ToUTF8). Invalid JSON is an error.
+func Minify(buf []byte) ([]byte, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(buf)))
+	if err := json.Compact(out, buf); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Indent is the same as Minify but puts every item of an array or
+// object on a new line beginning with the prefix followed by one or more
+// copies of the indent (depending on its depth) the same as
+// MarshalIndent. Empty arrays and objects stay on one line.
+func Indent(buf []byte, prefix, indent string) ([]byte, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(buf)*2))
+	if err := json.Indent(out, bytes.TrimSpace(buf), prefix, indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMinify() {
+	buf := []byte(`
+	{
+	  "z": 12345678901234567890.000,
+	  "a": [ 1e2, "<&>" , {} ],
+	  "z": null
+	}
+	`)
+
+	out, err := json.Minify(buf)
+	fmt.Println(string(out), err)
+
+	out, err = json.Indent(out, "", "  ")
+	fmt.Println(string(out), err)
+
+	_, err = json.Minify([]byte(`{"a":}`))
+	fmt.Println(err)
+
+	// Output:
+	// {"z":12345678901234567890.000,"a":[1e2,"<&>",{}],"z":null} <nil>
+	// {
+	//   "z": 12345678901234567890.000,
+	//   "a": [
+	//     1e2,
+	//     "<&>",
+	//     {}
+	//   ],
+	//   "z": null
+	// } <nil>
+	// invalid character '}' looking for beginning of value
+}
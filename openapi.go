@@ -0,0 +1,197 @@
+package json
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI is a loaded OpenAPI (3.x) document used to validate
+// responses against the spec (see ResponseSpec).
+type OpenAPI struct {
+	doc   map[string]any
+	bases []string // path prefixes from servers
+}
+
+// LoadOpenAPI loads an OpenAPI document in JSON.
+func LoadOpenAPI(buf []byte) (*OpenAPI, error) {
+	o := new(OpenAPI)
+	if err := Unmarshal(buf, &o.doc); err != nil {
+		return nil, err
+	}
+	if _, is := o.doc[`paths`].(map[string]any); !is {
+		return nil, fmt.Errorf(`no paths in OpenAPI document`)
+	}
+	servers, _ := o.doc[`servers`].([]any)
+	for _, s := range servers {
+		m, _ := s.(map[string]any)
+		raw, _ := m[`url`].(string)
+		if u, err := url.Parse(raw); err == nil {
+			if base := strings.TrimSuffix(u.Path, `/`); base != `` {
+				o.bases = append(o.bases, base)
+			}
+		}
+	}
+	return o, nil
+}
+
+// ResponseSpec (nil by default) validates every Fetch response against
+// the OpenAPI document when set (like TimeOut, it is package-wide and
+// meant for integration tests catching server drift early). The
+// response is still unmarshaled but Fetch returns a *SpecError when it
+// does not match the spec.
+var ResponseSpec *OpenAPI
+
+// SpecError is returned when a response does not match the OpenAPI
+// document.
+type SpecError struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// Error fulfills the error interface.
+func (e *SpecError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Msg
+		if v.Path != `` {
+			msgs[i] = v.Path + `: ` + v.Msg
+		}
+	}
+	return fmt.Sprintf(`%s %s does not match spec: %s`, e.Method, e.URL, strings.Join(msgs, `; `))
+}
+
+// ValidateResponse validates the status, content type, and body of the
+// response to the request method and URL against the spec returning a
+// *SpecError (or nil if valid). Body violations have the path within
+// the body (see Path).
+func (o *OpenAPI) ValidateResponse(method, rawurl string, status int, contentType string, body []byte) error {
+	fail := func(msg string, args ...any) error {
+		return &SpecError{method, rawurl, []SchemaViolation{{Msg: fmt.Sprintf(msg, args...)}}}
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+	op, err := o.operation(strings.ToLower(method), u.Path)
+	if err != nil {
+		return fail(`%v`, err)
+	}
+
+	responses, _ := op[`responses`].(map[string]any)
+	code := strconv.Itoa(status)
+	res, has := responses[code]
+	if !has {
+		res, has = responses[code[:1]+`XX`]
+	}
+	if !has {
+		res, has = responses[`default`]
+	}
+	if !has {
+		return fail(`undocumented status %d`, status)
+	}
+	resm, _ := o.deref(res).(map[string]any)
+
+	content, _ := resm[`content`].(map[string]any)
+	if len(content) == 0 {
+		if len(strings.TrimSpace(string(body))) > 0 {
+			return fail(`undocumented response body`)
+		}
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(contentType)
+	media, has := content[mt]
+	if !has {
+		if i := strings.Index(mt, `/`); i > 0 {
+			media, has = content[mt[:i]+`/*`]
+		}
+	}
+	if !has {
+		media, has = content[`*/*`]
+	}
+	if !has {
+		return fail(`undocumented content type %q`, contentType)
+	}
+	mm, _ := media.(map[string]any)
+	schema, has := mm[`schema`]
+	if !has {
+		return nil
+	}
+
+	var v any
+	if err := Unmarshal(body, &v); err != nil {
+		return fail(`invalid JSON body: %v`, err)
+	}
+	if err := validate(o.doc, schema, v); err != nil {
+		return &SpecError{method, rawurl, err.(*SchemaError).Violations}
+	}
+	return nil
+}
+
+// operation returns the operation object for the method and path
+// matching path templates ({id}) with or without server base paths.
+func (o *OpenAPI) operation(method, path string) (map[string]any, error) {
+	paths := o.doc[`paths`].(map[string]any)
+	candidates := []string{path}
+	for _, base := range o.bases {
+		if strings.HasPrefix(path, base) {
+			candidates = append(candidates, strings.TrimPrefix(path, base))
+		}
+	}
+	tmpls := make([]string, 0, len(paths))
+	for tmpl := range paths {
+		tmpls = append(tmpls, tmpl)
+	}
+	sort.Strings(tmpls) // literal segments sort before {params}
+	for _, c := range candidates {
+		for _, tmpl := range tmpls {
+			item := paths[tmpl]
+			if !pathMatches(tmpl, c) {
+				continue
+			}
+			im, _ := o.deref(item).(map[string]any)
+			if op, is := im[method].(map[string]any); is {
+				return op, nil
+			}
+			return nil, fmt.Errorf(`undocumented method %s for %s`, strings.ToUpper(method), tmpl)
+		}
+	}
+	return nil, fmt.Errorf(`undocumented path %s`, path)
+}
+
+// deref resolves the $ref of the object (if any).
+func (o *OpenAPI) deref(v any) any {
+	m, _ := v.(map[string]any)
+	ref, is := m[`$ref`].(string)
+	if !is {
+		return v
+	}
+	t, err := (&schemaValidator{root: o.doc}).ref(ref)
+	if err != nil {
+		return v
+	}
+	return t
+}
+
+// pathMatches returns true if the path matches the template.
+func pathMatches(tmpl, path string) bool {
+	ts := strings.Split(strings.Trim(tmpl, `/`), `/`)
+	ps := strings.Split(strings.Trim(path, `/`), `/`)
+	if len(ts) != len(ps) {
+		return false
+	}
+	for i, t := range ts {
+		if strings.HasPrefix(t, `{`) && strings.HasSuffix(t, `}`) && ps[i] != `` {
+			continue
+		}
+		if t != ps[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleResponseSpec() {
+	spec, err := json.LoadOpenAPI([]byte(`{
+	  "openapi": "3.0.3",
+	  "servers": [{"url": "https://api.example.com/v1"}],
+	  "paths": {
+	    "/users/{id}": {
+	      "get": {
+	        "responses": {
+	          "200": {
+	            "content": {
+	              "application/json": {
+	                "schema": {"$ref": "#/components/schemas/User"}
+	              }
+	            }
+	          }
+	        }
+	      }
+	    }
+	  },
+	  "components": {
+	    "schemas": {
+	      "User": {
+	        "type": "object",
+	        "required": ["id", "name"],
+	        "properties": {
+	          "id": {"type": "integer"},
+	          "name": {"type": "string"},
+	          "email": {"type": "string", "nullable": true}
+	        }
+	      }
+	    }
+	  }
+	}`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	json.ResponseSpec = spec
+	defer func() { json.ResponseSpec = nil }()
+
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Content-Type`, `application/json; charset=utf-8`)
+			switch r.URL.Path {
+			case `/v1/users/1`:
+				fmt.Fprint(w, `{"id":1,"name":"ann","email":null}`)
+			default:
+				fmt.Fprint(w, `{"id":"2"}`)
+			}
+		}))
+	defer svr.Close()
+
+	var user map[string]any
+	fmt.Println(json.Fetch(&json.Request{URL: svr.URL + `/v1/users/1`, Into: &user}))
+
+	err = json.Fetch(&json.Request{URL: svr.URL + `/v1/users/2`, Into: &user})
+	for _, v := range err.(*json.SpecError).Violations {
+		fmt.Println(v.Path, v.Msg)
+	}
+	fmt.Println(json.Fetch(&json.Request{URL: svr.URL + `/v1/groups`, Into: &user}) != nil)
+
+	// Output:
+	// <nil>
+	// .name required
+	// .id expected integer but got string
+	// true
+}
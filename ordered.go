@@ -0,0 +1,161 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// OrderedObject is a JSON object that keeps its keys in the order they
+// were added (or unmarshaled) so that documents where order matters to
+// humans (configuration files, API payloads, etc.) come back out in
+// exactly the order they went in, which a map never does. Every object
+// within one (at any depth) unmarshals as an *OrderedObject as well
+// (arrays as []any and everything else as with any). The zero value is
+// an empty object ready to use.
+//
+//     var o json.OrderedObject
+//     json.Unmarshal([]byte(`{"name":"web","port":80,"env":{"b":1,"a":2}}`), &o)
+//     o.Set(`port`, 8080)
+//     json.Marshal(o) // {"name":"web","port":8080,"env":{"b":1,"a":2}}
+//
+type OrderedObject struct {
+	keys []string
+	vals map[string]any
+}
+
+// Len returns the number of keys.
+func (o *OrderedObject) Len() int { return len(o.keys) }
+
+// Keys returns (a copy of) the keys in order.
+func (o *OrderedObject) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Get returns the value of the key and whether it has one.
+func (o *OrderedObject) Get(key string) (any, bool) {
+	v, has := o.vals[key]
+	return v, has
+}
+
+// Set sets the value of the key keeping its place if it already has one
+// or adding it to the end if not.
+func (o *OrderedObject) Set(key string, v any) {
+	if o.vals == nil {
+		o.vals = map[string]any{}
+	}
+	if _, has := o.vals[key]; !has {
+		o.keys = append(o.keys, key)
+	}
+	o.vals[key] = v
+}
+
+// Delete removes the key (if any) returning true if it had one.
+func (o *OrderedObject) Delete(key string) bool {
+	if _, has := o.vals[key]; !has {
+		return false
+	}
+	delete(o.vals, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// MarshalJSON fulfills the json.Marshaler interface with the keys in
+// order (and each value marshaled as with Marshal).
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	e := &encoder{buf: &b, utf8: InvalidUTF8}
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(quote(k) + `:`)
+		if err := e.value(reflect.ValueOf(o.vals[k]), 0); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface replacing
+// everything with the keys (and values) of the object in the order
+// they appear. For duplicate keys the last value is kept (in the place
+// of the first).
+func (o *OrderedObject) UnmarshalJSON(buf []byte) error {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf(`cannot unmarshal %v into OrderedObject`, tok)
+	}
+	*o = OrderedObject{}
+	if err := o.decode(dec, 0); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf(`invalid data after top-level value`)
+	}
+	return nil
+}
+
+// decode decodes the rest of an object (after its opening brace).
+func (o *OrderedObject) decode(dec *json.Decoder, depth int) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		v, err := orderedValue(dec, depth+1)
+		if err != nil {
+			return err
+		}
+		o.Set(key, v)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// orderedValue decodes the next value with every object as an
+// *OrderedObject.
+func orderedValue(dec *json.Decoder, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf(`nesting too deep`)
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		o := new(OrderedObject)
+		return o, o.decode(dec, depth)
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			v, err := orderedValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,53 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleOrderedObject() {
+	var o json.OrderedObject
+	err := json.Unmarshal([]byte(`{
+		"name": "web",
+		"port": 80,
+		"env": {"ZED": "z", "ALPHA": "a"},
+		"hosts": [{"b": 1, "a": 2}],
+		"debug": false
+	}`), &o)
+	fmt.Println(err, o.Keys())
+
+	o.Set(`port`, 8080)
+	o.Set(`added`, true)
+	o.Delete(`debug`)
+	env, _ := o.Get(`env`)
+	env.(*json.OrderedObject).Set(`BETA`, `b`)
+
+	buf, _ := json.Marshal(o)
+	fmt.Println(string(buf))
+	buf, _ = json.MarshalIndent(&o, ``, `  `)
+	fmt.Println(string(buf))
+
+	fmt.Println(json.Unmarshal([]byte(`[1]`), &o))
+
+	// Output:
+	// <nil> [name port env hosts debug]
+	// {"name":"web","port":8080,"env":{"ZED":"z","ALPHA":"a","BETA":"b"},"hosts":[{"b":1,"a":2}],"added":true}
+	// {
+	//   "name": "web",
+	//   "port": 8080,
+	//   "env": {
+	//     "ZED": "z",
+	//     "ALPHA": "a",
+	//     "BETA": "b"
+	//   },
+	//   "hosts": [
+	//     {
+	//       "b": 1,
+	//       "a": 2
+	//     }
+	//   ],
+	//   "added": true
+	// }
+	// cannot unmarshal [ into OrderedObject
+}
@@ -0,0 +1,176 @@
+package json
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NextPage returns the Request for the page after the response to the
+// Request (see FetchPages) or nil if there are no more pages.
+type NextPage func(it *Request, res *Response) (*Request, error)
+
+// LinkNext is the NextPage following the Link header (RFC 8288,
+// formerly RFC 5988) of the response with rel="next" (resolved
+// relative to the URL of the Request), which is how GitHub and most
+// other REST APIs paginate.
+func LinkNext(it *Request, res *Response) (*Request, error) {
+	link := linkRel(res.Header, `next`)
+	if link == "" {
+		return nil, nil
+	}
+	base, err := url.Parse(it.URL + `?` + it.Query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	u, err := base.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	c := *it
+	c.Query = u.Query()
+	u.RawQuery = ""
+	c.URL = u.String()
+	return &c, nil
+}
+
+// linkRel returns the URL of the first link of the Link headers with
+// the relation type (or empty if none).
+func linkRel(h http.Header, rel string) string {
+	for _, v := range h.Values(`Link`) {
+		for _, link := range strings.Split(v, `,`) {
+			link = strings.TrimSpace(link)
+			end := strings.Index(link, `>`)
+			if !strings.HasPrefix(link, `<`) || end < 0 {
+				continue
+			}
+			for _, param := range strings.Split(link[end+1:], `;`) {
+				name, val, found := strings.Cut(strings.TrimSpace(param), `=`)
+				if !found || !strings.EqualFold(strings.TrimSpace(name), `rel`) {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(r, rel) {
+						return link[1:end]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// CursorNext returns a NextPage that sets the query string parameter to
+// the cursor selected by the query (a Path or jq expression, see
+// QueryEach) from the response (strings as is, everything else as
+// JSON). There are no more pages when the cursor is missing, null, or
+// empty:
+//
+//     next := json.CursorNext(`.meta.next_cursor`, `cursor`)
+//
+func CursorNext(query, param string) NextPage {
+	return func(it *Request, res *Response) (*Request, error) {
+		sel, err := compileQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := Unmarshal(res.Body, &v); err != nil {
+			return nil, err
+		}
+		vals, err := sel(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(vals) == 0 || vals[0] == nil || vals[0] == "" {
+			return nil, nil
+		}
+		cursor, is := vals[0].(string)
+		if !is {
+			cursor = This{vals[0]}.String()
+		}
+		c := *it
+		c.Query = url.Values{}
+		for k, vs := range it.Query {
+			c.Query[k] = vs
+		}
+		c.Query.Set(param, cursor)
+		return &c, nil
+	}
+}
+
+// FetchPages sends the Request (see FetchResponse) and then the Request
+// for every next page (LinkNext if nil) calling the function with the
+// response of each page (also unmarshaled into Into, if any, as usual)
+// until there are no more pages or the function returns an error
+// (which is returned). The Request is never changed. Requesting the
+// same page twice (a broken cursor, for example) is an error.
+func FetchPages(ctx context.Context, it *Request, next NextPage, fn func(*Response) error) error {
+	if next == nil {
+		next = LinkNext
+	}
+	seen := map[string]bool{}
+	for it != nil {
+		full := it.URL + `?` + it.Query.Encode()
+		if seen[full] {
+			return fmt.Errorf(`page requested twice: %s`, full)
+		}
+		seen[full] = true
+		page := *it
+		res, err := FetchResponse(ctx, &page)
+		if err != nil {
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+		if it, err = next(it, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchAll returns the items of every page (see FetchPages) in order.
+// The items are selected from each page by the query (a Path or jq
+// expression, see QueryEach) which must select a single array (or null
+// for none). With an empty query every page must be an array itself:
+//
+//     repos, err := json.FetchAll[Repo](ctx, &json.Request{
+//       URL: `https://api.github.com/orgs/rwxrob/repos`,
+//     }, ``, nil)
+//
+//     users, err := json.FetchAll[User](ctx, &json.Request{URL: api},
+//       `.data`, json.CursorNext(`.meta.next_cursor`, `cursor`))
+//
+func FetchAll[T any](ctx context.Context, it *Request, items string, next NextPage) ([]T, error) {
+	var all []T
+	err := FetchPages(ctx, it, next, func(res *Response) error {
+		buf := res.Body
+		if items != "" {
+			var v any
+			if err := Unmarshal(buf, &v); err != nil {
+				return err
+			}
+			sel, err := selectOne(items, v)
+			if err != nil {
+				return err
+			}
+			if sel == nil {
+				return nil
+			}
+			if buf, err = Marshal(sel); err != nil {
+				return err
+			}
+		}
+		page, err := As[[]T](buf)
+		if err != nil {
+			return err
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
@@ -0,0 +1,85 @@
+package json_test
+
+import (
+	"context"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleFetchAll() {
+	pages := map[string]string{
+		``:  `[{"name":"a"},{"name":"b"}]`,
+		`2`: `[{"name":"c"}]`,
+		`3`: `[{"name":"d"}]`,
+	}
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch page := r.URL.Query().Get(`page`); page {
+			case ``:
+				w.Header().Set(`Link`, `</repos?page=2>; rel="next", </repos?page=3>; rel="last"`)
+			case `2`:
+				w.Header().Set(`Link`, `</repos?page=1>; rel="prev first", </repos?page=3>; rel="next"`)
+			}
+			fmt.Fprint(w, pages[r.URL.Query().Get(`page`)])
+		}))
+	defer svr.Close()
+
+	type Repo struct{ Name string }
+	repos, err := json.FetchAll[Repo](context.Background(),
+		&json.Request{URL: svr.URL + `/repos`}, ``, nil)
+	fmt.Println(repos, err)
+
+	// Output:
+	// [{a} {b} {c} {d}] <nil>
+}
+
+func ExampleCursorNext() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch r.URL.Query().Get(`cursor`) {
+			case ``:
+				fmt.Fprint(w, `{"data":[1,2],"meta":{"next":"xyz"}}`)
+			case `xyz`:
+				fmt.Fprint(w, `{"data":[3],"meta":{"next":null}}`)
+			}
+		}))
+	defer svr.Close()
+
+	next := json.CursorNext(`.meta.next`, `cursor`)
+	nums, err := json.FetchAll[int](context.Background(),
+		&json.Request{URL: svr.URL}, `.data`, next)
+	fmt.Println(nums, err)
+
+	err = json.FetchPages(context.Background(), &json.Request{URL: svr.URL}, next,
+		func(res *json.Response) error {
+			fmt.Println(string(res.Body))
+			return nil
+		})
+	fmt.Println(err)
+
+	// Output:
+	// [1 2 3] <nil>
+	// {"data":[1,2],"meta":{"next":"xyz"}}
+	// {"data":[3],"meta":{"next":null}}
+	// <nil>
+}
+
+func ExampleFetchPages_loop() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			w.Header().Set(`Link`, `<?page=1>; rel=next`)
+			fmt.Fprint(w, `[]`)
+		}))
+	defer svr.Close()
+
+	n := 0
+	err := json.FetchPages(context.Background(), &json.Request{URL: svr.URL}, nil,
+		func(res *json.Response) error { n++; return nil })
+	fmt.Println(n, err != nil)
+
+	// Output:
+	// 2 true
+}
@@ -0,0 +1,260 @@
+package json
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed simple query path expression using the subset of
+// the jq/yq syntax that only selects values (no functions, pipes,
+// operators, or assignments). It is the lightweight native query
+// engine (see NativeQuery) used unless the full yq engine is wanted
+// (see DefaultQueryEngine) and is always available for simple lookups:
+//
+//     .                  the whole document
+//     .name              key of an object
+//     ."some key"        quoted key of an object
+//     .["some key"]      same
+//     .items[2]          index of an array (negative from end)
+//     .items[]           every item of an array (or value of an object)
+//     .items[].name      key of every item
+//
+type Path []PathStep
+
+// PathStep is a single step of a Path.
+type PathStep struct {
+	Key   string // object key (when not Index and not Each)
+	Index *int   // array index (when not nil)
+	Each  bool   // every item of array or value of object
+}
+
+// String fulfills the fmt.Stringer interface rendering the step as
+// a valid path expression.
+func (s PathStep) String() string {
+	switch {
+	case s.Each:
+		return `[]`
+	case s.Index != nil:
+		return `[` + strconv.Itoa(*s.Index) + `]`
+	case isIdent(s.Key):
+		return `.` + s.Key
+	}
+	return `.` + quote(s.Key)
+}
+
+// String fulfills the fmt.Stringer interface rendering the path as
+// a valid (normalized) path expression.
+func (p Path) String() string {
+	if len(p) == 0 {
+		return `.`
+	}
+	var b strings.Builder
+	for _, s := range p {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// isIdent returns true if the key can be used without quotes.
+func isIdent(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParsePath parses a simple path expression (see Path).
+func ParsePath(expr string) (Path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, `.`) {
+		return nil, fmt.Errorf(`path must begin with a dot: %q`, expr)
+	}
+	path := Path{}
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+
+		case '.':
+			i++
+			if i == len(expr) {
+				if len(path) > 0 {
+					return nil, fmt.Errorf(`trailing dot in path: %q`, expr)
+				}
+				return path, nil
+			}
+			switch {
+			case expr[i] == '[':
+				continue
+			case expr[i] == '"':
+				key, n, err := pathQuoted(expr[i:])
+				if err != nil {
+					return nil, err
+				}
+				path = append(path, PathStep{Key: key})
+				i += n
+			default:
+				n := i
+				for n < len(expr) && expr[n] != '.' && expr[n] != '[' {
+					n++
+				}
+				if !isIdent(expr[i:n]) {
+					return nil, fmt.Errorf(`invalid key %q in path: %q`, expr[i:n], expr)
+				}
+				path = append(path, PathStep{Key: expr[i:n]})
+				i = n
+			}
+
+		case '[':
+			if i+1 < len(expr) && expr[i+1] == '"' {
+				key, n, err := pathQuoted(expr[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				i += n + 1
+				if i >= len(expr) || expr[i] != ']' {
+					return nil, fmt.Errorf(`missing closing bracket in path: %q`, expr)
+				}
+				path = append(path, PathStep{Key: key})
+				i++
+				continue
+			}
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf(`missing closing bracket in path: %q`, expr)
+			}
+			in := expr[i+1 : i+end]
+			i += end + 1
+			if in == "" {
+				path = append(path, PathStep{Each: true})
+				continue
+			}
+			idx, err := strconv.Atoi(in)
+			if err != nil {
+				return nil, fmt.Errorf(`invalid index %q in path: %q`, in, expr)
+			}
+			path = append(path, PathStep{Index: &idx})
+
+		default:
+			return nil, fmt.Errorf(`unexpected %q in path: %q`, expr[i], expr)
+		}
+	}
+	return path, nil
+}
+
+// pathQuoted returns the unquoted key at the beginning of the string
+// and the number of bytes consumed.
+func pathQuoted(s string) (string, int, error) {
+	for n := 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			n++
+		case '"':
+			var key string
+			err := Unmarshal([]byte(s[:n+1]), &key)
+			return key, n + 1, err
+		}
+	}
+	return "", 0, fmt.Errorf(`missing closing quote in path: %q`, s)
+}
+
+// MustParsePath is the same as ParsePath but panics on any error. It
+// is meant for package-level path variables and quick scripts.
+func MustParsePath(expr string) Path {
+	p, err := ParsePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// MarshalText fulfills the encoding.TextMarshaler interface so that
+// a Path marshals as its (normalized) expression.
+func (p Path) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// UnmarshalText fulfills the encoding.TextUnmarshaler interface parsing
+// the expression (see ParsePath).
+func (p *Path) UnmarshalText(text []byte) error {
+	path, err := ParsePath(string(text))
+	if err != nil {
+		return err
+	}
+	*p = path
+	return nil
+}
+
+// Select returns every value matching the path from any value decoded
+// into generic form (map[string]any, []any, etc. as from Unmarshal into
+// an any). Missing keys and indexes select null (nil) just like jq.
+// Stepping into anything that is not an object or array is an error.
+func (p Path) Select(v any) ([]any, error) {
+	cur := []any{v}
+	for _, step := range p {
+		var next []any
+		for _, c := range cur {
+			out, err := step.selectFrom(c)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+// selectFrom applies a single step to a single value.
+func (s PathStep) selectFrom(v any) ([]any, error) {
+	if v == nil {
+		if s.Each {
+			return nil, fmt.Errorf(`cannot iterate over null`)
+		}
+		return []any{nil}, nil
+	}
+	switch t := v.(type) {
+
+	case map[string]any:
+		switch {
+		case s.Each:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, len(keys))
+			for i, k := range keys {
+				out[i] = t[k]
+			}
+			return out, nil
+		case s.Index != nil:
+			return nil, fmt.Errorf(`cannot index object with number`)
+		}
+		return []any{t[s.Key]}, nil
+
+	case []any:
+		switch {
+		case s.Each:
+			return t, nil
+		case s.Index != nil:
+			i := *s.Index
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				return []any{nil}, nil
+			}
+			return []any{t[i]}, nil
+		}
+		return nil, fmt.Errorf(`cannot index array with %q`, s.Key)
+
+	}
+	return nil, fmt.Errorf(`cannot index %T with %v`, v, s)
+}
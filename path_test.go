@@ -0,0 +1,80 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleParsePath() {
+	for _, expr := range []string{
+		`.`,
+		`.name`,
+		`.items[2].name`,
+		`.items[].tags[-1]`,
+		`."some key".x`,
+		`.["some]key"][]`,
+		`name`,
+		`.items[x]`,
+	} {
+		p, err := json.ParsePath(expr)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(p)
+	}
+
+	// Output:
+	// .
+	// .name
+	// .items[2].name
+	// .items[].tags[-1]
+	// ."some key".x
+	// ."some]key"[]
+	// path must begin with a dot: "name"
+	// invalid index "x" in path: ".items[x]"
+}
+
+func ExamplePath_Select() {
+	var doc any
+	json.MustUnmarshal([]byte(`{
+		"items": [
+			{"name": "foo", "tags": ["a", "b"]},
+			{"name": "bar", "tags": ["c"]}
+		]
+	}`), &doc)
+
+	for _, expr := range []string{
+		`.items[].name`,
+		`.items[].tags[-1]`,
+		`.items[5]`,
+		`.missing`,
+		`.items.name`,
+	} {
+		results, err := json.MustParsePath(expr).Select(doc)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(results)
+	}
+
+	// Output:
+	// [foo bar]
+	// [b c]
+	// [<nil>]
+	// [<nil>]
+	// cannot index array with "name"
+}
+
+func ExamplePath_MarshalText() {
+	var got struct{ At json.Path }
+	err := json.Unmarshal([]byte(`{"At":".items[2].\"some key\""}`), &got)
+	fmt.Println(err, len(got.At))
+	buf, _ := json.Marshal(got)
+	fmt.Println(string(buf))
+	// Output:
+	// <nil> 3
+	// {"At":".items[2].\"some key\""}
+}
@@ -0,0 +1,191 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+)
+
+// Key returns a new Path with the object key step added.
+func (p Path) Key(key string) Path {
+	return append(p[:len(p):len(p)], PathStep{Key: key})
+}
+
+// Index returns a new Path with the array index step added.
+func (p Path) Index(i int) Path {
+	return append(p[:len(p):len(p)], PathStep{Index: &i})
+}
+
+// Each returns a new Path with the every item step added.
+func (p Path) Each() Path {
+	return append(p[:len(p):len(p)], PathStep{Each: true})
+}
+
+// SlicePath is a typed path to an array whose items have the typed path
+// P (see GeneratePaths).
+type SlicePath[P any] struct {
+	path Path
+	item func(Path) P
+}
+
+// NewSlicePath returns a SlicePath for the path with the function to
+// create the typed path of its items.
+func NewSlicePath[P any](p Path, item func(Path) P) SlicePath[P] {
+	return SlicePath[P]{p, item}
+}
+
+// Path returns the untyped Path.
+func (s SlicePath[P]) Path() Path { return s.path }
+
+// String fulfills the fmt.Stringer interface.
+func (s SlicePath[P]) String() string { return s.path.String() }
+
+// Index returns the typed path to the item at the index.
+func (s SlicePath[P]) Index(i int) P { return s.item(s.path.Index(i)) }
+
+// Each returns the typed path to every item.
+func (s SlicePath[P]) Each() P { return s.item(s.path.Each()) }
+
+// MapPath is a typed path to an object with arbitrary keys whose values
+// have the typed path P (see GeneratePaths).
+type MapPath[P any] struct {
+	path Path
+	item func(Path) P
+}
+
+// NewMapPath returns a MapPath for the path with the function to create
+// the typed path of its values.
+func NewMapPath[P any](p Path, item func(Path) P) MapPath[P] {
+	return MapPath[P]{p, item}
+}
+
+// Path returns the untyped Path.
+func (m MapPath[P]) Path() Path { return m.path }
+
+// String fulfills the fmt.Stringer interface.
+func (m MapPath[P]) String() string { return m.path.String() }
+
+// Key returns the typed path to the value of the key.
+func (m MapPath[P]) Key(key string) P { return m.item(m.path.Key(key)) }
+
+// Each returns the typed path to every value.
+func (m MapPath[P]) Each() P { return m.item(m.path.Each()) }
+
+// GeneratePaths returns formatted Go source code (for the named
+// package) with compile-time checked path accessors for each of the
+// struct types of the values given (and every struct type they
+// contain) so that field names can never be typos at runtime. Each
+// struct type T gets a TPath type with a method for every field (named
+// the same as the Go field but using its JSON name) and a TPaths
+// variable for the root of the document:
+//
+//     type Order struct {
+//       Items []Item `json:"items"`
+//     }
+//     type Item struct {
+//       Name string `json:"name"`
+//     }
+//
+//     OrderPaths.Items().Index(3).Name().String() // .items[3].name
+//
+// Fields of other types (and those that marshal themselves) are plain
+// Path values. Slices and arrays are SlicePath and maps are MapPath.
+// Every typed path has a Path method to get the Path for Select, etc.
+// Typically called from a small program run by go:generate.
+func GeneratePaths(pkg string, types ...any) ([]byte, error) {
+	g := &pathGen{names: map[reflect.Type]string{}}
+	for _, v := range types {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+			return nil, fmt.Errorf(`not a named struct type: %T`, v)
+		}
+		g.add(t)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by json.GeneratePaths; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import json %q\n\n", `github.com/rwxrob/json`)
+	for i := 0; i < len(g.queue); i++ {
+		g.write(&b, g.queue[i])
+	}
+	return format.Source(b.Bytes())
+}
+
+type pathGen struct {
+	names map[reflect.Type]string
+	queue []reflect.Type
+}
+
+// add queues the struct type (once) returning its path type name.
+func (g *pathGen) add(t reflect.Type) string {
+	if n, has := g.names[t]; has {
+		return n
+	}
+	n := t.Name() + `Path`
+	g.names[t] = n
+	g.queue = append(g.queue, t)
+	return n
+}
+
+// typed returns the typed path type and the expression converting the
+// Path p into it for values of the type.
+func (g *pathGen) typed(t reflect.Type, p string) (string, string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if typeMarshals(t) {
+		return `json.Path`, p
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return `json.Path`, p
+		}
+		n := g.add(t)
+		return n, n + `{` + p + `}`
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return `json.Path`, p // base64 string
+		}
+		it, ix := g.typed(t.Elem(), `p`)
+		return `json.SlicePath[` + it + `]`,
+			`json.NewSlicePath(` + p + `, func(p json.Path) ` + it + ` { return ` + ix + ` })`
+	case reflect.Map:
+		it, ix := g.typed(t.Elem(), `p`)
+		return `json.MapPath[` + it + `]`,
+			`json.NewMapPath(` + p + `, func(p json.Path) ` + it + ` { return ` + ix + ` })`
+	}
+	return `json.Path`, p
+}
+
+// write writes the path type for the struct type and its methods.
+func (g *pathGen) write(b *bytes.Buffer, t reflect.Type) {
+	n := g.names[t]
+	fmt.Fprintf(b, "// %s is the typed path to %s values.\n", n, t.Name())
+	fmt.Fprintf(b, "type %s struct{ path json.Path }\n\n", n)
+	fmt.Fprintf(b, "// %ss is the typed path to a root %s.\n", n, t.Name())
+	fmt.Fprintf(b, "var %ss = %s{json.Path{}}\n\n", n, n)
+	fmt.Fprintf(b, "// Path returns the untyped path.\n")
+	fmt.Fprintf(b, "func (p %s) Path() json.Path { return p.path }\n\n", n)
+	fmt.Fprintf(b, "// String fulfills the fmt.Stringer interface.\n")
+	fmt.Fprintf(b, "func (p %s) String() string { return p.path.String() }\n\n", n)
+
+	used := map[string]bool{`Path`: true, `String`: true}
+	for _, fd := range typeFields(t) {
+		sf := t.FieldByIndex(fd.index)
+		m := sf.Name
+		for used[m] {
+			m += `_`
+		}
+		used[m] = true
+		rt, expr := g.typed(sf.Type, `p.path.Key(`+strconv.Quote(fd.name)+`)`)
+		fmt.Fprintf(b, "// %s returns the typed path to the %s field.\n", m, fd.name)
+		fmt.Fprintf(b, "func (p %s) %s() %s { return %s }\n\n", n, m, rt, expr)
+	}
+}
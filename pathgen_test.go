@@ -0,0 +1,66 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExamplePath_Key() {
+	p := json.Path{}.Key(`items`).Index(3).Key(`some name`)
+	fmt.Println(p)
+	fmt.Println(p.Key(`tags`).Each())
+	// Output:
+	// .items[3]."some name"
+	// .items[3]."some name".tags[]
+}
+
+type Item struct {
+	Name string            `json:"name"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func ExampleGeneratePaths() {
+	src, err := json.GeneratePaths(`orders`, Item{})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(string(src))
+	// Output:
+	// // Code generated by json.GeneratePaths; DO NOT EDIT.
+	//
+	// package orders
+	//
+	// import json "github.com/rwxrob/json"
+	//
+	// // ItemPath is the typed path to Item values.
+	// type ItemPath struct{ path json.Path }
+	//
+	// // ItemPaths is the typed path to a root Item.
+	// var ItemPaths = ItemPath{json.Path{}}
+	//
+	// // Path returns the untyped path.
+	// func (p ItemPath) Path() json.Path { return p.path }
+	//
+	// // String fulfills the fmt.Stringer interface.
+	// func (p ItemPath) String() string { return p.path.String() }
+	//
+	// // Name returns the typed path to the name field.
+	// func (p ItemPath) Name() json.Path { return p.path.Key("name") }
+	//
+	// // Tags returns the typed path to the tags field.
+	// func (p ItemPath) Tags() json.MapPath[json.Path] {
+	// 	return json.NewMapPath(p.path.Key("tags"), func(p json.Path) json.Path { return p })
+	// }
+}
+
+func ExampleSlicePath() {
+	type ItemPath struct{ json.Path }
+	items := json.NewSlicePath(json.Path{}.Key(`items`),
+		func(p json.Path) ItemPath { return ItemPath{p} })
+	fmt.Println(items.Index(3).Key(`name`))
+	fmt.Println(items.Each().Key(`name`))
+	// Output:
+	// .items[3].name
+	// .items[].name
+}
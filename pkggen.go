@@ -0,0 +1,314 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeneratePackage writes a single file (named after the package and
+// ending with _json_gen.go) into the directory of the Go package with
+// everything its exported struct types lack of the AsJSON interface
+// (every method is implemented with this package), a TFromJSON
+// conversion function, and a TSchema constant with the JSON Schema of
+// each type T:
+//
+//     //go:generate go run ./internal/jsongen
+//
+//     // internal/jsongen/main.go
+//     func main() {
+//       if err := json.GeneratePackage(`.`); err != nil {
+//         log.Fatal(err)
+//       }
+//     }
+//
+// Methods (and functions and constants) already declared in the
+// package are left alone, as are generic types. Types embedded in
+// other structs of the package never get MarshalJSON or UnmarshalJSON
+// (since they would be promoted to and used for the embedding struct
+// instead). See VerifyPackage to keep the file in sync.
+func GeneratePackage(dir string) error {
+	file, src, err := generatePackage(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, src, 0644)
+}
+
+// VerifyPackage returns an error if the file written by GeneratePackage
+// is missing or out of date (for tests and continuous integration).
+func VerifyPackage(dir string) error {
+	file, src, err := generatePackage(dir)
+	if err != nil {
+		return err
+	}
+	have, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf(`%s: not generated (see GeneratePackage)`, file)
+	}
+	if !bytes.Equal(have, src) {
+		return fmt.Errorf(`%s: out of date (see GeneratePackage)`, file)
+	}
+	return nil
+}
+
+// pkgGen generates the _json_gen.go file of a package.
+type pkgGen struct {
+	types    map[string]*ast.TypeSpec
+	methods  map[string]map[string]bool // by type
+	names    map[string]bool            // package level functions, etc.
+	embedded map[string]bool            // types embedded in structs
+}
+
+// asJSONMethods are the methods of AsJSON with their implementations
+// (T is replaced by the type name).
+var asJSONMethods = []struct{ name, code string }{
+	{`JSON`, "// JSON implements json.AsJSON.\nfunc (s T) JSON() ([]byte, error) { return json.This{This: s}.JSON() }\n"},
+	{`String`, "// String implements json.AsJSON and fmt.Stringer.\nfunc (s T) String() string { return json.This{This: s}.String() }\n"},
+	{`Print`, "// Print implements json.AsJSON.\nfunc (s T) Print() { json.This{This: s}.Print() }\n"},
+	{`Log`, "// Log implements json.AsJSON.\nfunc (s T) Log() string { return json.This{This: s}.Log() }\n"},
+	{`MarshalJSON`, "// MarshalJSON implements json.AsJSON.\nfunc (s T) MarshalJSON() ([]byte, error) {\n\ttype plain T\n\treturn json.Marshal(plain(s))\n}\n"},
+	{`UnmarshalJSON`, "// UnmarshalJSON implements json.AsJSON.\nfunc (s *T) UnmarshalJSON(buf []byte) error {\n\ttype plain T\n\treturn json.Unmarshal(buf, (*plain)(s))\n}\n"},
+}
+
+// generatePackage returns the name and content of the generated file
+// for the package in the directory.
+func generatePackage(dir string) (string, []byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		n := fi.Name()
+		return !strings.HasSuffix(n, `_test.go`) && !strings.HasSuffix(n, `_json_gen.go`)
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf(`%s: expected one package, found %d`, dir, len(pkgs))
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	g := &pkgGen{
+		types:    map[string]*ast.TypeSpec{},
+		methods:  map[string]map[string]bool{},
+		names:    map[string]bool{},
+		embedded: map[string]bool{},
+	}
+	files := make([]string, 0, len(pkg.Files))
+	for f := range pkg.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	var order []string
+	for _, f := range files {
+		for _, decl := range pkg.Files[f].Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						g.types[s.Name.Name] = s
+						g.names[s.Name.Name] = true
+						order = append(order, s.Name.Name)
+						ast.Inspect(s.Type, func(n ast.Node) bool {
+							if f, is := n.(*ast.Field); is && len(f.Names) == 0 {
+								g.embedded[embeddedName(f.Type)] = true
+							}
+							return true
+						})
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							g.names[n.Name] = true
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					g.names[d.Name.Name] = true
+					continue
+				}
+				t := d.Recv.List[0].Type
+				if star, is := t.(*ast.StarExpr); is {
+					t = star.X
+				}
+				if id, is := t.(*ast.Ident); is {
+					if g.methods[id.Name] == nil {
+						g.methods[id.Name] = map[string]bool{}
+					}
+					g.methods[id.Name][d.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by json.GeneratePackage; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg.Name)
+	fmt.Fprintf(&b, "import json %q\n\n", `github.com/rwxrob/json`)
+	for _, name := range order {
+		spec := g.types[name]
+		if _, is := spec.Type.(*ast.StructType); !is || !ast.IsExported(name) || spec.TypeParams != nil {
+			continue
+		}
+		for _, m := range asJSONMethods {
+			if g.embedded[name] && (m.name == `MarshalJSON` || m.name == `UnmarshalJSON`) {
+				continue
+			}
+			if !g.methods[name][m.name] {
+				b.WriteString(strings.NewReplacer(` T)`, ` `+name+`)`, ` *T)`, ` *`+name+`)`, `plain T`, `plain `+name).Replace(m.code) + "\n")
+			}
+		}
+		if fn := name + `FromJSON`; !g.names[fn] {
+			fmt.Fprintf(&b, "// %s returns the JSON unmarshaled into a new %s.\n", fn, name)
+			fmt.Fprintf(&b, "func %s(buf []byte) (%s, error) { return json.As[%s](buf) }\n\n", fn, name, name)
+		}
+		if c := name + `Schema`; !g.names[c] {
+			schema, err := Marshal(g.schema(spec.Type, map[string]bool{name: true}))
+			if err != nil {
+				return "", nil, err
+			}
+			lit := "`" + string(schema) + "`"
+			if bytes.IndexByte(schema, '`') >= 0 {
+				lit = strconv.Quote(string(schema))
+			}
+			fmt.Fprintf(&b, "// %s is the JSON Schema of %s.\n", c, name)
+			fmt.Fprintf(&b, "const %s = %s\n\n", c, lit)
+		}
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Join(dir, pkg.Name+`_json_gen.go`), src, nil
+}
+
+// schema returns the JSON Schema (in generic form) of the Go type
+// expression. Types of the package are inlined (other than those
+// already being inlined) and those of other packages are anything
+// (except time.Time).
+func (g *pkgGen) schema(expr ast.Expr, seen map[string]bool) map[string]any {
+	switch t := expr.(type) {
+
+	case *ast.Ident:
+		switch t.Name {
+		case `string`:
+			return map[string]any{`type`: `string`}
+		case `bool`:
+			return map[string]any{`type`: `boolean`}
+		case `int`, `int8`, `int16`, `int32`, `int64`, `uint`, `uint8`,
+			`uint16`, `uint32`, `uint64`, `uintptr`, `byte`, `rune`:
+			return map[string]any{`type`: `integer`}
+		case `float32`, `float64`:
+			return map[string]any{`type`: `number`}
+		}
+		spec, has := g.types[t.Name]
+		if !has || seen[t.Name] {
+			return map[string]any{}
+		}
+		seen[t.Name] = true
+		defer delete(seen, t.Name)
+		return g.schema(spec.Type, seen)
+
+	case *ast.StarExpr:
+		return g.schema(t.X, seen)
+
+	case *ast.ArrayType:
+		if id, is := t.Elt.(*ast.Ident); is && (id.Name == `byte` || id.Name == `uint8`) {
+			return map[string]any{`type`: `string`, `contentEncoding`: `base64`}
+		}
+		return map[string]any{`type`: `array`, `items`: g.schema(t.Elt, seen)}
+
+	case *ast.MapType:
+		return map[string]any{`type`: `object`, `additionalProperties`: g.schema(t.Value, seen)}
+
+	case *ast.SelectorExpr:
+		if x, is := t.X.(*ast.Ident); is && x.Name == `time` && t.Sel.Name == `Time` {
+			return map[string]any{`type`: `string`, `format`: `date-time`}
+		}
+
+	case *ast.StructType:
+		props := map[string]any{}
+		var required []string
+		g.fields(t, seen, props, &required)
+		s := map[string]any{`type`: `object`, `properties`: props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s[`required`] = required
+		}
+		return s
+
+	}
+	return map[string]any{}
+}
+
+// fields adds the properties (and required names) of the fields of the
+// struct (including those of embedded structs) as marshaled.
+func (g *pkgGen) fields(st *ast.StructType, seen map[string]bool, props map[string]any, required *[]string) {
+	for _, f := range st.Fields.List {
+		tag := ``
+		if f.Tag != nil {
+			tag, _ = strconv.Unquote(f.Tag.Value)
+		}
+		name, opts, _ := strings.Cut(reflect.StructTag(tag).Get(`json`), `,`)
+		if name == `-` && opts == `` {
+			continue
+		}
+		if len(f.Names) == 0 && name == `` {
+			et := f.Type
+			if star, is := et.(*ast.StarExpr); is {
+				et = star.X
+			}
+			if id, is := et.(*ast.Ident); is && !seen[id.Name] {
+				if spec, has := g.types[id.Name]; has {
+					if est, is := spec.Type.(*ast.StructType); is {
+						seen[id.Name] = true
+						g.fields(est, seen, props, required)
+						delete(seen, id.Name)
+						continue
+					}
+				}
+			}
+		}
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(embeddedName(f.Type))}
+		}
+		for _, n := range names {
+			if !ast.IsExported(n.Name) {
+				continue
+			}
+			key := name
+			if key == `` {
+				key = n.Name
+			}
+			props[key] = g.schema(f.Type, seen)
+			if !strings.Contains(`,`+opts+`,`, `,omitempty,`) {
+				*required = append(*required, key)
+			}
+		}
+	}
+}
+
+// embeddedName returns the field name of an embedded type.
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ``
+}
@@ -0,0 +1,110 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleGeneratePackage() {
+	dir, _ := os.MkdirTemp("", "pkggen")
+	defer os.RemoveAll(dir)
+	src := `package shop
+
+import "time"
+
+type Base struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Order struct {
+	Base
+	Items   []Item    ` + "`json:\"items\"`" + `
+	Placed  time.Time ` + "`json:\"placed\"`" + `
+	Note    *string   ` + "`json:\"note,omitempty\"`" + `
+	secret  string
+}
+
+type Item struct {
+	SKU string
+	Qty int ` + "`json:\"qty\"`" + `
+}
+
+func (i Item) String() string { return i.SKU }
+`
+	os.WriteFile(filepath.Join(dir, "shop.go"), []byte(src), 0644)
+
+	fmt.Println(json.VerifyPackage(dir) != nil)
+	fmt.Println(json.GeneratePackage(dir))
+	fmt.Println(json.VerifyPackage(dir))
+
+	gen, _ := os.ReadFile(filepath.Join(dir, "shop_json_gen.go"))
+	for _, line := range strings.Split(string(gen), "\n") {
+		if strings.HasPrefix(line, "func") || strings.HasPrefix(line, "const") {
+			fmt.Println(line)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "more.go"), []byte("package shop\n\ntype Cart struct{}\n"), 0644)
+	fmt.Println(json.VerifyPackage(dir) != nil)
+
+	// Output:
+	// true
+	// <nil>
+	// <nil>
+	// func (s Base) JSON() ([]byte, error) { return json.This{This: s}.JSON() }
+	// func (s Base) String() string { return json.This{This: s}.String() }
+	// func (s Base) Print() { json.This{This: s}.Print() }
+	// func (s Base) Log() string { return json.This{This: s}.Log() }
+	// func BaseFromJSON(buf []byte) (Base, error) { return json.As[Base](buf) }
+	// const BaseSchema = `{"properties":{"id":{"type":"string"}},"required":["id"],"type":"object"}`
+	// func (s Order) JSON() ([]byte, error) { return json.This{This: s}.JSON() }
+	// func (s Order) String() string { return json.This{This: s}.String() }
+	// func (s Order) Print() { json.This{This: s}.Print() }
+	// func (s Order) Log() string { return json.This{This: s}.Log() }
+	// func (s Order) MarshalJSON() ([]byte, error) {
+	// func (s *Order) UnmarshalJSON(buf []byte) error {
+	// func OrderFromJSON(buf []byte) (Order, error) { return json.As[Order](buf) }
+	// const OrderSchema = `{"properties":{"id":{"type":"string"},"items":{"items":{"properties":{"SKU":{"type":"string"},"qty":{"type":"integer"}},"required":["SKU","qty"],"type":"object"},"type":"array"},"note":{"type":"string"},"placed":{"format":"date-time","type":"string"}},"required":["id","items","placed"],"type":"object"}`
+	// func (s Item) JSON() ([]byte, error) { return json.This{This: s}.JSON() }
+	// func (s Item) Print() { json.This{This: s}.Print() }
+	// func (s Item) Log() string { return json.This{This: s}.Log() }
+	// func (s Item) MarshalJSON() ([]byte, error) {
+	// func (s *Item) UnmarshalJSON(buf []byte) error {
+	// func ItemFromJSON(buf []byte) (Item, error) { return json.As[Item](buf) }
+	// const ItemSchema = `{"properties":{"SKU":{"type":"string"},"qty":{"type":"integer"}},"required":["SKU","qty"],"type":"object"}`
+	// true
+}
+
+func TestGeneratePackage_vet(t *testing.T) {
+	if testing.Short() {
+		t.Skip(`runs go vet`)
+	}
+	gobin, err := exec.LookPath(`go`)
+	if err != nil {
+		t.Skip(`go not found`)
+	}
+	root, _ := filepath.Abs(`.`)
+	sum, _ := os.ReadFile(filepath.Join(root, `go.sum`))
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, `go.mod`), []byte("module shop\n\ngo 1.18\n\n"+
+		"require github.com/rwxrob/json v0.0.0\n\n"+
+		"replace github.com/rwxrob/json => "+root+"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, `go.sum`), sum, 0644)
+	os.WriteFile(filepath.Join(dir, `shop.go`), []byte("package shop\n\n"+
+		"type Item struct {\n\tSKU string `json:\"sku\"`\n}\n"), 0644)
+	if err := json.GeneratePackage(dir); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(gobin, `vet`, `./...`)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), `GOFLAGS=-mod=mod`)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet of generated code failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,115 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a JSON Pointer (RFC 6901) such as /items/0/name (with ~1
+// for slash and ~0 for tilde within keys) for simple lookups and
+// changes without unmarshaling into structs or queries (see Path). The
+// empty Pointer is the entire document.
+//
+//     name, err := json.Pointer(`/items/0/name`).Resolve(buf)
+//     buf, err = json.Pointer(`/items/-`).Set(buf, item)
+//
+type Pointer string
+
+// Tokens returns the unescaped reference tokens of the Pointer.
+func (p Pointer) Tokens() ([]string, error) {
+	if p == `` {
+		return nil, nil
+	}
+	if !strings.HasPrefix(string(p), `/`) {
+		return nil, fmt.Errorf(`invalid JSON pointer: %q`, string(p))
+	}
+	toks := strings.Split(string(p)[1:], `/`)
+	for i, tok := range toks {
+		for n := 0; n < len(tok); n++ {
+			if tok[n] == '~' && (n+1 == len(tok) || tok[n+1] != '0' && tok[n+1] != '1') {
+				return nil, fmt.Errorf(`invalid escape in JSON pointer: %q`, string(p))
+			}
+		}
+		toks[i] = pointerUnescaper.Replace(tok)
+	}
+	return toks, nil
+}
+
+// Resolve returns the value (in generic form) at the Pointer within the
+// JSON document or an error if there is none.
+func (p Pointer) Resolve(buf []byte) (any, error) {
+	var v any
+	if err := Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	return p.resolve(v)
+}
+
+// resolve returns the value at the Pointer within the generic value.
+func (p Pointer) resolve(cur any) (any, error) {
+	toks, err := p.Tokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, tok := range toks {
+		switch t := cur.(type) {
+		case map[string]any:
+			v, has := t[tok]
+			if !has {
+				return nil, fmt.Errorf(`%s: no key %s`, p, quote(tok))
+			}
+			cur = v
+		case []any:
+			n, err := strconv.Atoi(tok)
+			if err != nil || n < 0 || tok != strconv.Itoa(n) {
+				return nil, fmt.Errorf(`%s: invalid array index %s`, p, quote(tok))
+			}
+			if n >= len(t) {
+				return nil, fmt.Errorf(`%s: index %d out of range`, p, n)
+			}
+			cur = t[n]
+		default:
+			return nil, fmt.Errorf(`%s: cannot look up %s in %s`, p, quote(tok), jsonType(cur))
+		}
+	}
+	return cur, nil
+}
+
+// Set returns the JSON document (compact, keys sorted) with the value
+// at the Pointer replaced by the value given or added if there is none
+// (- or the index just past the end of an array appends to it). The
+// parent must already exist.
+func (p Pointer) Set(buf []byte, v any) ([]byte, error) {
+	d := new(Document)
+	if err := Unmarshal(buf, &d.root); err != nil {
+		return nil, err
+	}
+	if _, err := p.Tokens(); err != nil {
+		return nil, err
+	}
+	op := `add`
+	if _, err := p.resolve(d.root); err == nil {
+		op = `replace`
+	}
+	if _, _, err := d.apply(PatchOp{Op: op, Path: string(p), Value: v}); err != nil {
+		return nil, err
+	}
+	return Marshal(d.root)
+}
+
+// Delete returns the JSON document (compact, keys sorted) without the
+// value at the Pointer, which must exist.
+func (p Pointer) Delete(buf []byte) ([]byte, error) {
+	d := new(Document)
+	if err := Unmarshal(buf, &d.root); err != nil {
+		return nil, err
+	}
+	if _, err := p.resolve(d.root); err != nil {
+		return nil, err
+	}
+	if _, _, err := d.apply(PatchOp{Op: `remove`, Path: string(p)}); err != nil {
+		return nil, err
+	}
+	return Marshal(d.root)
+}
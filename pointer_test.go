@@ -0,0 +1,45 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExamplePointer() {
+	doc := []byte(`{"items":[{"name":"a"},{"name":"b"}],"a/b":{"m~n":1}}`)
+
+	v, err := json.Pointer(`/items/1/name`).Resolve(doc)
+	fmt.Println(v, err)
+	v, err = json.Pointer(`/a~1b/m~0n`).Resolve(doc)
+	fmt.Println(v, err)
+	_, err = json.Pointer(`/items/2`).Resolve(doc)
+	fmt.Println(err)
+	_, err = json.Pointer(`/items/x`).Resolve(doc)
+	fmt.Println(err)
+	_, err = json.Pointer(`/a~2`).Resolve(doc)
+	fmt.Println(err)
+
+	doc, err = json.Pointer(`/items/0/name`).Set(doc, `z`)
+	fmt.Println(string(doc), err)
+	doc, err = json.Pointer(`/items/-`).Set(doc, map[string]any{"name": "c"})
+	fmt.Println(string(doc), err)
+	doc, err = json.Pointer(`/new`).Set(doc, true)
+	fmt.Println(string(doc), err)
+	doc, err = json.Pointer(`/a~1b`).Delete(doc)
+	fmt.Println(string(doc), err)
+	_, err = json.Pointer(`/nope`).Delete(doc)
+	fmt.Println(err)
+
+	// Output:
+	// b <nil>
+	// 1 <nil>
+	// /items/2: index 2 out of range
+	// /items/x: invalid array index "x"
+	// invalid escape in JSON pointer: "/a~2"
+	// {"a/b":{"m~n":1},"items":[{"name":"z"},{"name":"b"}]} <nil>
+	// {"a/b":{"m~n":1},"items":[{"name":"z"},{"name":"b"},{"name":"c"}]} <nil>
+	// {"a/b":{"m~n":1},"items":[{"name":"z"},{"name":"b"},{"name":"c"}],"new":true} <nil>
+	// {"items":[{"name":"z"},{"name":"b"},{"name":"c"}],"new":true} <nil>
+	// /nope: no key "nope"
+}
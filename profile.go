@@ -0,0 +1,183 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// ProfileDistinct is the most distinct values counted for each field
+// by Profile (to bound memory). Fields with more are reported as
+// having at least this many (see FieldProfile.Distinct).
+var ProfileDistinct = 10000
+
+// DatasetProfile is the result of Profile.
+type DatasetProfile struct {
+	Records int             `json:"records"`
+	Fields  []*FieldProfile `json:"fields"` // sorted by Path
+}
+
+// FieldProfile is the profile of every value at a single Path of the
+// records (see Profile). Items of arrays are all at the same Path
+// (.tags[], for example). Min, Max, and Avg are only set if there are
+// numbers. Distinct counts distinct strings, numbers, and booleans
+// only (not null or arrays and objects) and is DistinctCapped if it
+// reached ProfileDistinct.
+type FieldProfile struct {
+	Path           Path           `json:"path"`
+	Count          int            `json:"count"`   // values (including null)
+	Records        int            `json:"records"` // records with any value
+	Types          map[string]int `json:"types"`   // count of each type
+	NullRate       float64        `json:"nullRate"`
+	Min            *float64       `json:"min,omitempty"`
+	Max            *float64       `json:"max,omitempty"`
+	Avg            *float64       `json:"avg,omitempty"`
+	Distinct       int            `json:"distinct"`
+	DistinctCapped bool           `json:"distinctCapped,omitempty"`
+
+	sum    float64
+	values map[string]bool
+	last   int // record last counted
+}
+
+// Profile reads every record of a dataset (the items of a top-level
+// array or values of JSON Lines) and returns the profile of every
+// field for a quick overview of data quality before writing structs or
+// schemas: the type distribution (with integer distinct from number as
+// in JSON Schema), null rate, minimum, maximum, and average of the
+// numbers, and cardinality. Fields of nested objects and items of
+// arrays are profiled as well at their own Path. Records that are not
+// objects are profiled at the top-level Path (.).
+//
+//     p, err := json.Profile(file)
+//     fmt.Print(p.Table())
+//
+func Profile(r io.Reader) (*DatasetProfile, error) {
+	items, err := newItemReader(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &DatasetProfile{}
+	fields := map[string]*FieldProfile{}
+	for {
+		raw, err := items.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf(`record %d: %w`, p.Records, err)
+		}
+		p.Records++
+		if m, is := v.(map[string]any); is {
+			for k, fv := range m {
+				profileValue(fields, Path{}.Key(k), fv, p.Records, 0)
+			}
+			continue
+		}
+		profileValue(fields, Path{}, v, p.Records, 0)
+	}
+	for _, f := range fields {
+		if f.Count > 0 {
+			f.NullRate = float64(f.Types[`null`]) / float64(f.Count)
+		}
+		if n := f.Types[`integer`] + f.Types[`number`]; n > 0 {
+			avg := f.sum / float64(n)
+			f.Avg = &avg
+		}
+		f.Distinct = len(f.values)
+		f.DistinctCapped = f.Distinct >= ProfileDistinct
+		f.values = nil
+		p.Fields = append(p.Fields, f)
+	}
+	sort.Slice(p.Fields, func(i, j int) bool {
+		return p.Fields[i].Path.String() < p.Fields[j].Path.String()
+	})
+	return p, nil
+}
+
+// profileValue adds the value (of the record) at the Path.
+func profileValue(fields map[string]*FieldProfile, p Path, v any, record, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	key := p.String()
+	f, has := fields[key]
+	if !has {
+		f = &FieldProfile{Path: p, Types: map[string]int{}, values: map[string]bool{}}
+		fields[key] = f
+	}
+	f.Count++
+	if f.last != record {
+		f.Records++
+		f.last = record
+	}
+	typ := jsonType(v)
+	f.Types[typ]++
+	switch t := v.(type) {
+	case float64:
+		if f.Min == nil {
+			lo, hi := t, t
+			f.Min, f.Max = &lo, &hi
+		}
+		*f.Min, *f.Max = math.Min(*f.Min, t), math.Max(*f.Max, t)
+		f.sum += t
+	case map[string]any:
+		for k, fv := range t {
+			profileValue(fields, p.Key(k), fv, record, depth+1)
+		}
+		return
+	case []any:
+		for _, item := range t {
+			profileValue(fields, p.Each(), item, record, depth+1)
+		}
+		return
+	case nil:
+		return
+	}
+	if len(f.values) < ProfileDistinct {
+		f.values[typ+`:`+fmt.Sprint(v)] = true
+	}
+}
+
+// Table returns the profile as a plain text table (one line for every
+// field) for terminals and logs:
+//
+//     PATH    COUNT  NULL%  TYPES                  MIN  MAX  AVG   DISTINCT
+//     .age    3      33.3   integer:2 null:1       30   41   35.5  2
+//     .name   3      0.0    string:3                              3
+//
+func (p *DatasetProfile) Table() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tCOUNT\tNULL%\tTYPES\tMIN\tMAX\tAVG\tDISTINCT")
+	num := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'g', 6, 64)
+	}
+	for _, f := range p.Fields {
+		var types []string
+		for t, n := range f.Types {
+			types = append(types, t+`:`+strconv.Itoa(n))
+		}
+		sort.Strings(types)
+		distinct := strconv.Itoa(f.Distinct)
+		if f.DistinctCapped {
+			distinct += `+`
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\n",
+			f.Path, f.Count, f.NullRate*100, strings.Join(types, ` `),
+			num(f.Min), num(f.Max), num(f.Avg), distinct)
+	}
+	w.Flush()
+	return b.String()
+}
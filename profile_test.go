@@ -0,0 +1,32 @@
+package json_test
+
+import (
+	"fmt"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleProfile() {
+	data := `
+	{"name":"ann","age":30,"tags":["a","b"]}
+	{"name":"bob","age":41.5,"tags":[]}
+	{"name":"cy","age":null,"tags":["a"],"admin":true}
+	`
+	p, err := json.Profile(strings.NewReader(data))
+	fmt.Println(err, p.Records)
+	fmt.Print(p.Table())
+
+	age := p.Fields[1]
+	fmt.Println(age.Path, age.Records, *age.Avg, age.NullRate)
+
+	// Output:
+	// <nil> 3
+	// PATH     COUNT  NULL%  TYPES                      MIN  MAX   AVG    DISTINCT
+	// .admin   1      0.0    boolean:1                                    1
+	// .age     3      33.3   integer:1 null:1 number:1  30   41.5  35.75  2
+	// .name    3      0.0    string:3                                     3
+	// .tags    3      0.0    array:3                                      0
+	// .tags[]  3      0.0    string:3                                     2
+	// .age 3 35.75 0.3333333333333333
+}
@@ -0,0 +1,83 @@
+package json
+
+import (
+	"io"
+	"time"
+)
+
+// Progress is reported to OnProgress while reading a Fetch response or
+// decoding with DecodeToChan so that long transfers can show progress
+// instead of appearing hung until TimeOut.
+type Progress struct {
+	URL   string // of request (empty when decoding from a reader)
+	Bytes int64  // read so far
+	Total int64  // expected bytes (Content-Length) or -1 if unknown
+	Items int    // decoded so far (DecodeToChan only)
+	Done  bool   // true once for the last report (even on error)
+}
+
+// OnProgress (nil by default) is called with the Progress of every
+// Fetch and DecodeToChan at most once every ProgressEvery (and always
+// when done). It is called from the goroutine doing the reading
+// (DecodeToChan has its own) and must return quickly. Like TimeOut,
+// it is package-wide and should be set once during initialization
+// (before drawing a progress bar or spinner, for example).
+//
+//     json.OnProgress = func(p json.Progress) {
+//       fmt.Fprintf(os.Stderr, "\r%d/%d bytes", p.Bytes, p.Total)
+//     }
+//
+var OnProgress func(p Progress)
+
+// ProgressEvery is the minimum time between OnProgress reports.
+var ProgressEvery = 100 * time.Millisecond
+
+// progressReader counts the bytes (and items) read reporting them to
+// OnProgress (if set when created).
+type progressReader struct {
+	r    io.Reader
+	fn   func(Progress)
+	p    Progress
+	last time.Time
+}
+
+func newProgressReader(r io.Reader, url string, total int64) *progressReader {
+	return &progressReader{
+		r:    r,
+		fn:   OnProgress,
+		p:    Progress{URL: url, Total: total},
+		last: time.Now(),
+	}
+}
+
+// Read implements io.Reader.
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.Bytes += int64(n)
+	pr.report(false)
+	return n, err
+}
+
+// item counts a decoded item.
+func (pr *progressReader) item() {
+	pr.p.Items++
+	pr.report(false)
+}
+
+// done reports the final Progress.
+func (pr *progressReader) done() {
+	pr.p.Done = true
+	pr.report(true)
+}
+
+func (pr *progressReader) report(force bool) {
+	if pr.fn == nil {
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(pr.last) < ProgressEvery {
+		return
+	}
+	pr.last = now
+	pr.fn(pr.p)
+}
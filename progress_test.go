@@ -0,0 +1,35 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleOnProgress() {
+	defer func() { json.OnProgress = nil }()
+	json.OnProgress = func(p json.Progress) {
+		if p.Done {
+			fmt.Println(p.URL != "", p.Bytes, p.Total, p.Items)
+		}
+	}
+
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprint(w, `{"some":"thing"}`)
+		}))
+	defer svr.Close()
+	var v map[string]any
+	json.Fetch(&json.Request{URL: svr.URL, Into: &v})
+
+	items, _ := json.DecodeToChan[int](strings.NewReader(`[1, 2, 3]`))
+	for range items {
+	}
+
+	// Output:
+	// true 16 16 0
+	// false 9 -1 3
+}
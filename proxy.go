@@ -0,0 +1,162 @@
+package json
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Proxy is an http.Handler for thin JSON-shaping gateways. It fetches
+// the JSON response from the upstream (see Request) and streams it back
+// to the client transformed item by item: every item of a top-level
+// array (or every value of JSON Lines) is passed through the Query (if
+// any) and then the Transform (if any). Items are read from upstream
+// only as fast as the client accepts them (back-pressure) so that huge
+// responses never need to fit in memory. Top-level arrays are written
+// as arrays (application/json) and everything else as JSON Lines
+// (application/x-ndjson).
+//
+//     http.Handle(`/users`, &json.Proxy{
+//       URL:   `https://api.example.com`,
+//       Query: `.name`,
+//     })
+//
+// Errors before anything has been written (from the upstream request,
+// for example) are written with status 502 (Bad Gateway) as JSON (see
+// ErrorValue). Errors after that can only end the response early.
+type Proxy struct {
+
+	// URL is the base URL of the upstream to which the path and query
+	// of every incoming request are appended.
+	URL string
+
+	// Request (if set) returns the upstream Request for the incoming
+	// request instead of using URL.
+	Request func(r *http.Request) *Request
+
+	// Query is a Path or jq expression (see QueryEach) applied to every
+	// item. Items may produce no results (filtering them out) or many.
+	Query string
+
+	// Transform is applied to every item (in generic form) after the
+	// Query and returns the items to write in its place (if any).
+	Transform func(v any) ([]any, error)
+
+	once  sync.Once
+	query func(any) ([]any, error)
+	qerr  error
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.once.Do(func() {
+		if p.Query != "" {
+			p.query, p.qerr = compileQuery(p.Query)
+		}
+	})
+	if p.qerr != nil {
+		p.fail(w, p.qerr)
+		return
+	}
+
+	var up *Request
+	if p.Request != nil {
+		up = p.Request(r)
+	} else {
+		up = &Request{
+			URL:   strings.TrimSuffix(p.URL, `/`) + r.URL.Path,
+			Query: r.URL.Query(),
+		}
+	}
+	res, done, err := up.do(r.Context())
+	if err != nil {
+		p.fail(w, err)
+		return
+	}
+	defer done()
+
+	items, err := newItemReader(res.Body)
+	if err != nil {
+		p.fail(w, timeOutError(err))
+		return
+	}
+	if items.array {
+		w.Header().Set(`Content-Type`, `application/json`)
+		io.WriteString(w, `[`)
+	} else {
+		w.Header().Set(`Content-Type`, `application/x-ndjson`)
+	}
+	flusher, _ := w.(http.Flusher)
+	n := 0
+	for {
+		raw, err := items.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return // incomplete output signals failure
+		}
+		var v any
+		if err := Unmarshal(raw, &v); err != nil {
+			return
+		}
+		out, err := p.apply(v)
+		if err != nil {
+			return
+		}
+		for _, o := range out {
+			buf, err := Marshal(o)
+			if err != nil {
+				return
+			}
+			if items.array && n > 0 {
+				buf = append([]byte{','}, buf...)
+			}
+			if !items.array {
+				buf = append(buf, '\n')
+			}
+			if _, err := w.Write(buf); err != nil {
+				return
+			}
+			n++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if items.array {
+		io.WriteString(w, `]`)
+	}
+}
+
+// apply applies the Query and Transform to the item.
+func (p *Proxy) apply(v any) ([]any, error) {
+	out := []any{v}
+	if p.query != nil {
+		var err error
+		if out, err = p.query(v); err != nil {
+			return nil, err
+		}
+	}
+	if p.Transform == nil {
+		return out, nil
+	}
+	var all []any
+	for _, o := range out {
+		t, err := p.Transform(o)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, t...)
+	}
+	return all, nil
+}
+
+// fail writes the error as JSON with status 502.
+func (p *Proxy) fail(w http.ResponseWriter, err error) {
+	w.Header().Set(`Content-Type`, `application/json`)
+	w.WriteHeader(http.StatusBadGateway)
+	buf, _ := Marshal(ErrorValue(err))
+	w.Write(append(buf, '\n'))
+}
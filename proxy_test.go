@@ -0,0 +1,42 @@
+package json_test
+
+import (
+	"fmt"
+	"io"
+	_http "net/http"
+	ht "net/http/httptest"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleProxy() {
+
+	upstream := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprint(w, `[{"name":"ann","age":30},{"name":"bob","age":12},{"name":"cy","age":44}]`)
+		}))
+	defer upstream.Close()
+
+	proxy := ht.NewServer(&json.Proxy{
+		URL:   upstream.URL,
+		Query: `select(.age >= 18)`,
+		Transform: func(v any) ([]any, error) {
+			return []any{v.(map[string]any)["name"]}, nil
+		},
+	})
+	defer proxy.Close()
+
+	res, err := _http.Get(proxy.URL + `/users`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	fmt.Println(res.Header.Get(`Content-Type`))
+	fmt.Println(string(body))
+
+	// Output:
+	// application/json
+	// ["ann","cy"]
+}
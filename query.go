@@ -0,0 +1,313 @@
+package json
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// QueryEngine evaluates the query expressions of This.Query and
+// QueryWith (other than jq, see QueryOptions.JQ). The parsed form of
+// every source is cached (see QueryCacheSize) and so must never be
+// modified by Query.
+type QueryEngine interface {
+	Parse(src string) (docs any, err error)
+	Query(docs any, q string, o QueryOptions) (string, error)
+}
+
+// DefaultQueryEngine is the QueryEngine used by This.Query and
+// QueryWith. The default NativeQuery only supports Path expressions and
+// JSON documents. The full yq engine (with its huge transitive
+// dependency tree) is opt-in from its own package so that it is never
+// pulled into anything that does not import it:
+//
+//     import "github.com/rwxrob/json/yq"
+//
+//     json.DefaultQueryEngine = yq.Engine{}
+//
+// Changing it is not safe for concurrent use and should be done once
+// during initialization.
+var DefaultQueryEngine QueryEngine = NativeQuery{}
+
+// QueryFormat is the output encoding of query results.
+type QueryFormat int
+
+const (
+	QueryYAML QueryFormat = iota // YAML (the default)
+	QueryJSON                    // JSON (one result per line unless indented)
+	QueryRaw                     // scalars as is, everything else as JSON
+)
+
+// QueryOptions are the output options for QueryWith. The zero value
+// matches the output of Query: YAML, indented by 2 spaces, with scalars
+// unquoted.
+type QueryOptions struct {
+	Format QueryFormat
+	Indent int  // spaces (0 is 2 for YAML and compact for JSON)
+	Quote  bool // keep the quotes around YAML string scalars
+	JQ     bool // the expression is jq (not yq) syntax (see JQ)
+}
+
+// QueryCacheSize is the number of most recently queried documents to
+// keep in their parsed form so that successive Query and QueryPrint
+// calls against the same document (interactive sessions, for example)
+// do not parse the entire document again for every query. Documents
+// are cached by their source (JSON or YAML) so any mutation of the
+// queried value naturally invalidates its cached form. Only parsing is
+// saved: any value other than a string or []byte is still marshaled
+// (see Marshal) for every query to get that source (which is how
+// mutation is detected), so marshal it once instead when querying it
+// repeatedly:
+//
+//     buf, _ := json.Marshal(v)
+//     doc := json.This{This: buf}
+//     doc.Query(`.a`)
+//     doc.Query(`.b`)
+//
+// Set to 0 to disable caching.
+var QueryCacheSize = 8
+
+type queryDoc struct {
+	kind   string
+	src    string
+	parsed any
+}
+
+var queryCache struct {
+	sync.Mutex
+	docs []queryDoc // most recent last
+}
+
+// cachedDoc returns the parsed form (of the given kind) of the
+// YAML/JSON source from the cache (parsing and caching it if not
+// already cached). The parsed form must never be modified.
+func cachedDoc(kind, src string, parse func(string) (any, error)) (any, error) {
+	queryCache.Lock()
+	defer queryCache.Unlock()
+
+	for i, d := range queryCache.docs {
+		if d.kind == kind && d.src == src {
+			queryCache.docs = append(queryCache.docs[:i], queryCache.docs[i+1:]...)
+			queryCache.docs = append(queryCache.docs, d)
+			return d.parsed, nil
+		}
+	}
+
+	parsed, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if QueryCacheSize > 0 {
+		if len(queryCache.docs) >= QueryCacheSize {
+			n := len(queryCache.docs) - QueryCacheSize + 1
+			queryCache.docs = queryCache.docs[n:]
+		}
+		queryCache.docs = append(queryCache.docs, queryDoc{kind, src, parsed})
+	}
+	return parsed, nil
+}
+
+// querySource returns the YAML/JSON document source to be queried.
+// Strings and byte slices are assumed to already be YAML or JSON
+// documents. Anything else is marshaled (every time, see
+// QueryCacheSize).
+func (s This) querySource() (string, error) {
+	switch v := s.This.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	buf, err := Marshal(s.This)
+	return string(buf), err
+}
+
+// Query provides YAML/JSON query responses from the
+// DefaultQueryEngine. The parsed form of the document is cached (see
+// QueryCacheSize). See QueryWith for other output formats.
+func (s This) Query(q string) (string, error) {
+	out, err := s.QueryWith(q, QueryOptions{})
+	if LogRoutes != nil {
+		if err != nil {
+			logf(`query`, "", LogError, `%s: %v`, q, err)
+		} else {
+			logf(`query`, "", LogDebug, `%s (%d bytes)`, q, len(out))
+		}
+	}
+	return out, err
+}
+
+// QueryWith is the same as Query but with specific output options so
+// that results can be passed directly to other functions (Unmarshal,
+// for example) rather than depending on the defaults of the
+// DefaultQueryEngine.
+func (s This) QueryWith(q string, o QueryOptions) (string, error) {
+	if o.JQ {
+		return s.queryJQ(q, o)
+	}
+	e := DefaultQueryEngine
+	src, err := s.querySource()
+	if err != nil {
+		return "", err
+	}
+	docs, err := cachedDoc(fmt.Sprintf(`%T`, e), src, e.Parse)
+	if err != nil {
+		return "", err
+	}
+	return e.Query(docs, q, o)
+}
+
+// QueryPrint prints YAML/JSON query responses.
+func (s This) QueryPrint(q string) error {
+	out, err := s.Query(q)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// MustQuery is the same as Query but panics on any error. Use it only
+// for quick scripts and examples.
+func (s This) MustQuery(q string) string {
+	out, err := s.Query(q)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// write renders a single query result.
+func (o QueryOptions) write(out *strings.Builder, v any) error {
+	switch o.Format {
+
+	case QueryRaw:
+		if s, is := v.(string); is {
+			out.WriteString(s + "\n")
+			return nil
+		}
+		fallthrough
+
+	case QueryJSON:
+		var buf []byte
+		var err error
+		if o.Indent > 0 {
+			buf, err = MarshalIndent(v, "", strings.Repeat(" ", o.Indent))
+		} else {
+			buf, err = Marshal(v)
+		}
+		if err != nil {
+			return err
+		}
+		out.Write(buf)
+		out.WriteByte('\n')
+		return nil
+
+	}
+
+	indent := o.Indent
+	if indent == 0 {
+		indent = 2
+	}
+	if s, is := v.(string); is {
+		if o.Quote {
+			s = quote(s)
+		}
+		out.WriteString(s + "\n")
+		return nil
+	}
+	writeYAML(out, v, strings.Repeat(" ", indent), 0)
+	out.WriteByte('\n')
+	return nil
+}
+
+// writeYAML writes a minimal block-style YAML rendering of a generic
+// decoded JSON value.
+func writeYAML(out *strings.Builder, v any, indent string, depth int) {
+	pre := strings.Repeat(indent, depth)
+	switch t := v.(type) {
+
+	case map[string]any:
+		if len(t) == 0 {
+			out.WriteString(`{}`)
+			return
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i > 0 {
+				out.WriteString("\n" + pre)
+			}
+			out.WriteString(yamlScalar(k) + `:`)
+			if isBlock(t[k]) {
+				out.WriteString("\n" + pre + indent)
+				writeYAML(out, t[k], indent, depth+1)
+				continue
+			}
+			out.WriteByte(' ')
+			writeYAML(out, t[k], indent, depth+1)
+		}
+
+	case []any:
+		if len(t) == 0 {
+			out.WriteString(`[]`)
+			return
+		}
+		for i, item := range t {
+			if i > 0 {
+				out.WriteString("\n" + pre)
+			}
+			out.WriteString(`- `)
+			writeYAML(out, item, indent, depth+1)
+		}
+
+	case string:
+		out.WriteString(yamlScalar(t))
+
+	case nil:
+		out.WriteString(`null`)
+
+	default:
+		buf, _ := Marshal(t)
+		out.Write(buf)
+	}
+}
+
+// isBlock returns true if the value is a non-empty object or array.
+func isBlock(v any) bool {
+	switch t := v.(type) {
+	case map[string]any:
+		return len(t) > 0
+	case []any:
+		return len(t) > 0
+	}
+	return false
+}
+
+// yamlScalar returns the string as a plain YAML scalar if it is safe to
+// do so or as a double-quoted (JSON) string if not.
+func yamlScalar(s string) string {
+	if s == "" || s != strings.TrimSpace(s) ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "~", "yes", "no", "on", "off", "y", "n":
+		return quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return quote(s)
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return quote(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// QueryResult is a single value matched by QueryEach along with where
+// it came from. Err is set (and Value is nil) for any file that could
+// not be read or line that could not be decoded or queried.
+type QueryResult struct {
+	File  string
+	Line  int
+	Value any
+	Err   error
+}
+
+// QueryMaxLine is the longest line (in bytes) that QueryEach (and
+// LineScanner) will read from any JSONL file.
+var QueryMaxLine = 16 * 1024 * 1024
+
+// compileQuery returns a function selecting the values matching the
+// expression, which is either a Path or (if not) a jq expression.
+func compileQuery(expr string) (func(v any) ([]any, error), error) {
+	q, err := CompileQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.Select, nil
+}
+
+// QueryEach runs the query expression (a Path or jq expression) against
+// every line of every JSONL (newline delimited JSON) file using the
+// given number of concurrent workers (one per file at a time), sending
+// every matched value (along with any errors) to the returned channel
+// as soon as it is found, which is closed when all files are done. The
+// order of results from different files is therefore unpredictable
+// (see QueryEachOrdered). The channel must be drained. Blank lines are
+// skipped.
+func QueryEach(files []string, expr string, workers int) (<-chan QueryResult, error) {
+	return queryEach(files, expr, workers, false)
+}
+
+// QueryEachOrdered is the same as QueryEach but sends the results in
+// order (by file and line) while still querying the files concurrently.
+func QueryEachOrdered(files []string, expr string, workers int) (<-chan QueryResult, error) {
+	return queryEach(files, expr, workers, true)
+}
+
+func queryEach(files []string, expr string, workers int, ordered bool) (<-chan QueryResult, error) {
+	sel, err := compileQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	out := make(chan QueryResult, 64)
+	outs := make([]chan QueryResult, len(files))
+	for i := range outs {
+		outs[i] = out
+		if ordered {
+			outs[i] = make(chan QueryResult, 64)
+		}
+	}
+
+	next := make(chan int)
+	go func() {
+		for i := range files {
+			next <- i
+		}
+		close(next)
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				queryFile(files[i], sel, outs[i])
+				if ordered {
+					close(outs[i])
+				}
+			}
+		}()
+	}
+
+	go func() {
+		if ordered {
+			for _, ch := range outs {
+				for r := range ch {
+					out <- r
+				}
+			}
+		}
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// queryFile sends the results of the query for every line of the file.
+func queryFile(file string, sel func(any) ([]any, error), out chan<- QueryResult) {
+	f, err := os.Open(file)
+	if err != nil {
+		out <- QueryResult{File: file, Err: err}
+		return
+	}
+	defer f.Close()
+	scan := bufio.NewScanner(f)
+	scan.Buffer(nil, QueryMaxLine)
+	line := 0
+	for scan.Scan() {
+		line++
+		buf := bytes.TrimSpace(scan.Bytes())
+		if len(buf) == 0 {
+			continue
+		}
+		var doc any
+		if err := Unmarshal(buf, &doc); err != nil {
+			out <- QueryResult{file, line, nil, err}
+			continue
+		}
+		vals, err := sel(doc)
+		if err != nil {
+			out <- QueryResult{file, line, nil, err}
+			continue
+		}
+		for _, v := range vals {
+			out <- QueryResult{file, line, v, nil}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		out <- QueryResult{file, line + 1, nil, fmt.Errorf(`reading: %w`, err)}
+	}
+}
@@ -0,0 +1,53 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleQueryEachOrdered() {
+	dir, _ := os.MkdirTemp("", "jsonl")
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.jsonl")
+	b := filepath.Join(dir, "b.jsonl")
+	os.WriteFile(a, []byte("{\"n\":1}\n\n{\"n\":2}\n"), 0600)
+	os.WriteFile(b, []byte("{\"n\":3}\nnope\n{\"n\":4}\n"), 0600)
+
+	results, _ := json.QueryEachOrdered([]string{a, b}, `select(.n % 2 == 0) | .n`, 4)
+	for r := range results {
+		if r.Err != nil {
+			fmt.Println(filepath.Base(r.File), r.Line, "error")
+			continue
+		}
+		fmt.Println(filepath.Base(r.File), r.Line, r.Value)
+	}
+
+	// Output:
+	// a.jsonl 3 2
+	// b.jsonl 2 error
+	// b.jsonl 3 4
+}
+
+func ExampleQueryEach() {
+	dir, _ := os.MkdirTemp("", "jsonl")
+	defer os.RemoveAll(dir)
+	var files []string
+	for i := 0; i < 10; i++ {
+		f := filepath.Join(dir, fmt.Sprintf("%d.jsonl", i))
+		os.WriteFile(f, []byte("{\"n\":1}\n{\"n\":2}\n"), 0600)
+		files = append(files, f)
+	}
+
+	results, _ := json.QueryEach(files, `.n`, 3)
+	var sum float64
+	for r := range results {
+		sum += r.Value.(float64)
+	}
+	fmt.Println(sum)
+
+	// Output:
+	// 30
+}
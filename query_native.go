@@ -0,0 +1,52 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// NativeQuery is the lightweight QueryEngine (and DefaultQueryEngine)
+// that supports only Path expressions and JSON documents (one or more,
+// as with JSON Lines) but has no dependencies.
+type NativeQuery struct{}
+
+// Parse implements QueryEngine by decoding all the JSON documents in
+// the source preserving the exact representation of numbers.
+func (NativeQuery) Parse(src string) (any, error) {
+	dec := DefaultEngine.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+	var docs []any
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// Query implements QueryEngine for Path expressions.
+func (NativeQuery) Query(docs any, q string, o QueryOptions) (string, error) {
+	path, err := ParsePath(q)
+	if err != nil {
+		return "", err
+	}
+	out := new(strings.Builder)
+	for _, doc := range docs.([]any) {
+		results, err := path.Select(doc)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range results {
+			if err := o.write(out, r); err != nil {
+				return "", err
+			}
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,75 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleThis_Query() {
+	this := json.This{map[string]any{"name": "foo", "tags": []string{"a", "b"}}}
+
+	out, err := this.Query(".name")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(out)
+
+	out, err = this.Query(".tags[1]")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(out)
+
+	// strings are assumed to be JSON documents
+	this.This = `{"name":"bar","list":[{"id":1},{"id":2,"on":[true]}]}`
+	if err := this.QueryPrint(".name"); err != nil {
+		fmt.Println(err)
+	}
+	if err := this.QueryPrint("."); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// foo
+	// b
+	// bar
+	// list:
+	//   - id: 1
+	//   - id: 2
+	//     "on":
+	//       - true
+	// name: bar
+}
+
+func ExampleThis_QueryWith() {
+	this := json.This{`{"name":"foo","tags":["a","b"],"n":{"x":1.50}}`}
+
+	out, _ := this.QueryWith(".tags", json.QueryOptions{Format: json.QueryJSON})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".n", json.QueryOptions{
+		Format: json.QueryJSON,
+		Indent: 2,
+	})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".name", json.QueryOptions{Format: json.QueryJSON})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".tags[]", json.QueryOptions{Format: json.QueryRaw})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".name", json.QueryOptions{Quote: true})
+	fmt.Print(out)
+
+	// Output:
+	// ["a","b"]
+	// {
+	//   "x": 1.50
+	// }
+	// "foo"
+	// a
+	// b
+	// "foo"
+}
@@ -0,0 +1,197 @@
+/*
+Package repl provides an interactive read-eval-print loop for querying
+a single loaded JSON document (or the JSON response from a URL) with jq
+expressions (see json.JQ) or simple paths, effectively an embedded
+jq-shell for any Go program using the rwxrob/json package.
+
+Every line entered is a query against the document unless it begins
+with a colon, in which case it is one of the following commands:
+
+    :help            list commands
+    :keys [prefix]   list key paths (completions) starting with prefix
+    :save FILE       save the last result to the file (as indented JSON)
+    :color on|off    toggle colorized output
+    :quit            exit (as does end of input)
+
+Terminals that support line editing (or programs using a line editor
+library) can use Complete for tab completion of key paths.
+*/
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+// REPL is a read-eval-print loop over a single document.
+type REPL struct {
+	Doc    any       // document in generic form (see New)
+	In     io.Reader // defaults to os.Stdin
+	Out    io.Writer // defaults to os.Stdout
+	Prompt string    // defaults to "> "
+	Color  bool      // colorize results (default true from New)
+
+	last []any // results from the last query
+}
+
+// New returns a new REPL for the document which is any value that can
+// be marshaled as JSON (strings and []byte are assumed to be JSON
+// already).
+func New(doc any) (*REPL, error) {
+	var buf []byte
+	var err error
+	switch v := doc.(type) {
+	case string:
+		buf = []byte(v)
+	case []byte:
+		buf = v
+	default:
+		if buf, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	var g any
+	if err := json.Unmarshal(buf, &g); err != nil {
+		return nil, err
+	}
+	return &REPL{Doc: g, Prompt: `> `, Color: true}, nil
+}
+
+// NewFromURL returns a new REPL for the JSON response from the URL
+// (see json.Fetch).
+func NewFromURL(url string) (*REPL, error) {
+	var doc any
+	if err := json.Fetch(&json.Request{URL: url, Into: &doc}); err != nil {
+		return nil, err
+	}
+	return &REPL{Doc: doc, Prompt: `> `, Color: true}, nil
+}
+
+// Run reads and evaluates lines until :quit or the end of input
+// printing the results (or errors) of every query.
+func (r *REPL) Run() error {
+	in, out := r.In, r.Out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	scan := bufio.NewScanner(in)
+	scan.Buffer(nil, 1024*1024)
+	for {
+		fmt.Fprint(out, r.Prompt)
+		if !scan.Scan() {
+			fmt.Fprintln(out)
+			return scan.Err()
+		}
+		line := strings.TrimSpace(scan.Text())
+		if line == `` {
+			continue
+		}
+		if strings.HasPrefix(line, `:`) {
+			quit, err := r.command(out, line[1:])
+			if err != nil {
+				fmt.Fprintln(out, `error:`, err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+		if err := r.Eval(out, line); err != nil {
+			fmt.Fprintln(out, `error:`, err)
+		}
+	}
+}
+
+// Eval runs a single query (simple path or jq expression) against the
+// document writing every result (as indented JSON) to the writer.
+func (r *REPL) Eval(out io.Writer, expr string) error {
+	var sel func(any) ([]any, error)
+	if p, err := json.ParsePath(expr); err == nil {
+		sel = p.Select
+	} else {
+		q, err := json.ParseJQ(expr)
+		if err != nil {
+			return err
+		}
+		sel = q.Select
+	}
+	results, err := sel(r.Doc)
+	if err != nil {
+		return err
+	}
+	r.last = results
+	for _, v := range results {
+		buf, err := json.MarshalIndent(v, ``, `  `)
+		if err != nil {
+			return err
+		}
+		if r.Color {
+			buf = json.ColorTheme.Colorize(buf)
+		}
+		fmt.Fprintln(out, string(buf))
+	}
+	return nil
+}
+
+// command runs a colon command returning true if the REPL should quit.
+func (r *REPL) command(out io.Writer, line string) (bool, error) {
+	name, arg, _ := strings.Cut(line, ` `)
+	arg = strings.TrimSpace(arg)
+	switch name {
+
+	case `q`, `quit`, `exit`:
+		return true, nil
+
+	case `h`, `help`:
+		fmt.Fprintln(out, `:keys [prefix]  :save FILE  :color on|off  :quit`)
+
+	case `keys`:
+		for _, k := range r.Complete(arg) {
+			fmt.Fprintln(out, k)
+		}
+
+	case `save`:
+		if arg == `` {
+			return false, fmt.Errorf(`missing file name`)
+		}
+		var v any = r.last
+		if len(r.last) == 1 {
+			v = r.last[0]
+		}
+		buf, err := json.MarshalIndent(v, ``, `  `)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(arg, append(buf, '\n'), 0600); err != nil {
+			return false, err
+		}
+		fmt.Fprintln(out, `saved`, arg)
+
+	case `color`:
+		r.Color = arg != `off`
+
+	default:
+		return false, fmt.Errorf(`unknown command: %s`, name)
+	}
+	return false, nil
+}
+
+// Complete returns the key paths of the document (see json.KeyPaths)
+// that begin with the prefix (sorted) for tab completion.
+func (r *REPL) Complete(prefix string) []string {
+	var out []string
+	for _, p := range json.KeyPaths(r.Doc) {
+		if strings.HasPrefix(p, prefix) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
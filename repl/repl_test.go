@@ -0,0 +1,33 @@
+package repl_test
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rwxrob/json/repl"
+)
+
+func ExampleREPL_Run() {
+	r, _ := repl.New(`{"name":"app","hosts":[{"ip":"10.0.0.1"},{"ip":"10.0.0.2","up":true}]}`)
+	r.Color = false
+	r.Out = os.Stdout
+	r.In = strings.NewReader(strings.Join([]string{
+		`.name`,
+		`:keys .hosts`,
+		`[.hosts[] | select(.up) | .ip]`,
+		`.nope[`,
+		`:quit`,
+	}, "\n"))
+	r.Run()
+
+	// Output:
+	// > "app"
+	// > .hosts
+	// .hosts[].ip
+	// .hosts[].up
+	// > [
+	//   "10.0.0.2"
+	// ]
+	// > error: jq: unexpected end at 6: ".nope["
+	// >
+}
@@ -0,0 +1,108 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Result is the standard machine-readable output envelope for CLI
+// tools built with this package. Print it as the only output of the
+// command and exit with its ExitCode:
+//
+//     {"ok":true,"data":...,"meta":{"duration":"12ms"}}
+//     {"ok":false,"error":{...},"meta":{"duration":"3s"}}
+//
+// The error is rendered with ErrorValue.
+type Result[T any] struct {
+	OK    bool       `json:"ok"`
+	Data  T          `json:"data,omitempty"`
+	Error any        `json:"error,omitempty"`
+	Meta  ResultMeta `json:"meta"`
+	err   error
+}
+
+// ResultMeta is the metadata of any Result.
+type ResultMeta struct {
+	Duration  string `json:"duration,omitempty"` // time.Duration format
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// NewResult returns a new Result for the data and error (if any).
+func NewResult[T any](data T, err error) *Result[T] {
+	r := &Result[T]{OK: err == nil, err: err}
+	if err != nil {
+		r.Error = ErrorValue(err)
+		return r
+	}
+	r.Data = data
+	return r
+}
+
+// RunResult calls the function and returns its Result including the
+// duration of the call.
+func RunResult[T any](fn func() (T, error)) *Result[T] {
+	start := time.Now()
+	data, err := fn()
+	r := NewResult(data, err)
+	r.Meta.Duration = time.Since(start).String()
+	return r
+}
+
+// MarshalJSON fulfills the encoding/json.Marshaler interface leaving
+// out the data when not OK.
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	out := struct {
+		OK    bool       `json:"ok"`
+		Data  any        `json:"data,omitempty"`
+		Error any        `json:"error,omitempty"`
+		Meta  ResultMeta `json:"meta"`
+	}{OK: r.OK, Error: r.Error, Meta: r.Meta}
+	if r.OK {
+		out.Data = r.Data
+	}
+	return Marshal(out)
+}
+
+// Err returns the original error of the Result (if any).
+func (r *Result[T]) Err() error { return r.err }
+
+// Print prints the Result as JSON on a single line.
+func (r *Result[T]) Print() { fmt.Println(This{r}) }
+
+// Exit codes returned by Result.ExitCode (from the BSD sysexits
+// conventions where they apply).
+const (
+	ExitOK          = 0
+	ExitError       = 1  // any other error
+	ExitData        = 65 // invalid input data (decoding, UTF-8)
+	ExitUnavailable = 69 // service or network unavailable
+	ExitTempFail    = 75 // timeouts (try again later)
+)
+
+// ExitCode returns the exit code matching the Result: ExitOK when OK
+// and one of the others according to the type of error (anywhere in
+// its chain).
+func (r *Result[T]) ExitCode() int {
+	if r.err == nil {
+		return ExitOK
+	}
+	var terr *TimeOutError
+	var uerr InvalidUTF8Error
+	var serr *json.SyntaxError
+	var ierr *json.UnmarshalTypeError
+	var nerr net.Error
+	switch {
+	case errors.As(r.err, &terr):
+		return ExitTempFail
+	case errors.As(r.err, &nerr) && nerr.Timeout():
+		return ExitTempFail
+	case errors.As(r.err, &serr), errors.As(r.err, &ierr), errors.As(r.err, &uerr):
+		return ExitData
+	case errors.As(r.err, &nerr):
+		return ExitUnavailable
+	}
+	return ExitError
+}
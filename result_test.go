@@ -0,0 +1,38 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleNewResult() {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	ok := json.NewResult(User{"ann"}, nil)
+	ok.Meta.RequestID = "req-1"
+	ok.Print()
+	fmt.Println(ok.ExitCode())
+
+	var u User
+	err := json.Unmarshal([]byte(`{"name":`), &u)
+	bad := json.NewResult(u, err)
+	bad.Print()
+	fmt.Println(bad.ExitCode())
+
+	// Output:
+	// {"ok":true,"data":{"name":"ann"},"meta":{"request_id":"req-1"}}
+	// 0
+	// {"ok":false,"error":{"message":"unexpected end of JSON input","type":"*json.SyntaxError","details":{"offset":8}},"meta":{}}
+	// 65
+}
+
+func ExampleRunResult() {
+	r := json.RunResult(func() ([]int, error) { return []int{1, 2}, nil })
+	fmt.Println(r.OK, r.Data, r.Meta.Duration != "")
+
+	// Output:
+	// true [1 2] true
+}
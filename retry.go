@@ -0,0 +1,100 @@
+package json
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy is how Fetch (and the rest) retries a request that
+// failed with a given status code (see RetryPolicies).
+type RetryPolicy struct {
+	Max        int           // retries (after the first attempt)
+	Delay      time.Duration // before the first retry
+	Backoff    float64       // multiplies Delay for every retry after
+	MaxDelay   time.Duration // limits every delay (if set)
+	RetryAfter bool          // wait as long as any Retry-After header
+
+	// Refresh (if set) is called before every retry of the policy, to
+	// refresh an expired token (before retrying a 401), for example.
+	// An error from Refresh is returned without retrying.
+	Refresh func(ctx context.Context) error
+}
+
+// RetryPolicies (empty by default) maps the status codes of failed
+// requests to the RetryPolicy for each so that the different failures
+// of real APIs can each be handled their own way. The status code 0 is
+// for requests that failed without any response (a refused connection,
+// for example, but never a canceled context or TimeOut). Retries of
+// each status code are counted separately (but all share the context
+// and TimeOut of the request). A RetryPolicies of the Request replaces
+// this one:
+//
+//     json.RetryPolicies = map[int]json.RetryPolicy{
+//       429: {Max: 5, Delay: time.Second, RetryAfter: true},
+//       502: {Max: 5, Delay: 100 * time.Millisecond, Backoff: 2},
+//       503: {Max: 5, Delay: 100 * time.Millisecond, Backoff: 2},
+//       401: {Max: 1, Refresh: refreshToken},
+//     }
+//
+var RetryPolicies = map[int]RetryPolicy{}
+
+// retry returns the policy and how long to wait before retrying the
+// Request after the error (and retries of the same status code so far)
+// or false if it should not be retried.
+func (it *Request) retry(err error, retries map[int]int) (RetryPolicy, time.Duration, bool) {
+	policies := RetryPolicies
+	if it.RetryPolicies != nil {
+		policies = it.RetryPolicies
+	}
+	var header http.Header
+	code := 0
+	var herr *HTTPError
+	switch {
+	case errors.As(err, &herr):
+		code, header = herr.StatusCode, herr.Header
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return RetryPolicy{}, 0, false
+	default:
+		var uerr *url.Error
+		var terr *TimeOutError
+		if !errors.As(err, &uerr) || errors.As(err, &terr) && terr.Phase == `total` {
+			return RetryPolicy{}, 0, false
+		}
+	}
+	p, has := policies[code]
+	if !has || retries[code] >= p.Max {
+		return p, 0, false
+	}
+	wait := p.Delay
+	for n := 0; n < retries[code] && p.Backoff > 0; n++ {
+		wait = time.Duration(float64(wait) * p.Backoff)
+	}
+	if after, ok := retryAfter(header); ok && p.RetryAfter && after > wait {
+		wait = after
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	retries[code]++
+	return p, wait, true
+}
+
+// retryAfter returns the duration of the Retry-After header (in
+// seconds or an HTTP date) if it has one.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get(`Retry-After`)
+	if v == `` {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
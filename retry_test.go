@@ -0,0 +1,69 @@
+package json_test
+
+import (
+	"context"
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"time"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleRetryPolicy() {
+	calls := map[string]int{}
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			calls[r.URL.Path]++
+			switch {
+			case r.URL.RawQuery != `q=1`:
+				w.WriteHeader(400)
+				return
+			case r.URL.Path == `/busy` && calls[r.URL.Path] < 3:
+				w.WriteHeader(503)
+				return
+			case r.URL.Path == `/limited` && calls[r.URL.Path] < 2:
+				w.Header().Set(`Retry-After`, `0`)
+				w.WriteHeader(429)
+				return
+			case r.URL.Path == `/private` && r.Header.Get(`Authorization`) != `Bearer fresh`:
+				w.WriteHeader(401)
+				return
+			case r.URL.Path == `/down`:
+				w.WriteHeader(502)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true}`)
+		}))
+	defer svr.Close()
+
+	token := `stale`
+	policies := map[int]json.RetryPolicy{
+		429: {Max: 5, RetryAfter: true},
+		502: {Max: 2, Delay: time.Millisecond, Backoff: 2},
+		503: {Max: 5, Delay: time.Millisecond, Backoff: 2},
+		401: {Max: 1, Refresh: func(ctx context.Context) error {
+			token = `fresh`
+			return nil
+		}},
+	}
+	auth := func(r *_http.Request) { r.Header.Set(`Authorization`, `Bearer `+token) }
+
+	for _, path := range []string{`/busy`, `/limited`, `/private`, `/down`} {
+		var got struct{ OK bool }
+		err := json.Fetch(&json.Request{
+			URL:           svr.URL + path,
+			Query:         map[string][]string{`q`: {`1`}},
+			Into:          &got,
+			RetryPolicies: policies,
+			OnRequest:     []func(*_http.Request){auth},
+		})
+		fmt.Println(path, calls[path], got.OK, err)
+	}
+
+	// Output:
+	// /busy 3 true <nil>
+	// /limited 2 true <nil>
+	// /private 2 true <nil>
+	// /down 3 false 502 Bad Gateway
+}
@@ -0,0 +1,141 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// Head returns (at most) the first n items of a top-level array (or
+// values of JSON Lines) from the reader decoded with Unmarshal. Nothing
+// after them is read (or even checked) so that a glance at a huge
+// export is instant. Progress is reported to OnProgress (if set).
+func Head[T any](r io.Reader, n int) ([]T, error) {
+	var raws []json.RawMessage
+	err := eachItem(r, func(raw json.RawMessage, _ int) bool {
+		if len(raws) >= n {
+			return false
+		}
+		raws = append(raws, raw)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeItems[T](raws, nil)
+}
+
+// Tail returns (at most) the last n items (see Head) keeping only n in
+// memory while reading all of them. Only those returned are decoded.
+func Tail[T any](r io.Reader, n int) ([]T, error) {
+	if n <= 0 {
+		return []T{}, nil
+	}
+	ring := make([]json.RawMessage, 0, n)
+	next := 0
+	count := 0
+	err := eachItem(r, func(raw json.RawMessage, i int) bool {
+		count = i + 1
+		if len(ring) < n {
+			ring = append(ring, raw)
+			return true
+		}
+		ring[next] = raw
+		next = (next + 1) % n
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	raws := append(ring[next:len(ring):len(ring)], ring[:next]...)
+	first := make([]int, len(raws))
+	for i := range first {
+		first[i] = count - len(raws) + i
+	}
+	return decodeItems[T](raws, first)
+}
+
+// SampleN returns a uniformly random sample of (at most) n items (see
+// Head) in the order they were read keeping only n in memory while
+// reading all of them (reservoir sampling). Randomness is from the
+// source (or that of the math/rand package if nil) so that samples are
+// repeatable with rand.NewSource(seed). Only those returned are
+// decoded.
+func SampleN[T any](r io.Reader, n int, src rand.Source) ([]T, error) {
+	if n <= 0 {
+		return []T{}, nil
+	}
+	random := rand.Int63n
+	if src != nil {
+		random = rand.New(src).Int63n
+	}
+	raws := make([]json.RawMessage, 0, n)
+	index := make([]int, 0, n)
+	err := eachItem(r, func(raw json.RawMessage, i int) bool {
+		if len(raws) < n {
+			raws = append(raws, raw)
+			index = append(index, i)
+			return true
+		}
+		if j := int(random(int64(i + 1))); j < n {
+			raws[j], index[j] = raw, i
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(raws))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return index[order[a]] < index[order[b]] })
+	sorted := make([]json.RawMessage, len(raws))
+	first := make([]int, len(raws))
+	for i, o := range order {
+		sorted[i], first[i] = raws[o], index[o]
+	}
+	return decodeItems[T](sorted, first)
+}
+
+// eachItem calls the function with every raw item (and its index) of
+// the reader until it returns false.
+func eachItem(r io.Reader, fn func(raw json.RawMessage, i int) bool) error {
+	pr := newProgressReader(r, "", -1)
+	defer pr.done()
+	items, err := newItemReader(pr)
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		raw, err := items.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(raw, i) {
+			return nil
+		}
+		pr.item()
+	}
+}
+
+// decodeItems decodes every raw item with Unmarshal reporting errors
+// with the index of the item (from index if not nil).
+func decodeItems[T any](raws []json.RawMessage, index []int) ([]T, error) {
+	out := make([]T, len(raws))
+	for i, raw := range raws {
+		if err := Unmarshal(raw, &out[i]); err != nil {
+			n := i
+			if index != nil {
+				n = index[i]
+			}
+			return nil, fmt.Errorf(`item %d: %w`, n, err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,54 @@
+package json_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleHead() {
+	data := `[1, 2, 3, 4, 5, "not read", {`
+
+	head, err := json.Head[int](strings.NewReader(data), 3)
+	fmt.Println(head, err)
+
+	lines := "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n{\"n\":4}\n"
+	tail, err := json.Tail[map[string]int](strings.NewReader(lines), 2)
+	fmt.Println(tail, err)
+
+	_, err = json.Tail[int](strings.NewReader(`[1, 2, "three", 4]`), 2)
+	fmt.Println(err)
+
+	// Output:
+	// [1 2 3] <nil>
+	// [map[n:3] map[n:4]] <nil>
+	// item 2: json: cannot unmarshal string into Go value of type int
+}
+
+func ExampleSampleN() {
+	var b strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintln(&b, i)
+	}
+	data := b.String()
+
+	a, _ := json.SampleN[int](strings.NewReader(data), 5, rand.NewSource(42))
+	again, _ := json.SampleN[int](strings.NewReader(data), 5, rand.NewSource(42))
+	fmt.Println(len(a), fmt.Sprint(a) == fmt.Sprint(again))
+
+	ordered := true
+	for i := 1; i < len(a); i++ {
+		ordered = ordered && a[i-1] < a[i]
+	}
+	fmt.Println(ordered)
+
+	all, _ := json.SampleN[int](strings.NewReader(`[1,2,3]`), 10, nil)
+	fmt.Println(all)
+
+	// Output:
+	// 5 true
+	// true
+	// [1 2 3]
+}
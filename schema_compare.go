@@ -0,0 +1,258 @@
+package json
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compatibility classes reported by CompareSchemas.
+const (
+	CompatFull     = `full`     // both backward and forward
+	CompatBackward = `backward` // new schema reads data of old schema
+	CompatForward  = `forward`  // old schema reads data of new schema
+	CompatBreaking = `breaking` // neither
+)
+
+// SchemaChange is a single difference found by CompareSchemas.
+type SchemaChange struct {
+	Path   string `json:"path"`             // key path (see KeyPaths)
+	Desc   string `json:"desc"`             // what changed
+	Breaks string `json:"breaks,omitempty"` // backward, forward, or neither
+}
+
+// SchemaReport is the result of CompareSchemas.
+type SchemaReport struct {
+	Compat  string         `json:"compat"`
+	Changes []SchemaChange `json:"changes"`
+}
+
+// CompareSchemas compares the old and new JSON Schemas reporting the
+// compatibility class of the new schema along with every change (and
+// which compatibility it breaks) so that releases of schemas (those
+// generated from Go structs, for example) can be gated. Data is assumed
+// to only have the properties declared by the schema used to write it.
+// Breaking backward (old data invalid under new schema):
+//
+//     * newly required properties
+//     * removed types or enum values
+//     * tighter bounds (minimum, maxLength, etc.)
+//     * removed properties (when additionalProperties is false)
+//     * additionalProperties changed to false
+//
+// Breaking forward (new data invalid under old schema) is the opposite
+// of each. Properties, items, and additionalProperties schemas are
+// compared recursively. Changes that neither break (descriptions,
+// titles, etc.) are ignored.
+func CompareSchemas(old, new []byte) (*SchemaReport, error) {
+	var a, b map[string]any
+	if err := Unmarshal(old, &a); err != nil {
+		return nil, fmt.Errorf(`old schema: %w`, err)
+	}
+	if err := Unmarshal(new, &b); err != nil {
+		return nil, fmt.Errorf(`new schema: %w`, err)
+	}
+	r := &SchemaReport{Changes: []SchemaChange{}}
+	r.compare(Path{}, a, b)
+	var back, fwd bool
+	for _, c := range r.Changes {
+		back = back || c.Breaks == `backward`
+		fwd = fwd || c.Breaks == `forward`
+	}
+	switch {
+	case back && fwd:
+		r.Compat = CompatBreaking
+	case back:
+		r.Compat = CompatForward
+	case fwd:
+		r.Compat = CompatBackward
+	default:
+		r.Compat = CompatFull
+	}
+	return r, nil
+}
+
+func (r *SchemaReport) add(p Path, breaks, format string, args ...any) {
+	r.Changes = append(r.Changes, SchemaChange{p.String(), fmt.Sprintf(format, args...), breaks})
+}
+
+// tighter and looser return which compatibility is broken when a
+// constraint is tightened or loosened.
+const (
+	tighter = `backward`
+	looser  = `forward`
+)
+
+func (r *SchemaReport) compare(p Path, a, b map[string]any) {
+
+	// types
+	at, bt := schemaTypes(a), schemaTypes(b)
+	for _, t := range setDiff(at, bt) {
+		r.add(p, tighter, `type %s removed`, t)
+	}
+	for _, t := range setDiff(bt, at) {
+		r.add(p, looser, `type %s added`, t)
+	}
+
+	// enums
+	ae, be := schemaEnum(a), schemaEnum(b)
+	if ae != nil || be != nil {
+		switch {
+		case be == nil:
+			r.add(p, looser, `enum removed`)
+		case ae == nil:
+			r.add(p, tighter, `enum added`)
+		default:
+			for _, v := range setDiff(ae, be) {
+				r.add(p, tighter, `enum value %s removed`, v)
+			}
+			for _, v := range setDiff(be, ae) {
+				r.add(p, looser, `enum value %s added`, v)
+			}
+		}
+	}
+
+	// bounds (true when larger is tighter)
+	for _, k := range []struct {
+		name  string
+		lower bool
+	}{
+		{`minimum`, true}, {`exclusiveMinimum`, true},
+		{`minLength`, true}, {`minItems`, true}, {`minProperties`, true},
+		{`maximum`, false}, {`exclusiveMaximum`, false},
+		{`maxLength`, false}, {`maxItems`, false}, {`maxProperties`, false},
+	} {
+		av, ahas := a[k.name].(float64)
+		bv, bhas := b[k.name].(float64)
+		switch {
+		case ahas == bhas && av == bv:
+		case !bhas:
+			r.add(p, looser, `%s %v removed`, k.name, av)
+		case !ahas:
+			r.add(p, tighter, `%s %v added`, k.name, bv)
+		case (bv > av) == k.lower:
+			r.add(p, tighter, `%s changed from %v to %v`, k.name, av, bv)
+		default:
+			r.add(p, looser, `%s changed from %v to %v`, k.name, av, bv)
+		}
+	}
+
+	// required
+	ar, br := schemaStrings(a[`required`]), schemaStrings(b[`required`])
+	for _, k := range setDiff(br, ar) {
+		r.add(p.Key(k), tighter, `now required`)
+	}
+	for _, k := range setDiff(ar, br) {
+		r.add(p.Key(k), looser, `no longer required`)
+	}
+
+	// additional properties
+	aclosed, bclosed := a[`additionalProperties`] == false, b[`additionalProperties`] == false
+	switch {
+	case !aclosed && bclosed:
+		r.add(p, tighter, `additional properties no longer allowed`)
+	case aclosed && !bclosed:
+		r.add(p, looser, `additional properties now allowed`)
+	}
+	as, _ := a[`additionalProperties`].(map[string]any)
+	bs, _ := b[`additionalProperties`].(map[string]any)
+	if as != nil && bs != nil {
+		r.compare(p.Each(), as, bs)
+	}
+
+	// properties
+	ap, _ := a[`properties`].(map[string]any)
+	bp, _ := b[`properties`].(map[string]any)
+	keys := make([]string, 0, len(ap)+len(bp))
+	for k := range ap {
+		keys = append(keys, k)
+	}
+	for k := range bp {
+		if _, has := ap[k]; !has {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		as, ahas := ap[k].(map[string]any)
+		bs, bhas := bp[k].(map[string]any)
+		switch {
+		case ahas && bhas:
+			r.compare(p.Key(k), as, bs)
+		case ahas && bclosed:
+			r.add(p.Key(k), tighter, `property removed`)
+		case ahas:
+			r.add(p.Key(k), ``, `property removed`)
+		case aclosed:
+			r.add(p.Key(k), looser, `property added`)
+		default:
+			r.add(p.Key(k), ``, `property added`)
+		}
+	}
+
+	// items
+	ai, _ := a[`items`].(map[string]any)
+	bi, _ := b[`items`].(map[string]any)
+	if ai != nil && bi != nil {
+		r.compare(p.Each(), ai, bi)
+	}
+}
+
+// schemaTypes returns the types of the schema (any when not set).
+func schemaTypes(s map[string]any) []string {
+	switch t := s[`type`].(type) {
+	case string:
+		if t == `number` {
+			return []string{`integer`, `number`}
+		}
+		return []string{t}
+	case []any:
+		out := schemaStrings(t)
+		for _, x := range out {
+			if x == `number` {
+				out = append(out, `integer`)
+			}
+		}
+		return out
+	}
+	return []string{`array`, `boolean`, `integer`, `null`, `number`, `object`, `string`}
+}
+
+// schemaEnum returns the enum values (as JSON) or nil if none.
+func schemaEnum(s map[string]any) []string {
+	e, is := s[`enum`].([]any)
+	if !is {
+		return nil
+	}
+	out := make([]string, 0, len(e))
+	for _, v := range e {
+		out = append(out, This{v}.String())
+	}
+	return out
+}
+
+// schemaStrings returns the strings in the generic array.
+func schemaStrings(v any) []string {
+	a, _ := v.([]any)
+	var out []string
+	for _, i := range a {
+		if s, is := i.(string); is {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// setDiff returns the sorted unique strings in a that are not in b.
+func setDiff(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	out := map[string]bool{}
+	for _, s := range a {
+		if !in[s] {
+			out[s] = true
+		}
+	}
+	return sortedKeys(out)
+}
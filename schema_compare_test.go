@@ -0,0 +1,66 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleCompareSchemas() {
+	v1 := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name": {"type": "string", "maxLength": 20},
+	    "role": {"enum": ["admin", "user"]},
+	    "tags": {"type": "array", "items": {"type": "string"}}
+	  },
+	  "required": ["name"]
+	}`)
+
+	v2 := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name": {"type": "string", "maxLength": 40},
+	    "role": {"enum": ["admin", "user", "guest"]},
+	    "tags": {"type": "array", "items": {"type": ["string", "null"]}},
+	    "email": {"type": "string"}
+	  },
+	  "required": ["name"]
+	}`)
+
+	v3 := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name": {"type": "string", "maxLength": 20},
+	    "role": {"enum": ["admin"]}
+	  },
+	  "required": ["name", "role"]
+	}`)
+
+	report, _ := json.CompareSchemas(v1, v2)
+	fmt.Println(report.Compat)
+	for _, c := range report.Changes {
+		json.This{c}.Print()
+	}
+
+	report, _ = json.CompareSchemas(v1, v3)
+	fmt.Println(report.Compat)
+	for _, c := range report.Changes {
+		json.This{c}.Print()
+	}
+
+	report, _ = json.CompareSchemas(v1, v1)
+	fmt.Println(report.Compat)
+
+	// Output:
+	// backward
+	// {"path":".email","desc":"property added"}
+	// {"path":".name","desc":"maxLength changed from 20 to 40","breaks":"forward"}
+	// {"path":".role","desc":"enum value \"guest\" added","breaks":"forward"}
+	// {"path":".tags[]","desc":"type null added","breaks":"forward"}
+	// forward
+	// {"path":".role","desc":"now required","breaks":"backward"}
+	// {"path":".role","desc":"enum value \"user\" removed","breaks":"backward"}
+	// {"path":".tags","desc":"property removed"}
+	// full
+}
@@ -0,0 +1,131 @@
+package json
+
+import "strings"
+
+// ExampleFromSchema returns an example document (indented JSON with
+// keys in the order of the schema properties) for the JSON Schema,
+// which is ideal for scaffolding configuration files. Every value is
+// the first found of its default, const, examples (or OpenAPI
+// example), or enum. Otherwise objects have every property, arrays a
+// single item, and everything else a placeholder for its type (and
+// format), such as "string", 0 (or the minimum), false,
+// "user@example.com", or "2006-01-02T15:04:05Z". References ($ref to
+// #/...) are followed (but never recursively), allOf merged, and the
+// first of anyOf and oneOf used.
+func ExampleFromSchema(schema []byte) ([]byte, error) {
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		return nil, err
+	}
+	a := newAnnotator(schema, s)
+	a.plain = true
+	g := &exampleGen{a: a, active: map[string]bool{}}
+	v := g.value(s, Path{})
+	a.value(v, s, Path{}, ``, 0, false)
+	return a.buf.Bytes(), nil
+}
+
+// exampleGen generates example values (see ExampleFromSchema).
+type exampleGen struct {
+	a      *annotator
+	active map[string]bool // references being generated
+}
+
+// exampleFormats are the placeholders for string formats.
+var exampleFormats = map[string]string{
+	`date-time`: `2006-01-02T15:04:05Z`,
+	`date`:      `2006-01-02`,
+	`time`:      `15:04:05Z`,
+	`duration`:  `PT1H`,
+	`email`:     `user@example.com`,
+	`hostname`:  `example.com`,
+	`ipv4`:      `192.0.2.1`,
+	`ipv6`:      `2001:db8::1`,
+	`uri`:       `https://example.com`,
+	`url`:       `https://example.com`,
+	`uuid`:      `00000000-0000-0000-0000-000000000000`,
+}
+
+// value returns the example value (in generic form) for the schema at
+// the Path.
+func (g *exampleGen) value(schema any, at Path) any {
+	m, is := schema.(map[string]any)
+	if !is {
+		return nil
+	}
+	if ref, is := m[`$ref`].(string); is && strings.HasPrefix(ref, `#`) {
+		if g.active[ref] {
+			return nil
+		}
+		g.active[ref] = true
+		defer delete(g.active, ref)
+	}
+	s, at := g.a.resolve(m, at)
+	if s == nil {
+		return nil
+	}
+
+	for _, k := range []string{`default`, `const`, `example`} {
+		if v, has := s[k]; has {
+			return v
+		}
+	}
+	for _, k := range []string{`examples`, `enum`} {
+		if list, is := s[k].([]any); is && len(list) > 0 {
+			return list[0]
+		}
+	}
+	for _, k := range []string{`anyOf`, `oneOf`} {
+		if list, is := s[k].([]any); is && len(list) > 0 {
+			return g.value(list[0], at.Key(k).Index(0))
+		}
+	}
+
+	typ := ``
+	for _, t := range schemaTypeList(s[`type`]) {
+		if t != `null` {
+			typ = t
+			break
+		}
+	}
+	if typ == `` {
+		_, props := s[`properties`]
+		_, all := s[`allOf`]
+		_, items := s[`items`]
+		switch {
+		case props || all:
+			typ = `object`
+		case items:
+			typ = `array`
+		}
+	}
+
+	switch typ {
+	case `object`:
+		out := map[string]any{}
+		names, props, paths := g.a.properties(s, at)
+		for _, k := range names {
+			out[k] = g.value(props[k], paths[k])
+		}
+		return out
+	case `array`:
+		if _, has := s[`items`]; !has {
+			return []any{}
+		}
+		return []any{g.value(s[`items`], at.Key(`items`))}
+	case `string`:
+		format, _ := s[`format`].(string)
+		if f, has := exampleFormats[format]; has {
+			return f
+		}
+		return `string`
+	case `integer`, `number`:
+		if low, is := s[`minimum`].(float64); is {
+			return low
+		}
+		return 0
+	case `boolean`:
+		return false
+	}
+	return nil
+}
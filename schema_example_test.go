@@ -0,0 +1,52 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleExampleFromSchema() {
+	schema := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name":    {"type": "string", "examples": ["web"]},
+	    "port":    {"type": "integer", "default": 8080},
+	    "workers": {"type": "integer", "minimum": 1},
+	    "level":   {"enum": ["info", "debug"]},
+	    "admin":   {"type": "string", "format": "email"},
+	    "tls":     {"type": "boolean"},
+	    "hosts":   {"type": "array", "items": {"type": "string", "format": "hostname"}},
+	    "owner":   {"$ref": "#/$defs/user"}
+	  },
+	  "$defs": {
+	    "user": {
+	      "type": "object",
+	      "properties": {
+	        "id":      {"type": "string", "format": "uuid"},
+	        "manager": {"$ref": "#/$defs/user"}
+	      }
+	    }
+	  }
+	}`)
+
+	out, err := json.ExampleFromSchema(schema)
+	fmt.Println(string(out), err)
+
+	// Output:
+	// {
+	//   "name": "web",
+	//   "port": 8080,
+	//   "workers": 1,
+	//   "level": "info",
+	//   "admin": "user@example.com",
+	//   "tls": false,
+	//   "hosts": [
+	//     "example.com"
+	//   ],
+	//   "owner": {
+	//     "id": "00000000-0000-0000-0000-000000000000",
+	//     "manager": null
+	//   }
+	// } <nil>
+}
@@ -0,0 +1,319 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaViolation is a single way a value is invalid (see
+// ValidateSchema).
+type SchemaViolation struct {
+	Path string `json:"path"` // of value (see Path)
+	Msg  string `json:"msg"`
+}
+
+// SchemaError contains every SchemaViolation found by ValidateSchema.
+type SchemaError struct {
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// Error fulfills the error interface.
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Path + `: ` + v.Msg
+	}
+	return `schema violations: ` + strings.Join(msgs, `; `)
+}
+
+// ValidateSchema validates the value (anything that can be marshaled
+// into JSON with strings and []byte assumed to be JSON already) against
+// the JSON Schema returning a *SchemaError with every violation (or nil
+// if valid). The commonly used keywords of draft 2020-12 (and earlier)
+// are supported along with nullable from OpenAPI 3.0: type, enum,
+// const, properties, required, additionalProperties, items, the min
+// and max keywords, pattern, multipleOf, uniqueItems, allOf, anyOf,
+// oneOf, not, and local references ($ref to #/...). Unknown keywords
+// (format, etc.) are ignored.
+func ValidateSchema(schema []byte, v any) error {
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		return err
+	}
+	return (&Schema{root: s}).Validate(v)
+}
+
+// validate validates the value against the schema (with references
+// resolved from the root) returning a *SchemaError or nil.
+func validate(root, schema, v any) error {
+	sv := &schemaValidator{root: root}
+	sv.check(schema, v, Path{})
+	if len(sv.violations) == 0 {
+		return nil
+	}
+	return &SchemaError{sv.violations}
+}
+
+type schemaValidator struct {
+	root       any
+	patterns   map[string]*regexp.Regexp // compiled (see Schema)
+	violations []SchemaViolation
+	depth      int
+}
+
+func (sv *schemaValidator) fail(p Path, format string, args ...any) {
+	sv.violations = append(sv.violations, SchemaViolation{p.String(), fmt.Sprintf(format, args...)})
+}
+
+// valid returns true if the value is valid against the schema without
+// recording any violations.
+func (sv *schemaValidator) valid(schema, v any, p Path) bool {
+	sub := &schemaValidator{root: sv.root, patterns: sv.patterns, depth: sv.depth}
+	sub.check(schema, v, p)
+	return len(sub.violations) == 0
+}
+
+// ref resolves a local JSON Pointer reference.
+func (sv *schemaValidator) ref(ref string) (any, error) {
+	if !strings.HasPrefix(ref, `#`) {
+		return nil, fmt.Errorf(`unsupported reference: %s`, ref)
+	}
+	p, err := resolvePointer(sv.root, ref[1:])
+	if err != nil {
+		return nil, err
+	}
+	return getIn(sv.root, p)
+}
+
+func (sv *schemaValidator) check(schema, v any, p Path) {
+	if sv.depth > maxDepth {
+		sv.fail(p, `schema nesting too deep (recursive $ref?)`)
+		return
+	}
+	sv.depth++
+	defer func() { sv.depth-- }()
+
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			sv.fail(p, `not allowed`)
+		}
+		return
+	case map[string]any:
+		sv.object(s, v, p)
+	}
+}
+
+func (sv *schemaValidator) object(s map[string]any, v any, p Path) {
+
+	if ref, is := s[`$ref`].(string); is {
+		target, err := sv.ref(ref)
+		if err != nil {
+			sv.fail(p, `%v`, err)
+			return
+		}
+		sv.check(target, v, p)
+	}
+
+	if v == nil && s[`nullable`] == true {
+		return
+	}
+
+	if t, has := s[`type`]; has && !typeMatches(t, v) {
+		sv.fail(p, `expected %s but got %s`, typeNames(t), jsonType(v))
+		return
+	}
+
+	if e, is := s[`enum`].([]any); is {
+		found := false
+		for _, x := range e {
+			if reflect.DeepEqual(x, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sv.fail(p, `not one of %s`, This{e})
+		}
+	}
+	if c, has := s[`const`]; has && !reflect.DeepEqual(c, v) {
+		sv.fail(p, `must be %s`, This{c})
+	}
+
+	for _, k := range []string{`allOf`, `anyOf`, `oneOf`} {
+		subs, is := s[k].([]any)
+		if !is {
+			continue
+		}
+		n := 0
+		for _, sub := range subs {
+			if k == `allOf` {
+				sv.check(sub, v, p)
+				continue
+			}
+			if sv.valid(sub, v, p) {
+				n++
+			}
+		}
+		switch {
+		case k == `anyOf` && n == 0:
+			sv.fail(p, `matches none of anyOf`)
+		case k == `oneOf` && n != 1:
+			sv.fail(p, `matches %d of oneOf (must be exactly one)`, n)
+		}
+	}
+	if not, has := s[`not`]; has && sv.valid(not, v, p) {
+		sv.fail(p, `must not match schema`)
+	}
+
+	switch t := v.(type) {
+
+	case float64:
+		if m, is := s[`minimum`].(float64); is {
+			if t < m || (s[`exclusiveMinimum`] == true && t == m) {
+				sv.fail(p, `less than minimum %v`, m)
+			}
+		}
+		if m, is := s[`maximum`].(float64); is {
+			if t > m || (s[`exclusiveMaximum`] == true && t == m) {
+				sv.fail(p, `greater than maximum %v`, m)
+			}
+		}
+		if m, is := s[`exclusiveMinimum`].(float64); is && t <= m {
+			sv.fail(p, `not greater than %v`, m)
+		}
+		if m, is := s[`exclusiveMaximum`].(float64); is && t >= m {
+			sv.fail(p, `not less than %v`, m)
+		}
+		if m, is := s[`multipleOf`].(float64); is && m > 0 {
+			if q := t / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				sv.fail(p, `not a multiple of %v`, m)
+			}
+		}
+
+	case string:
+		n := float64(utf8.RuneCountInString(t))
+		if m, is := s[`minLength`].(float64); is && n < m {
+			sv.fail(p, `shorter than %v`, m)
+		}
+		if m, is := s[`maxLength`].(float64); is && n > m {
+			sv.fail(p, `longer than %v`, m)
+		}
+		if pat, is := s[`pattern`].(string); is {
+			re, has := sv.patterns[pat]
+			var err error
+			if !has {
+				re, err = regexp.Compile(pat)
+			}
+			if err != nil {
+				sv.fail(p, `invalid pattern: %v`, err)
+			} else if !re.MatchString(t) {
+				sv.fail(p, `does not match pattern %s`, pat)
+			}
+		}
+
+	case []any:
+		n := float64(len(t))
+		if m, is := s[`minItems`].(float64); is && n < m {
+			sv.fail(p, `fewer than %v items`, m)
+		}
+		if m, is := s[`maxItems`].(float64); is && n > m {
+			sv.fail(p, `more than %v items`, m)
+		}
+		if s[`uniqueItems`] == true {
+			for i := 1; i < len(t); i++ {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(t[i], t[j]) {
+						sv.fail(p.Index(i), `duplicate of item %d`, j)
+					}
+				}
+			}
+		}
+		if items, has := s[`items`]; has {
+			for i, item := range t {
+				sv.check(items, item, p.Index(i))
+			}
+		}
+
+	case map[string]any:
+		n := float64(len(t))
+		if m, is := s[`minProperties`].(float64); is && n < m {
+			sv.fail(p, `fewer than %v properties`, m)
+		}
+		if m, is := s[`maxProperties`].(float64); is && n > m {
+			sv.fail(p, `more than %v properties`, m)
+		}
+		for _, k := range schemaStrings(s[`required`]) {
+			if _, has := t[k]; !has {
+				sv.fail(p.Key(k), `required`)
+			}
+		}
+		props, _ := s[`properties`].(map[string]any)
+		add, hasAdd := s[`additionalProperties`]
+		for _, k := range sortedAnyKeys(t) {
+			if ps, has := props[k]; has {
+				sv.check(ps, t[k], p.Key(k))
+				continue
+			}
+			if hasAdd {
+				sv.check(add, t[k], p.Key(k))
+			}
+		}
+	}
+}
+
+// sortedAnyKeys returns the keys of the object sorted.
+func sortedAnyKeys(m map[string]any) []string {
+	keys := make(map[string]bool, len(m))
+	for k := range m {
+		keys[k] = true
+	}
+	return sortedKeys(keys)
+}
+
+// jsonType returns the JSON Schema type of the generic value.
+func jsonType(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return `null`
+	case bool:
+		return `boolean`
+	case float64:
+		if t == math.Trunc(t) {
+			return `integer`
+		}
+		return `number`
+	case string:
+		return `string`
+	case []any:
+		return `array`
+	case map[string]any:
+		return `object`
+	}
+	return fmt.Sprintf(`%T`, v)
+}
+
+// typeMatches returns true if the value matches the type keyword
+// (a string or array of them).
+func typeMatches(t any, v any) bool {
+	actual := jsonType(v)
+	for _, name := range schemaTypeList(t) {
+		if name == actual || (name == `number` && actual == `integer`) {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaTypeList(t any) []string {
+	if s, is := t.(string); is {
+		return []string{s}
+	}
+	return schemaStrings(t)
+}
+
+func typeNames(t any) string { return strings.Join(schemaTypeList(t), ` or `) }
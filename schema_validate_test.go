@@ -0,0 +1,39 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleValidateSchema() {
+	schema := []byte(`{
+	  "type": "object",
+	  "properties": {
+	    "name": {"type": "string", "minLength": 1},
+	    "age": {"type": "integer", "minimum": 0},
+	    "tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}}
+	  },
+	  "required": ["name"],
+	  "additionalProperties": false,
+	  "$defs": {"tag": {"enum": ["a", "b"]}}
+	}`)
+
+	fmt.Println(json.ValidateSchema(schema, `{"name":"ann","age":3,"tags":["a"]}`))
+
+	err := json.ValidateSchema(schema, map[string]any{
+		"age":   -1.5,
+		"tags":  []string{"a", "c"},
+		"extra": true,
+	})
+	for _, v := range err.(*json.SchemaError).Violations {
+		fmt.Println(v.Path, v.Msg)
+	}
+
+	// Output:
+	// <nil>
+	// .name required
+	// .age expected integer but got number
+	// .extra not allowed
+	// .tags[1] not one of ["a","b"]
+}
@@ -0,0 +1,80 @@
+package json
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Secret resolves the {{secret "name"}} placeholders in the URL, Query,
+// Header, and Body values of every Request (see Fetch) when it is sent
+// so that request definitions (see Suite) can be committed safely
+// without the secrets themselves. The resolved values are never saved
+// in the Request. Only the placeholders of the request definition
+// itself are resolved, never any in the values filled in from other
+// responses (see RequestGroup and Suite). Set it to look secrets up in
+// any credential store (a keyring, vault, etc.). EnvSecret is used by
+// default.
+var Secret func(name string) (string, error) = EnvSecret
+
+// EnvSecret returns the value of the environment variable with the
+// name upper-cased and with every dash or dot replaced by an underscore
+// (github_token becomes GITHUB_TOKEN, for example) or an error if it
+// is not set.
+func EnvSecret(name string) (string, error) {
+	env := strings.ToUpper(strings.NewReplacer(`-`, `_`, `.`, `_`).Replace(name))
+	v, has := os.LookupEnv(env)
+	if !has {
+		return "", fmt.Errorf(`secret %q not found (set %s)`, name, env)
+	}
+	return v, nil
+}
+
+// secretExp matches a {{secret "name"}} placeholder.
+var secretExp = regexp.MustCompile("{{\\s*secret\\s+(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`)\\s*}}")
+
+// isSecret returns true if the placeholder is for a secret.
+func isSecret(placeholder string) bool { return secretExp.MatchString(placeholder) }
+
+// resolveSecrets returns the string with every secret placeholder
+// replaced by its value (see Secret).
+func resolveSecrets(s string) (string, error) {
+	if !strings.Contains(s, `{{`) {
+		return s, nil
+	}
+	var err error
+	out := secretExp.ReplaceAllStringFunc(s, func(m string) string {
+		name, uerr := strconv.Unquote(secretExp.FindStringSubmatch(m)[1])
+		if uerr != nil {
+			err = uerr
+			return m
+		}
+		v, serr := Secret(name)
+		if serr != nil && err == nil {
+			err = serr
+		}
+		return v
+	})
+	return out, err
+}
+
+// resolveValues is resolveSecrets for every value.
+func resolveValues(in url.Values) (url.Values, error) {
+	if in == nil {
+		return nil, nil
+	}
+	out := url.Values{}
+	for k, vs := range in {
+		for _, v := range vs {
+			r, err := resolveSecrets(v)
+			if err != nil {
+				return nil, err
+			}
+			out.Add(k, r)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"os"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSecret() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			fmt.Fprintf(w, `{"auth":%q,"key":%q}`,
+				r.Header.Get(`Authorization`), r.URL.Query().Get(`key`))
+		}))
+	defer svr.Close()
+
+	os.Setenv(`GITHUB_TOKEN`, `t0ken`)
+	defer os.Unsetenv(`GITHUB_TOKEN`)
+
+	req := &json.Request{
+		URL:    svr.URL,
+		Query:  url.Values{`key`: {`{{secret "api-key"}}`}},
+		Header: map[string]string{`Authorization`: `Bearer {{ secret "github_token" }}`},
+	}
+	var out map[string]string
+	fmt.Println(json.Fetch(req))
+
+	orig := json.Secret
+	defer func() { json.Secret = orig }()
+	json.Secret = func(name string) (string, error) {
+		if name == `api-key` {
+			return `k3y`, nil
+		}
+		return json.EnvSecret(name)
+	}
+	req.URL = svr.URL
+	req.Into = &out
+	fmt.Println(json.Fetch(req), out[`auth`], out[`key`])
+	fmt.Println(req.Header[`Authorization`])
+
+	// Output:
+	// secret "api-key" not found (set API_KEY)
+	// <nil> Bearer t0ken k3y
+	// Bearer {{ secret "github_token" }}
+}
@@ -0,0 +1,67 @@
+package json
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// EncodeSeq writes every value produced by the sequence (which may be
+// an iter.Seq from Go 1.23 or later) to the writer as a single JSON
+// array, pulling values only as they are needed and writing in chunks
+// (see EncodeTo) so that producers never have to build an entire slice
+// first. Encoding stops at the first error (leaving the array
+// incomplete).
+func EncodeSeq[T any](w io.Writer, seq func(yield func(T) bool)) error {
+	return encodeSeq(w, seq, false)
+}
+
+// EncodeLines is the same as EncodeSeq but writes JSON Lines (NDJSON)
+// instead, every value on its own line.
+func EncodeLines[T any](w io.Writer, seq func(yield func(T) bool)) error {
+	return encodeSeq(w, seq, true)
+}
+
+func encodeSeq[T any](w io.Writer, seq func(yield func(T) bool), lines bool) error {
+	e := &encoder{buf: new(bytes.Buffer), utf8: InvalidUTF8, w: w}
+	if !lines {
+		e.buf.WriteByte('[')
+	}
+	var err error
+	n := 0
+	seq(func(v T) bool {
+		if n > 0 && !lines {
+			e.buf.WriteByte(',')
+		}
+		n++
+		if err = e.value(reflect.ValueOf(&v).Elem(), 0); err != nil {
+			return false
+		}
+		if lines {
+			e.buf.WriteByte('\n')
+		}
+		err = e.flush()
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if !lines {
+		e.buf.WriteByte(']')
+	}
+	_, err = w.Write(e.buf.Bytes())
+	return err
+}
+
+// ChanSeq returns a sequence of every value received from the channel
+// (until it is closed) for EncodeSeq and EncodeLines. Values remaining
+// when the sequence is stopped early (by an error) are not received.
+func ChanSeq[T any](ch <-chan T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
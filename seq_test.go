@@ -0,0 +1,48 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleEncodeSeq() {
+	type Row struct {
+		ID int `json:"id"`
+	}
+
+	rows := func(yield func(Row) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(Row{i}) {
+				return
+			}
+		}
+	}
+	json.EncodeSeq(os.Stdout, rows)
+	fmt.Println()
+
+	json.EncodeLines(os.Stdout, rows)
+
+	ch := make(chan any)
+	go func() {
+		defer close(ch)
+		ch <- "one"
+		ch <- 2
+		ch <- nil
+	}()
+	json.EncodeSeq(os.Stdout, json.ChanSeq(ch))
+	fmt.Println()
+
+	empty := func(yield func(int) bool) {}
+	json.EncodeSeq(os.Stdout, empty)
+	fmt.Println()
+
+	// Output:
+	// [{"id":1},{"id":2},{"id":3}]
+	// {"id":1}
+	// {"id":2}
+	// {"id":3}
+	// ["one",2,null]
+	// []
+}
@@ -0,0 +1,150 @@
+package json
+
+import (
+	"reflect"
+	"sync"
+)
+
+// guesses for the encoded size of the things that are not worth the
+// cost of measuring exactly
+const (
+	sizeBool    = 5  // false
+	sizeInt     = 8  // most are small
+	sizeFloat   = 12 // most have some fraction
+	sizeUnknown = 16 // anything rendering itself
+)
+
+// typeSize is the cached size information for a specific type.
+type typeSize struct {
+	fixed int  // bytes known from the type alone
+	exact bool // no need to walk the value (fixed is everything)
+	self  bool // renders itself (MarshalJSON, MarshalText)
+}
+
+var sizeCache sync.Map
+
+// SizeHint estimates the number of bytes the value will require when
+// marshaled so that callers can pre-allocate buffers (reducing the
+// repeated growth and copying when marshaling large batches of data).
+// The size of anything that depends only on the type (struct keys,
+// booleans, numbers) is cached per type and only variable-length
+// values (strings, slices, maps) are walked. The estimate is never
+// exact and deliberately leans toward overestimating so that buffers
+// allocated with it rarely need to grow.
+func SizeHint(v any) int { return sizeOf(reflect.ValueOf(v), 0) }
+
+// sizeOfType returns the cached size information for the type.
+func sizeOfType(t reflect.Type) typeSize {
+	if s, has := sizeCache.Load(t); has {
+		return s.(typeSize)
+	}
+	s := typeSize{fixed: sizeUnknown}
+
+	switch {
+
+	case t.Implements(marshalerType) || t.Implements(textMarshalerType):
+		s.self = true
+
+	case t.Kind() == reflect.Bool:
+		s = typeSize{fixed: sizeBool, exact: true}
+
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uintptr:
+		s = typeSize{fixed: sizeInt, exact: true}
+
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = typeSize{fixed: sizeFloat, exact: true}
+
+	case t.Kind() == reflect.String:
+		s = typeSize{fixed: 2}
+
+	case t.Kind() == reflect.Array:
+		e := sizeOfType(t.Elem())
+		s = typeSize{fixed: 2 + t.Len()*(e.fixed+1), exact: e.exact}
+
+	case t.Kind() == reflect.Struct:
+		s = typeSize{fixed: 2, exact: true}
+		for _, f := range typeFields(t) {
+			s.fixed += len(f.name) + 4 // "":,
+			ft := t.FieldByIndex(f.index).Type
+			fs := sizeOfType(ft)
+			if !fs.exact || f.omitEmpty || len(f.index) > 1 {
+				s.exact = false
+				continue
+			}
+			s.fixed += fs.fixed
+		}
+
+	}
+
+	sizeCache.Store(t, s)
+	return s
+}
+
+// sizeOf estimates the size of a specific reflected value.
+func sizeOf(v reflect.Value, level int) int {
+	if !v.IsValid() {
+		return 4 // null
+	}
+	if level > maxDepth {
+		return 0
+	}
+
+	ts := sizeOfType(v.Type())
+	if ts.exact || ts.self {
+		return ts.fixed
+	}
+
+	switch v.Kind() {
+
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return 4
+		}
+		return sizeOf(v.Elem(), level+1)
+
+	case reflect.String:
+		return v.Len() + 2
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return 4
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Len()*4/3 + 4 // base64
+		}
+		if es := sizeOfType(v.Type().Elem()); es.exact {
+			return 2 + v.Len()*(es.fixed+1)
+		}
+		n := 2
+		for i := 0; i < v.Len(); i++ {
+			n += sizeOf(v.Index(i), level+1) + 1
+		}
+		return n
+
+	case reflect.Map:
+		if v.IsNil() {
+			return 4
+		}
+		n := 2
+		iter := v.MapRange()
+		for iter.Next() {
+			n += sizeOf(iter.Key(), level+1) + 2 // :,
+			n += sizeOf(iter.Value(), level+1)
+		}
+		return n
+
+	case reflect.Struct:
+		n := 2
+		for _, f := range typeFields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			n += len(f.name) + 4 + sizeOf(fv, level+1)
+		}
+		return n
+
+	}
+
+	return ts.fixed
+}
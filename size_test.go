@@ -0,0 +1,31 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSizeHint() {
+
+	type Item struct {
+		ID    int     `json:"id"`
+		Price float64 `json:"price"`
+		Name  string  `json:"name"`
+	}
+
+	items := make([]Item, 1000)
+	for i := range items {
+		items[i] = Item{i, 9.99, "some item"}
+	}
+
+	buf, _ := json.Marshal(items)
+	hint := json.SizeHint(items)
+
+	fmt.Println(hint >= len(buf) && hint < len(buf)*3/2)
+	fmt.Println(json.SizeHint(nil), json.SizeHint("foo"), json.SizeHint(true))
+
+	// Output:
+	// true
+	// 4 5 5
+}
@@ -0,0 +1,52 @@
+package json
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// MarshalSorted is the same as Marshal but always orders the keys of
+// every map with the less function (or lexically if nil) whatever the
+// DefaultEngine so that output compared to golden files never changes
+// from run to run. Fields of structs remain in the order declared and
+// anything that renders itself (MarshalJSON) keeps its own order (see
+// OrderedObject for keys in the order they were added). Keys
+// that are neither less than the other keep the lexical order.
+//
+//     buf, err := json.MarshalSorted(v, json.KeysFirst(`id`, `name`))
+//
+func MarshalSorted(v any, less func(a, b string) bool) ([]byte, error) {
+	return MarshalSortedIndent(v, less, ``, ``)
+}
+
+// MarshalSortedIndent is the same as MarshalSorted but indented (see
+// MarshalIndent).
+func MarshalSortedIndent(v any, less func(a, b string) bool, prefix, indent string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	e := &encoder{buf: buf, prefix: prefix, indent: indent, utf8: InvalidUTF8, less: less}
+	err := e.value(reflect.ValueOf(v), 0)
+	return buf.Bytes(), err
+}
+
+// KeysFirst returns a less function for MarshalSorted that orders the
+// keys given before any others (in the order given) followed by the
+// rest lexically.
+func KeysFirst(keys ...string) func(a, b string) bool {
+	rank := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if _, has := rank[k]; !has {
+			rank[k] = i
+		}
+	}
+	return func(a, b string) bool {
+		ra, hasa := rank[a]
+		rb, hasb := rank[b]
+		switch {
+		case hasa && hasb:
+			return ra < rb
+		case hasa != hasb:
+			return hasa
+		}
+		return a < b
+	}
+}
@@ -0,0 +1,30 @@
+package json_test
+
+import (
+	"fmt"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMarshalSorted() {
+	v := map[string]any{
+		`zeta`: 1, `id`: 2, `alpha`: 3, `name`: 4,
+		`nested`: map[string]int{`b`: 1, `name`: 2, `a`: 3},
+	}
+
+	buf, _ := json.MarshalSorted(v, nil)
+	fmt.Println(string(buf))
+
+	buf, _ = json.MarshalSorted(v, json.KeysFirst(`id`, `name`))
+	fmt.Println(string(buf))
+
+	byLength := func(a, b string) bool { return len(a) < len(b) }
+	buf, _ = json.MarshalSortedIndent(map[string]int{`ccc`: 1, `a`: 2, `bb`: 3, `b`: 4}, byLength, ``, `  `)
+	fmt.Println(strings.ReplaceAll(string(buf), "\n", ` `))
+
+	// Output:
+	// {"alpha":3,"id":2,"name":4,"nested":{"a":3,"b":1,"name":2},"zeta":1}
+	// {"id":2,"name":4,"alpha":3,"nested":{"name":2,"a":3,"b":1},"zeta":1}
+	// {   "a": 2,   "b": 4,   "bb": 3,   "ccc": 1 }
+}
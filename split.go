@@ -0,0 +1,280 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SplitOptions are the options for Split. Exactly one of Count, Size,
+// and Shards must be set.
+type SplitOptions struct {
+	Dir    string // directory for the parts (created if needed)
+	Name   string // of the parts with %d for the number (part-%04d.jsonl)
+	Count  int    // items in each part (the last may have fewer)
+	Size   int64  // most bytes in each part (unless a single item is more)
+	Shards int    // number of parts by hash of the Key of each item
+	Key    string // path (see Path) of the value hashed (whole item if empty)
+	Array  bool   // write each part as a JSON array (not JSON Lines)
+}
+
+// Split writes every item of a top-level array (or values of JSON
+// Lines) from the reader into parts (files) as they are read returning
+// the names of the files written (in order) so that big exports can be
+// processed by parallel jobs. Items are written as they were (never
+// unmarshaled) but compacted to a single line each. Parts are split by
+// the Count of items, their Size, or into a number of Shards by the
+// hash (FNV-1a) of the value at the Key so that all items with the same
+// value are in the same part (even across calls). Parts that would
+// have no items are never written. Join does the reverse:
+//
+//     files, err := json.Split(export, json.SplitOptions{
+//       Dir:    `shards`,
+//       Shards: 8,
+//       Key:    `.customer.id`,
+//     })
+//
+func Split(r io.Reader, opt SplitOptions) ([]string, error) {
+	set := 0
+	for _, n := range []int64{int64(opt.Count), opt.Size, int64(opt.Shards)} {
+		if n > 0 {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf(`split requires exactly one of Count, Size, and Shards`)
+	}
+	var key Path
+	if opt.Key != "" {
+		var err error
+		if key, err = ParsePath(opt.Key); err != nil {
+			return nil, err
+		}
+	}
+	if opt.Name == "" {
+		opt.Name = `part-%04d.jsonl`
+		if opt.Array {
+			opt.Name = `part-%04d.json`
+		}
+	}
+	if opt.Dir != "" {
+		if err := os.MkdirAll(opt.Dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	s := &splitter{opt: opt, parts: map[int]*splitPart{}}
+	err := s.split(r, key)
+	if cerr := s.close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.files, nil
+}
+
+// splitter is the state of Split.
+type splitter struct {
+	opt   SplitOptions
+	parts map[int]*splitPart // open (by number)
+	files []string
+}
+
+// splitPart is a single open part.
+type splitPart struct {
+	f     *os.File
+	w     *bufio.Writer
+	items int
+	size  int64
+}
+
+func (s *splitter) split(r io.Reader, key Path) error {
+	items, err := newItemReader(r)
+	if err != nil {
+		return err
+	}
+	current := 0
+	for i := 0; ; i++ {
+		raw, err := items.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line := new(bytes.Buffer)
+		if err := json.Compact(line, raw); err != nil {
+			return fmt.Errorf(`item %d: %w`, i, err)
+		}
+		n := current
+		switch {
+		case s.opt.Shards > 0:
+			if n, err = s.shard(line.Bytes(), key); err != nil {
+				return fmt.Errorf(`item %d: %w`, i, err)
+			}
+		case s.parts[current] != nil:
+			p := s.parts[current]
+			if s.opt.Count > 0 && p.items >= s.opt.Count ||
+				s.opt.Size > 0 && p.size+int64(line.Len())+2 > s.opt.Size {
+				if err := s.closePart(current); err != nil {
+					return err
+				}
+				current++
+				n = current
+			}
+		}
+		if err := s.write(n, line.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// shard returns the number of the part for the item by the hash of the
+// value at the key.
+func (s *splitter) shard(item []byte, key Path) (int, error) {
+	val := item
+	if key != nil {
+		var v any
+		if err := Unmarshal(item, &v); err != nil {
+			return 0, err
+		}
+		found, err := key.Select(v)
+		if err != nil {
+			return 0, err
+		}
+		var at any
+		if len(found) > 0 {
+			at = found[0]
+		}
+		if val, err = Marshal(at); err != nil {
+			return 0, err
+		}
+	}
+	h := fnv.New32a()
+	h.Write(val)
+	return int(h.Sum32() % uint32(s.opt.Shards)), nil
+}
+
+// write adds the item to the part (opening it if needed).
+func (s *splitter) write(n int, item []byte) error {
+	p := s.parts[n]
+	if p == nil {
+		name := filepath.Join(s.opt.Dir, fmt.Sprintf(s.opt.Name, n))
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		p = &splitPart{f: f, w: bufio.NewWriter(f)}
+		s.parts[n] = p
+		s.files = append(s.files, name)
+	}
+	sep := "\n"
+	if s.opt.Array {
+		sep = ",\n"
+		if p.items == 0 {
+			sep = "[\n"
+		}
+	}
+	if s.opt.Array || p.items > 0 {
+		p.w.WriteString(sep)
+	}
+	p.w.Write(item)
+	p.items++
+	p.size += int64(len(sep) + len(item))
+	return nil
+}
+
+// closePart ends and closes the part.
+func (s *splitter) closePart(n int) error {
+	p := s.parts[n]
+	delete(s.parts, n)
+	end := "\n"
+	if s.opt.Array {
+		end = "\n]\n"
+	}
+	p.w.WriteString(end)
+	err := p.w.Flush()
+	if cerr := p.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// close ends and closes every open part (ordering the files by name
+// for Shards).
+func (s *splitter) close() error {
+	var err error
+	for n := range s.parts {
+		if cerr := s.closePart(n); err == nil {
+			err = cerr
+		}
+	}
+	if s.opt.Shards > 0 {
+		sort.Strings(s.files)
+	}
+	return err
+}
+
+// Join writes every item of every file (arrays or JSON Lines, parts
+// from Split, for example) in order to the writer as JSON Lines (or a
+// single JSON array if array) with each item compacted to a single
+// line.
+func Join(w io.Writer, array bool, files ...string) error {
+	bw := bufio.NewWriter(w)
+	n := 0
+	for _, name := range files {
+		if err := joinFile(bw, name, array, &n); err != nil {
+			return err
+		}
+	}
+	if array {
+		if n == 0 {
+			bw.WriteString("[")
+		}
+		bw.WriteString("\n]\n")
+	}
+	return bw.Flush()
+}
+
+// joinFile writes every item of the file (see Join) counting them.
+func joinFile(w *bufio.Writer, name string, array bool, n *int) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	items, err := newItemReader(f)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, name, err)
+	}
+	for {
+		raw, err := items.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, name, err)
+		}
+		switch {
+		case array && *n == 0:
+			w.WriteString("[\n")
+		case array:
+			w.WriteString(",\n")
+		}
+		line := new(bytes.Buffer)
+		if err := json.Compact(line, raw); err != nil {
+			return fmt.Errorf(`%s: %w`, name, err)
+		}
+		w.Write(line.Bytes())
+		if !array {
+			w.WriteByte('\n')
+		}
+		*n++
+	}
+}
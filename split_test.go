@@ -0,0 +1,63 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSplit() {
+	dir, _ := os.MkdirTemp("", "split")
+	defer os.RemoveAll(dir)
+
+	data := `[
+	  {"id": 1, "user": {"name": "ann"}},
+	  {"id": 2, "user": {"name": "bob"}},
+	  {"id": 3, "user": {"name": "ann"}},
+	  {"id": 4, "user": {"name": "cy"}},
+	  {"id": 5}
+	]`
+
+	files, err := json.Split(strings.NewReader(data), json.SplitOptions{Dir: dir, Count: 2})
+	fmt.Println(len(files), err)
+	buf, _ := os.ReadFile(files[2])
+	fmt.Print(string(buf))
+
+	files, _ = json.Split(strings.NewReader(data), json.SplitOptions{
+		Dir:    filepath.Join(dir, "shards"),
+		Shards: 4,
+		Key:    ".user.name",
+		Array:  true,
+	})
+	for _, f := range files {
+		var items []struct{ ID int }
+		json.Unmarshal(must(os.ReadFile(f)), &items)
+		fmt.Println(filepath.Base(f), items)
+	}
+
+	var out strings.Builder
+	err = json.Join(&out, false, files...)
+	fmt.Println(strings.Count(out.String(), "\n"), err)
+
+	_, err = json.Split(strings.NewReader(data), json.SplitOptions{Count: 2, Size: 10})
+	fmt.Println(err)
+
+	// Output:
+	// 3 <nil>
+	// {"id":5}
+	// part-0000.json [{5}]
+	// part-0001.json [{4}]
+	// part-0002.json [{1} {2} {3}]
+	// 5 <nil>
+	// split requires exactly one of Count, Size, and Shards
+}
+
+func must(buf []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
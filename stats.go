@@ -0,0 +1,84 @@
+package json
+
+import "encoding/json"
+
+// ValueStats is the memory usage report from Stats.
+type ValueStats struct {
+	Objects   int `json:"objects"`
+	Arrays    int `json:"arrays"`
+	Strings   int `json:"strings"` // values only (not keys)
+	Numbers   int `json:"numbers"`
+	Bools     int `json:"bools"`
+	Nulls     int `json:"nulls"`
+	Keys      int `json:"keys"`
+	MaxDepth  int `json:"max_depth"`  // zero for scalars
+	HeapBytes int `json:"heap_bytes"` // approximate (64-bit)
+}
+
+// approximate sizes of the generic decoded form (64-bit) including the
+// interface holding every value
+const (
+	statIface    = 16
+	statString   = 16      // header (plus bytes)
+	statNumber   = 8       // boxed float64
+	statSlice    = 24      // header (plus items)
+	statMap      = 48      // hmap (plus entries)
+	statMapEntry = 16 + 16 // key string header and value interface
+	statMapSlack = 8       // buckets are never full
+)
+
+// Stats returns counts of every kind of value, the maximum depth, and
+// the approximate heap bytes used by the value in generic decoded form
+// (as from Unmarshal into an any) which is useful for deciding when to
+// switch to the streaming APIs instead. Values of other types are
+// converted to generic form first (which is as expensive as decoding
+// them).
+func Stats(v any) ValueStats {
+	var s ValueStats
+	switch v.(type) {
+	case nil, map[string]any, []any, string, float64, bool, json.Number:
+	default:
+		v, _ = generic(v)
+	}
+	s.HeapBytes = statIface + s.add(v, 0)
+	return s
+}
+
+// add adds the value at the depth to the stats returning its heap
+// bytes (not including the interface holding it).
+func (s *ValueStats) add(v any, depth int) int {
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		s.Objects++
+		s.Keys += len(t)
+		n := statMap
+		for k, c := range t {
+			n += statMapEntry + statMapSlack + len(k) + s.add(c, depth+1)
+		}
+		return n
+	case []any:
+		s.Arrays++
+		n := statSlice + cap(t)*statIface
+		for _, c := range t {
+			n += s.add(c, depth+1)
+		}
+		return n
+	case string:
+		s.Strings++
+		return statString + len(t)
+	case float64:
+		s.Numbers++
+		return statNumber
+	case json.Number:
+		s.Numbers++
+		return statString + len(t)
+	case bool:
+		s.Bools++
+	case nil:
+		s.Nulls++
+	}
+	return 0
+}
@@ -0,0 +1,23 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleStats() {
+	var v any
+	json.Unmarshal([]byte(`{"name":"app","ports":[80,443],"tls":true,"owner":null}`), &v)
+	s := json.Stats(v)
+	fmt.Println(s.Objects, s.Arrays, s.Strings, s.Numbers, s.Bools, s.Nulls, s.Keys, s.MaxDepth)
+	fmt.Println(s.HeapBytes > 0)
+
+	big := make([]int, 1000)
+	fmt.Println(json.Stats(big).Numbers, json.Stats(big).HeapBytes > json.Stats(v).HeapBytes)
+
+	// Output:
+	// 1 1 1 2 1 1 4 2
+	// true
+	// 1000 true
+}
@@ -0,0 +1,242 @@
+package json
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store is a content-addressable store of JSON documents saved under
+// the hex CanonicalHash of their content so that identical documents
+// (no matter how they were encoded) are only ever stored once, which
+// makes keeping many similar snapshots (config history, for example)
+// cheap. Every Put adds a reference to the document and every Release
+// removes one. GC deletes those with no references remaining.
+//
+// Documents may also be saved under a key (see PutKey) replacing the
+// previous document for that key, which makes a Store a simple cache
+// (see GetCached).
+//
+// Documents are kept in memory when Dir is empty or as files (in
+// subdirectories named for the first two characters of the ID) within
+// Dir along with the reference counts (refs.json) and keys
+// (keys.json). Stores are safe for concurrent use but not by multiple
+// processes sharing the same Dir.
+type Store struct {
+	Dir string
+
+	mu     sync.Mutex
+	loaded bool
+	refs   map[string]int
+	keys   map[string]storeKey
+	mem    map[string][]byte
+}
+
+// storeKey is the document saved under a key.
+type storeKey struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+// Put saves the value (if not already saved) in canonical form adding
+// a reference to it and returns its ID.
+func (s *Store) Put(v any) (string, error) {
+	buf, err := Canonical(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	id := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	if _, has := s.refs[id]; !has {
+		if err := s.write(id, buf); err != nil {
+			return "", err
+		}
+	}
+	s.refs[id]++
+	return id, s.save()
+}
+
+// PutKey is the same as Put but also saves the ID under the key along
+// with the current time (see GetKey) releasing the document previously
+// saved under it (if any).
+func (s *Store) PutKey(key string, v any) (string, error) {
+	id, err := s.Put(v)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, has := s.keys[key]; has && s.refs[old.ID] > 0 {
+		s.refs[old.ID]--
+	}
+	s.keys[key] = storeKey{id, time.Now()}
+	return id, s.save()
+}
+
+// GetKey unmarshals the document saved under the key (see PutKey) into
+// the value (which must be a pointer) returning when it was saved.
+func (s *Store) GetKey(key string, into any) (time.Time, error) {
+	s.mu.Lock()
+	err := s.load()
+	k, has := s.keys[key]
+	s.mu.Unlock()
+	switch {
+	case err != nil:
+		return time.Time{}, err
+	case !has:
+		return time.Time{}, fmt.Errorf(`no such key: %s`, key)
+	}
+	return k.Time, s.Get(k.ID, into)
+}
+
+// Get unmarshals the document with the ID into the value (which must
+// be a pointer).
+func (s *Store) Get(id string, into any) error {
+	buf, err := s.Raw(id)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(buf, into)
+}
+
+// Raw returns the canonical JSON of the document with the ID.
+func (s *Store) Raw(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if _, has := s.refs[id]; !has {
+		return nil, fmt.Errorf(`no such document: %s`, id)
+	}
+	if s.Dir == "" {
+		return s.mem[id], nil
+	}
+	return os.ReadFile(s.path(id))
+}
+
+// Refs returns the number of references to the document with the ID
+// (zero if it does not exist or is waiting for GC).
+func (s *Store) Refs(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.load() != nil {
+		return 0
+	}
+	return s.refs[id]
+}
+
+// Release removes a reference to the document with the ID. The
+// document remains until the next GC.
+func (s *Store) Release(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if s.refs[id] <= 0 {
+		return fmt.Errorf(`no references to document: %s`, id)
+	}
+	s.refs[id]--
+	return s.save()
+}
+
+// GC deletes every document with no references returning the number
+// deleted.
+func (s *Store) GC() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for id, count := range s.refs {
+		if count > 0 {
+			continue
+		}
+		if s.Dir == "" {
+			delete(s.mem, id)
+		} else if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		delete(s.refs, id)
+		n++
+	}
+	return n, s.save()
+}
+
+// path returns the file path of the document with the ID.
+func (s *Store) path(id string) string {
+	return filepath.Join(s.Dir, id[:2], id+`.json`)
+}
+
+// load loads the reference counts and keys (once).
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+	s.refs = map[string]int{}
+	s.keys = map[string]storeKey{}
+	s.mem = map[string][]byte{}
+	if s.Dir != "" {
+		for name, v := range map[string]any{`refs.json`: &s.refs, `keys.json`: &s.keys} {
+			buf, err := os.ReadFile(filepath.Join(s.Dir, name))
+			switch {
+			case err == nil:
+				if err := Unmarshal(buf, v); err != nil {
+					return err
+				}
+			case !os.IsNotExist(err):
+				return err
+			}
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// save saves the reference counts and keys (each atomically).
+func (s *Store) save() error {
+	if s.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	for name, v := range map[string]any{`refs.json`: s.refs, `keys.json`: s.keys} {
+		buf, err := Marshal(v)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(s.Dir, name)
+		if err := os.WriteFile(file+`.tmp`, buf, 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(file+`.tmp`, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write saves the document with the ID.
+func (s *Store) write(id string, buf []byte) error {
+	if s.Dir == "" {
+		s.mem[id] = buf
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path(id)), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(id), buf, 0600)
+}
@@ -0,0 +1,45 @@
+package json_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleStore() {
+	dir, _ := os.MkdirTemp("", "store")
+	defer os.RemoveAll(dir)
+	store := &json.Store{Dir: filepath.Join(dir, "configs")}
+
+	v1, _ := store.Put(`{"port": 80, "host": "a"}`)
+	v2, _ := store.Put(map[string]any{"host": "a", "port": 80})
+	v3, _ := store.Put(`{"port": 443, "host": "a"}`)
+	fmt.Println(v1 == v2, v1 == v3, store.Refs(v1), store.Refs(v3))
+
+	var cfg struct {
+		Port int `json:"port"`
+	}
+	store.Get(v3, &cfg)
+	fmt.Println(cfg.Port)
+
+	store.Release(v3)
+	store.Release(v1)
+	n, _ := store.GC()
+	fmt.Println(n, store.Refs(v1))
+	_, err := store.Raw(v3)
+	fmt.Println(err != nil)
+
+	// reopen from disk
+	again := &json.Store{Dir: store.Dir}
+	raw, _ := again.Raw(v1)
+	fmt.Println(string(raw))
+
+	// Output:
+	// true false 2 1
+	// 443
+	// 1 1
+	// true
+	// {"host":"a","port":80}
+}
@@ -0,0 +1,54 @@
+package json
+
+import "bytes"
+
+// Stringified is a field whose value is JSON encoded inside of a JSON
+// string (double-encoded) as many APIs do, transparently decoding the
+// string into the Value (of any type) and encoding it back into
+// a string when marshaled. (Unlike a struct tag option, this works
+// with every Engine.)
+//
+//     type Event struct {
+//       Name    string                    `json:"name"`
+//       Payload json.Stringified[Payload] `json:"payload"`
+//     }
+//
+//     // {"name":"push","payload":"{\"ref\":\"main\"}"}
+//     fmt.Println(event.Payload.Value.Ref) // main
+//
+// For leniency, a value that is not a string at all (not
+// double-encoded after all) is decoded as is and an empty string or
+// null leaves the Value alone.
+type Stringified[T any] struct {
+	Value T
+}
+
+// MarshalJSON fulfills the json.Marshaler interface with the Value
+// marshaled (see Marshal) into a string.
+func (s Stringified[T]) MarshalJSON() ([]byte, error) {
+	buf, err := Marshal(s.Value)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(quote(string(buf))), nil
+}
+
+// UnmarshalJSON fulfills the json.Unmarshaler interface decoding the
+// JSON within the string into the Value.
+func (s *Stringified[T]) UnmarshalJSON(buf []byte) error {
+	buf = bytes.TrimSpace(buf)
+	if len(buf) == 0 || buf[0] != '"' {
+		if bytes.Equal(buf, []byte(`null`)) {
+			return nil
+		}
+		return Unmarshal(buf, &s.Value)
+	}
+	var str string
+	if err := Unmarshal(buf, &str); err != nil {
+		return err
+	}
+	if str == `` {
+		return nil
+	}
+	return Unmarshal([]byte(str), &s.Value)
+}
@@ -0,0 +1,40 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleStringified() {
+	type Payload struct {
+		Ref     string   `json:"ref"`
+		Commits []string `json:"commits"`
+	}
+	type Event struct {
+		Name    string                    `json:"name"`
+		Payload json.Stringified[Payload] `json:"payload"`
+	}
+
+	for _, in := range []string{
+		`{"name":"push","payload":"{\"ref\":\"main\",\"commits\":[\"a1\",\"b2\"]}"}`,
+		`{"name":"push","payload":{"ref":"dev"}}`,
+		`{"name":"ping","payload":""}`,
+		`{"name":"bad","payload":"{oops"}`,
+	} {
+		var e Event
+		err := json.Unmarshal([]byte(in), &e)
+		fmt.Println(e.Name, e.Payload.Value.Ref, e.Payload.Value.Commits, err != nil)
+	}
+
+	e := Event{`push`, json.Stringified[Payload]{Payload{Ref: `main`}}}
+	buf, _ := json.Marshal(e)
+	fmt.Println(string(buf))
+
+	// Output:
+	// push main [a1 b2] false
+	// push dev [] false
+	// ping  [] false
+	// bad  [] true
+	// {"name":"push","payload":"{\"ref\":\"main\",\"commits\":null}"}
+}
@@ -0,0 +1,188 @@
+package json
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Suite is a declarative sequence of requests (like a lightweight
+// Postman collection or Hurl file) usually loaded from a JSON or YAML
+// file (see LoadSuite):
+//
+//     vars:
+//       api: https://api.example.com
+//     steps:
+//       - name: login
+//         method: POST
+//         url: "{{.api}}/login"
+//         body: {user: ann}
+//         extract: {token: .token}
+//         assert:
+//           - {query: .ok, equals: true}
+//       - name: me
+//         url: "{{.api}}/users/me"
+//         header: {Authorization: "Bearer {{.token}}"}
+//         assert:
+//           - {query: .name, matches: "^ann"}
+//
+// Every step is sent (in order) with Fetch after replacing the
+// {{placeholders}} (see RequestGroup) in its URL, Query, Header, and
+// Body with the Vars (including those extracted by earlier steps).
+// Secrets should never be in the file but referred to with
+// {{secret "name"}} placeholders instead (see Secret).
+type Suite struct {
+	Vars  map[string]any `json:"vars,omitempty"`
+	Steps []SuiteStep    `json:"steps"`
+}
+
+// SuiteStep is a single request of a Suite. Extract maps variable
+// names to queries (a Path or jq expression, see QueryEach) of the
+// response. Body is sent as form values (see Request).
+type SuiteStep struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Query   map[string]string `json:"query,omitempty"`
+	Header  map[string]string `json:"header,omitempty"`
+	Body    map[string]string `json:"body,omitempty"`
+	Extract map[string]string `json:"extract,omitempty"`
+	Assert  []SuiteAssert     `json:"assert,omitempty"`
+}
+
+// SuiteAssert is an assertion about the response of a SuiteStep. The
+// Query (a Path or jq expression) must select exactly one value that
+// must equal Equals (if set) and (if set) match the Matches regular
+// expression (as JSON unless a string). Without either the value must
+// only exist.
+type SuiteAssert struct {
+	Query   string `json:"query"`
+	Equals  any    `json:"equals,omitempty"`
+	Matches string `json:"matches,omitempty"`
+}
+
+// SuiteError is returned by Run for the first step that fails.
+type SuiteError struct {
+	Step     string   `json:"step"`
+	Failures []string `json:"failures"`
+}
+
+// Error fulfills the error interface.
+func (e *SuiteError) Error() string {
+	return fmt.Sprintf(`step %s failed: %s`, e.Step, strings.Join(e.Failures, `; `))
+}
+
+// LoadSuite loads the Suite from the file, which must be JSON or (when
+// it ends with .yaml or .yml) YAML.
+func LoadSuite(file string) (*Suite, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Suite)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case `.yaml`, `.yml`:
+		err = Decode(`application/yaml`, buf, s)
+	default:
+		err = Unmarshal(buf, s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, file, err)
+	}
+	return s, nil
+}
+
+// Run runs every step of the Suite in order stopping at the first that
+// fails (with a *SuiteError) and returns the variables (the Vars and
+// those extracted).
+func (s *Suite) Run() (map[string]any, error) {
+	vars := map[string]any{}
+	for k, v := range s.Vars {
+		vars[k] = v
+	}
+	for i, step := range s.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprint(i + 1)
+		}
+		if fails := step.run(vars); fails != nil {
+			return vars, &SuiteError{name, fails}
+		}
+	}
+	return vars, nil
+}
+
+// run sends the request of the step checking its assertions and adding
+// any extracted variables returning the failures (if any).
+func (step SuiteStep) run(vars map[string]any) []string {
+	values := func(m map[string]string) url.Values {
+		if m == nil {
+			return nil
+		}
+		v := url.Values{}
+		for k, s := range m {
+			v.Set(k, s)
+		}
+		return v
+	}
+	// secrets resolve only in the step itself, never in the (response)
+	// values of the variables (see fill)
+	it, err := (&Request{
+		Method: step.Method,
+		URL:    step.URL,
+		Query:  values(step.Query),
+		Header: step.Header,
+		Body:   values(step.Body),
+	}).fill(vars)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var res any
+	it.Into = &res
+	if err := Fetch(it); err != nil {
+		return []string{err.Error()}
+	}
+
+	var fails []string
+	for _, a := range step.Assert {
+		if err := a.check(res); err != nil {
+			fails = append(fails, err.Error())
+		}
+	}
+	names := make([]string, 0, len(step.Extract))
+	for k := range step.Extract {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		v, err := selectOne(step.Extract[k], res)
+		if err != nil {
+			fails = append(fails, fmt.Sprintf(`extract %s: %v`, k, err))
+			continue
+		}
+		vars[k] = v
+	}
+	return fails
+}
+
+// check returns an error if the assertion fails for the response.
+func (a SuiteAssert) check(res any) error {
+	if a.Matches != "" {
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return err
+		}
+		if err := assertOne(a.Query, res, re); err != nil {
+			return err
+		}
+	}
+	if a.Equals != nil {
+		return assertOne(a.Query, res, a.Equals)
+	}
+	_, err := selectOne(a.Query, res)
+	return err
+}
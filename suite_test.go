@@ -0,0 +1,103 @@
+package json_test
+
+import (
+	"fmt"
+	_http "net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleSuite() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch r.URL.Path {
+			case `/login`:
+				r.ParseForm()
+				fmt.Fprintf(w, `{"ok":true,"token":"t-%s"}`, r.Form.Get(`user`))
+			case `/users/me`:
+				fmt.Fprintf(w, `{"name":"ann","auth":%q}`, r.Header.Get(`Authorization`))
+			}
+		}))
+	defer svr.Close()
+
+	dir, _ := os.MkdirTemp("", "suite")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "suite.json")
+	os.WriteFile(file, []byte(`{
+	  "vars": {"api": "`+svr.URL+`"},
+	  "steps": [
+	    {
+	      "name": "login",
+	      "method": "POST",
+	      "url": "{{.api}}/login",
+	      "body": {"user": "ann"},
+	      "extract": {"token": ".token"},
+	      "assert": [{"query": ".ok", "equals": true}]
+	    },
+	    {
+	      "name": "me",
+	      "url": "{{.api}}/users/me",
+	      "header": {"Authorization": "Bearer {{.token}}"},
+	      "assert": [
+	        {"query": ".auth", "equals": "Bearer t-ann"},
+	        {"query": ".name", "matches": "^bob"}
+	      ]
+	    }
+	  ]
+	}`), 0600)
+
+	suite, err := json.LoadSuite(file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	vars, err := suite.Run()
+	fmt.Println(vars["token"])
+	fmt.Println(err)
+
+	// Output:
+	// t-ann
+	// step me failed: .name is "ann", does not match ^bob
+}
+
+func ExampleSuite_secrets() {
+	svr := ht.NewServer(_http.HandlerFunc(
+		func(w _http.ResponseWriter, r *_http.Request) {
+			switch r.URL.Path {
+			case `/evil`:
+				fmt.Fprint(w, `{"name":"x{{secret \"github_token\"}}"}`)
+			case `/echo`:
+				fmt.Fprintf(w, `{"q":%q,"auth":%q}`,
+					r.URL.Query().Get(`q`), r.Header.Get(`Authorization`))
+			}
+		}))
+	defer svr.Close()
+
+	os.Setenv(`GITHUB_TOKEN`, `s3cr3t`)
+	defer os.Unsetenv(`GITHUB_TOKEN`)
+
+	suite := &json.Suite{
+		Vars: map[string]any{`api`: svr.URL},
+		Steps: []json.SuiteStep{
+			{URL: `{{.api}}/evil`, Extract: map[string]string{`name`: `.name`}},
+			{
+				URL:     `{{.api}}/echo`,
+				Query:   map[string]string{`q`: `{{.name}}`},
+				Header:  map[string]string{`Authorization`: `Bearer {{secret "github_token"}}`},
+				Extract: map[string]string{`q`: `.q`, `auth`: `.auth`},
+			},
+		},
+	}
+	vars, err := suite.Run()
+	fmt.Println(err)
+	fmt.Println(vars[`q`])
+	fmt.Println(vars[`auth`])
+
+	// Output:
+	// <nil>
+	// x{{secret "github_token"}}
+	// Bearer s3cr3t
+}
@@ -0,0 +1,163 @@
+package json
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Value tag options change how the value of a field is written in JSON
+// whatever the Engine: the unix options of time fields (see epochUnit)
+// and the scale and round options of Decimal fields (see decimalOpts).
+// Values of types reaching any are always marshaled by the encoder of
+// this package and the JSON unmarshaled into them is first rewritten
+// (see untag) into what any Engine decodes the same.
+var (
+	unmarshalerType   = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+var valueOptTypes sync.Map
+
+// hasValueOpts returns true if the type (at any depth) reaches a field
+// with a value tag option that applies to it.
+func hasValueOpts(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if has, done := valueOptTypes.Load(t); done {
+		return has.(bool)
+	}
+	has := valueOptsIn(t, map[reflect.Type]bool{})
+	valueOptTypes.Store(t, has)
+	return has
+}
+
+func valueOptsIn(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if seen[t] || reflect.PointerTo(t).Implements(unmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalType) {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, f := range typeFields(t) {
+			ft := t.FieldByIndex(f.index).Type
+			if f.epoch != 0 && (ft == timeType || ft == reflect.PointerTo(timeType)) {
+				return true
+			}
+			if f.decimal.applies(ft) {
+				return true
+			}
+			if valueOptsIn(ft, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return valueOptsIn(t.Elem(), seen)
+	}
+	return false
+}
+
+// untag returns the JSON with the value of every field of the type with
+// a value tag option rewritten as the field would have it without: the
+// RFC 3339 string of epoch times and the rounded string of decimals.
+func untag(buf []byte, t reflect.Type) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return buf, nil // let the Engine report it
+	}
+	v, err := untagValue(v, t, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := new(bytes.Buffer)
+	err = (&encoder{buf: out, utf8: InvalidUTF8}).value(reflect.ValueOf(v), 0)
+	return out.Bytes(), err
+}
+
+func untagValue(v any, t reflect.Type, depth int) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if depth > maxDepth || !hasValueOpts(t) {
+		return v, nil
+	}
+	var err error
+	switch t.Kind() {
+	case reflect.Struct:
+		m, is := v.(map[string]any)
+		if !is {
+			return v, nil
+		}
+		fields := typeFields(t)
+		for k, fv := range m {
+			f, ok := fieldNamed(fields, k)
+			if !ok {
+				continue
+			}
+			ft := t.FieldByIndex(f.index).Type
+			n, isnum := fv.(json.Number)
+			if f.epoch != 0 && isnum && (ft == timeType || ft == reflect.PointerTo(timeType)) {
+				if tm, ok := epochTime(n, f.epoch); ok {
+					m[k] = tm.Format(time.RFC3339Nano)
+				}
+				continue
+			}
+			if f.decimal.applies(ft) {
+				if m[k], err = f.decimal.decode(f.name, ft, fv); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if m[k], err = untagValue(fv, ft, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if list, is := v.([]any); is {
+			for i, item := range list {
+				if list[i], err = untagValue(item, t.Elem(), depth+1); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case reflect.Map:
+		if m, is := v.(map[string]any); is {
+			for k, item := range m {
+				if m[k], err = untagValue(item, t.Elem(), depth+1); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return v, nil
+}
+
+// fieldNamed returns the field the key is decoded into the same as
+// encoding/json (exact name first, then ignoring case).
+func fieldNamed(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
@@ -0,0 +1,157 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Transcoder encodes and decodes a specific content (MIME) type to and
+// from any Go value (observing the usual json struct tags). See
+// RegisterTranscoder.
+type Transcoder struct {
+	Encode func(v any) ([]byte, error)
+	Decode func(buf []byte, v any) error
+}
+
+var transcoders = struct {
+	sync.RWMutex
+	m map[string]Transcoder
+}{m: map[string]Transcoder{}}
+
+func init() {
+	js := Transcoder{Marshal, Unmarshal}
+	RegisterTranscoder(`application/json`, js)
+	RegisterTranscoder(`text/json`, js)
+	nd := Transcoder{MarshalNDJSON, UnmarshalNDJSON}
+	RegisterTranscoder(`application/x-ndjson`, nd)
+	RegisterTranscoder(`application/jsonl`, nd)
+}
+
+// RegisterTranscoder adds (or replaces) the Transcoder for the given
+// content type (parameters such as charset are ignored). Fetch uses
+// these to decode responses according to their Content-Type and they
+// are available for any other dispatching as well (see Encode and
+// Decode). The following are registered by default:
+//
+//     application/json
+//     text/json
+//     application/x-ndjson
+//     application/jsonl
+//     application/cbor
+//     application/yaml
+//     text/yaml
+//
+// Any type with a +json suffix (application/vnd.foo+json, for example)
+// that is not registered is handled as application/json.
+func RegisterTranscoder(ctype string, t Transcoder) {
+	transcoders.Lock()
+	defer transcoders.Unlock()
+	transcoders.m[mediaType(ctype)] = t
+}
+
+// TranscoderFor returns the Transcoder registered for the content type
+// (which may include parameters, as from a Content-Type header) and
+// false if there is none.
+func TranscoderFor(ctype string) (Transcoder, bool) {
+	mt := mediaType(ctype)
+	transcoders.RLock()
+	defer transcoders.RUnlock()
+	t, has := transcoders.m[mt]
+	if !has && strings.HasSuffix(mt, `+json`) {
+		t, has = transcoders.m[`application/json`]
+	}
+	return t, has
+}
+
+// mediaType returns the lowercase media type without any parameters.
+func mediaType(ctype string) string {
+	mt, _, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		mt, _, _ = strings.Cut(ctype, `;`)
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// Encode encodes the value using the Transcoder registered for the
+// content type.
+func Encode(ctype string, v any) ([]byte, error) {
+	t, has := TranscoderFor(ctype)
+	if !has {
+		return nil, fmt.Errorf(`no transcoder for %q`, ctype)
+	}
+	return t.Encode(v)
+}
+
+// Decode decodes the data into the value (passed by pointer) using the
+// Transcoder registered for the content type.
+func Decode(ctype string, buf []byte, v any) error {
+	t, has := TranscoderFor(ctype)
+	if !has {
+		return fmt.Errorf(`no transcoder for %q`, ctype)
+	}
+	return t.Decode(buf, v)
+}
+
+// MarshalNDJSON marshals every item of the slice (or array) passed as
+// a single line of JSON (newline delimited JSON, JSON Lines). Anything
+// else is marshaled as a single line.
+func MarshalNDJSON(v any) ([]byte, error) {
+	out := new(bytes.Buffer)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		buf, err := Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(buf)
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		buf, err := Marshal(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		out.Write(buf)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// UnmarshalNDJSON unmarshals newline delimited JSON (JSON Lines) into
+// the slice (or any) passed by pointer. Every line is unmarshaled on
+// its own (so that a line with more than one value is never accepted)
+// and any error is a *LineError. Blank lines are ignored.
+func UnmarshalNDJSON(buf []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf(`json: ndjson requires a non-nil pointer (not %T)`, v)
+	}
+	target := rv.Elem()
+	list := target
+	switch {
+	case target.Kind() == reflect.Slice:
+		list = reflect.MakeSlice(target.Type(), 0, 0)
+	case target.Kind() == reflect.Interface && target.NumMethod() == 0:
+		list = reflect.ValueOf([]any{})
+	default:
+		return fmt.Errorf(`json: ndjson requires a pointer to a slice (not %T)`, v)
+	}
+	for i, line := range bytes.Split(buf, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		item := reflect.New(list.Type().Elem())
+		if err := Unmarshal(line, item.Interface()); err != nil {
+			return &LineError{Line: i + 1, Err: err}
+		}
+		list = reflect.Append(list, item.Elem())
+	}
+	target.Set(list)
+	return nil
+}
@@ -0,0 +1,86 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDecode() {
+	type Item struct {
+		Name string `json:"name"`
+	}
+
+	var items []Item
+	err := json.Decode(
+		"application/x-ndjson; charset=utf-8",
+		[]byte("{\"name\":\"foo\"}\n\n{\"name\":\"bar\"}\n"),
+		&items,
+	)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(items)
+
+	var item Item
+	err = json.Decode("application/vnd.foo.v2+json", []byte(`{"name":"baz"}`), &item)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(item)
+
+	fmt.Println(json.Decode("image/png", nil, &item))
+
+	// Output:
+	// [{foo} {bar}]
+	// {baz}
+	// no transcoder for "image/png"
+}
+
+func ExampleUnmarshalNDJSON() {
+	var nums []int
+	err := json.UnmarshalNDJSON([]byte("1\n2\n\n3\n"), &nums)
+	fmt.Println(nums, err)
+
+	err = json.UnmarshalNDJSON([]byte("1\n2\n\n3,4\n"), &nums)
+	fmt.Println(err)
+
+	var v any
+	err = json.UnmarshalNDJSON([]byte("{\"a\":1}\n\"b\"\n"), &v)
+	fmt.Println(v, err)
+
+	// Output:
+	// [1 2 3] <nil>
+	// line 4: invalid character ',' after top-level value
+	// [map[a:1] b] <nil>
+}
+
+func ExampleEncode() {
+	buf, err := json.Encode("application/x-ndjson", []any{1, "two", []int{3}})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(string(buf))
+
+	// Output:
+	// 1
+	// "two"
+	// [3]
+}
+
+func ExampleRegisterTranscoder() {
+	json.RegisterTranscoder("text/x-reversed", json.Transcoder{
+		Encode: func(v any) ([]byte, error) {
+			buf, err := json.Marshal(v)
+			for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+				buf[i], buf[j] = buf[j], buf[i]
+			}
+			return buf, err
+		},
+	})
+	buf, _ := json.Encode("text/x-reversed", []int{1, 2, 3})
+	fmt.Println(string(buf))
+
+	// Output:
+	// ]3,2,1[
+}
@@ -0,0 +1,202 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func init() {
+	y := Transcoder{MarshalYAML, UnmarshalYAML}
+	RegisterTranscoder(`application/yaml`, y)
+	RegisterTranscoder(`application/x-yaml`, y)
+	RegisterTranscoder(`text/yaml`, y)
+}
+
+// MarshalYAML marshals the value as JSON first (so that the usual json
+// struct tags are observed) and then converts that to YAML preserving
+// the order of keys (see ToYAML).
+func MarshalYAML(v any) ([]byte, error) {
+	buf, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return ToYAML(buf)
+}
+
+// ToYAML converts the JSON to YAML in block style (strings quoted only
+// when needed) preserving the order of keys and the exact
+// representation of numbers.
+func ToYAML(buf []byte) ([]byte, error) {
+	buf, err := ToUTF8(buf)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(buf) {
+		var v any
+		return nil, Unmarshal(buf, &v)
+	}
+	node := new(yaml.Node)
+	if err := yaml.Unmarshal(buf, node); err != nil {
+		return nil, err
+	}
+	plainStyle(node)
+	return yaml.Marshal(node)
+}
+
+// plainStyle removes the flow and quoting styles from the nodes parsed
+// from JSON so that the YAML looks like YAML (strings are still quoted
+// whenever needed).
+func plainStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		plainStyle(n)
+	}
+}
+
+// UnmarshalYAML converts the YAML to JSON (see FromYAML) and then
+// unmarshals it into the value (passed by pointer) so that the usual
+// json struct tags are observed.
+func UnmarshalYAML(buf []byte, v any) error {
+	js, err := FromYAML(buf)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(js, v)
+}
+
+// maxYAMLNodes limits the nodes written by FromYAML so that aliases
+// repeated within aliases (a billion laughs) cannot exhaust memory.
+const maxYAMLNodes = 1 << 20
+
+// FromYAML converts the YAML (the first document only) to compact JSON
+// preserving the order of keys. Aliases are expanded, merge keys (<<)
+// are observed, keys that are not strings become strings, timestamps
+// become RFC 3339 strings, and numbers are written in JSON form (0x1F
+// and 1_000 become 31 and 1000). Anything JSON cannot hold (.inf, .nan,
+// or a key that is a mapping or sequence) is an error.
+func FromYAML(buf []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, err
+	}
+	y := &yamlJSON{}
+	if err := y.node(&doc, 0); err != nil {
+		return nil, err
+	}
+	return y.out.Bytes(), nil
+}
+
+// yamlJSON is the state of FromYAML writing JSON as it walks the nodes.
+type yamlJSON struct {
+	out   bytes.Buffer
+	nodes int
+}
+
+func (y *yamlJSON) node(n *yaml.Node, depth int) error {
+	if y.nodes++; y.nodes > maxYAMLNodes {
+		return fmt.Errorf(`YAML expands to more than %d nodes`, maxYAMLNodes)
+	}
+	if depth > maxDepth {
+		return fmt.Errorf(`YAML nesting too deep`)
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			y.out.WriteString(`null`)
+			return nil
+		}
+		return y.node(n.Content[0], depth)
+	case yaml.AliasNode:
+		return y.node(n.Alias, depth+1)
+	case yaml.SequenceNode:
+		y.out.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				y.out.WriteByte(',')
+			}
+			if err := y.node(c, depth+1); err != nil {
+				return err
+			}
+		}
+		y.out.WriteByte(']')
+		return nil
+	case yaml.MappingNode:
+		y.out.WriteByte('{')
+		if _, err := y.pairs(n, depth, 0); err != nil {
+			return err
+		}
+		y.out.WriteByte('}')
+		return nil
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	buf, err := Marshal(v)
+	if err != nil {
+		return fmt.Errorf(`YAML line %d: %w`, n.Line, err)
+	}
+	y.out.Write(buf)
+	return nil
+}
+
+// pairs writes the key/value pairs of the mapping (after those merged
+// into it so that its own take precedence when unmarshaled) returning
+// the count written so far.
+func (y *yamlJSON) pairs(n *yaml.Node, depth, count int) (int, error) {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return count, fmt.Errorf(`YAML line %d: merge of a non-mapping`, n.Line)
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind != yaml.ScalarNode {
+			return count, fmt.Errorf(`YAML line %d: key is not a scalar`, k.Line)
+		}
+		var err error
+		if k.Tag == `!!merge` {
+			merged := []*yaml.Node{v}
+			if v.Kind == yaml.SequenceNode {
+				merged = v.Content
+			}
+			for _, m := range merged {
+				if count, err = y.pairs(m, depth+1, count); err != nil {
+					return count, err
+				}
+			}
+			continue
+		}
+		if count > 0 {
+			y.out.WriteByte(',')
+		}
+		count++
+		y.out.WriteString(quote(k.Value) + `:`)
+		if err = y.node(v, depth+1); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// YAML returns the value as YAML (see MarshalYAML).
+func (s This) YAML() ([]byte, error) { return MarshalYAML(s.This) }
+
+// StringYAML is the same as String but YAML (see MarshalYAML). Any error
+// is logged.
+func (s This) StringYAML() string {
+	buf, err := s.YAML()
+	if err != nil {
+		log.Print(err)
+	}
+	return string(buf)
+}
+
+// PrintYAML prints the value as YAML (see MarshalYAML) which already
+// ends with a line return. Any error is logged.
+func (s This) PrintYAML() { fmt.Print(s.StringYAML()) }
@@ -0,0 +1,92 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleMarshalYAML() {
+	data := struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Truth string   `json:"truth"`
+	}{"foo", []string{"a", "b"}, "true"}
+
+	buf, err := json.MarshalYAML(data)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(string(buf))
+
+	// Output:
+	// name: foo
+	// tags:
+	//     - a
+	//     - b
+	// truth: "true"
+}
+
+func ExampleUnmarshalYAML() {
+	var data struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	err := json.UnmarshalYAML([]byte("name: foo\ntags: [a, b]\n"), &data)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(data.Name, data.Tags)
+
+	// Output:
+	// foo [a b]
+}
+
+func ExampleFromYAML() {
+	buf, err := json.FromYAML([]byte(`
+defaults: &defaults
+  port: 0x1F90
+  debug: false
+prod:
+  <<: *defaults
+  debug: true
+  hosts: [a, b]
+  since: 2024-01-02
+  1: one
+`))
+	fmt.Println(string(buf), err)
+
+	var v struct {
+		Prod struct {
+			Port  int  `json:"port"`
+			Debug bool `json:"debug"`
+		} `json:"prod"`
+	}
+	fmt.Println(json.Unmarshal(buf, &v), v.Prod.Port, v.Prod.Debug)
+
+	_, err = json.FromYAML([]byte("x: .inf\n"))
+	fmt.Println(err)
+
+	// Output:
+	// {"defaults":{"port":8080,"debug":false},"prod":{"port":8080,"debug":false,"debug":true,"hosts":["a","b"],"since":"2024-01-02T00:00:00Z","1":"one"}} <nil>
+	// <nil> 8080 true
+	// YAML line 1: json: unsupported value: +Inf
+}
+
+func ExampleToYAML() {
+	out, err := json.ToYAML([]byte(`{"z":1.50,"a":[true,null],"s":"true"}`))
+	fmt.Print(string(out))
+	fmt.Println(err)
+
+	json.This{map[string]int{"b": 2, "a": 1}}.PrintYAML()
+
+	// Output:
+	// z: 1.50
+	// a:
+	//     - true
+	//     - null
+	// s: "true"
+	// <nil>
+	// a: 1
+	// b: 2
+}
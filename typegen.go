@@ -0,0 +1,368 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SchemaFormats maps the string formats of JSON Schema to the Go types
+// used by GenerateTypes (with the full import path before the type
+// name for those of other packages). Formats not here are strings.
+var SchemaFormats = map[string]string{
+	`date-time`: `time.Time`,
+	`uuid`:      `github.com/google/uuid.UUID`,
+}
+
+// GenerateTypes returns formatted Go source code (for the named
+// package) with the named type for the JSON Schema (and every type it
+// contains) so that published API schemas turn directly into types
+// used with this package. Typically called from a small program run by
+// go:generate. Objects with properties become structs (fields in the
+// order of the schema) with required properties as values, others and
+// those that are nullable (OpenAPI nullable or type with null) as
+// pointers (slices, maps, and any as is) and omitempty:
+//
+//     type User struct {
+//       ID      int64      `json:"id"`
+//       Name    string     `json:"name"`
+//       Email   *string    `json:"email,omitempty"`
+//       Role    UserRole   `json:"role"`
+//       Created time.Time  `json:"created"`
+//     }
+//
+// String enums become named types with a constant for every value
+// (UserRoleAdmin, for example), formats the types of SchemaFormats,
+// and objects with only additionalProperties maps. Nested object and
+// enum types are named after their parents and fields (UserAddress,
+// for example) and those referred to ($ref to #/...) after the last
+// part of the reference. Descriptions become comments.
+func GenerateTypes(pkg, name string, schema []byte) ([]byte, error) {
+	var s any
+	if err := Unmarshal(schema, &s); err != nil {
+		return nil, err
+	}
+	a := newAnnotator(schema, s)
+	g := &typeGen{
+		a:       a,
+		names:   map[string]bool{},
+		refs:    map[string]string{},
+		imports: map[string]bool{},
+	}
+	m, at := a.resolve(s, Path{})
+	g.define(g.unique(goName(name)), m, at)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by json.GenerateTypes; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for p := range g.imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		b.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+		b.WriteString(")\n\n")
+	}
+	for _, d := range g.decls {
+		b.WriteString(d + "\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+type typeGen struct {
+	a       *annotator
+	decls   []string
+	names   map[string]bool   // type names used
+	refs    map[string]string // type names of references
+	imports map[string]bool
+}
+
+// unique returns the type name (with a number added if already used)
+// marking it as used.
+func (g *typeGen) unique(name string) string {
+	n := name
+	for i := 2; g.names[n]; i++ {
+		n = fmt.Sprintf(`%s%d`, name, i)
+	}
+	g.names[n] = true
+	return n
+}
+
+// goType returns the Go type of the schema at the Path (defining any
+// new named types with the name suggested) and whether it is nullable.
+func (g *typeGen) goType(schema any, at Path, name string) (string, bool) {
+	m, is := schema.(map[string]any)
+	if !is {
+		return `any`, false
+	}
+	nullable := schemaNullable(m)
+	if ref, is := m[`$ref`].(string); is && strings.HasPrefix(ref, `#`) {
+		s, sat := g.a.resolve(m, at)
+		if n, has := g.refs[ref]; has {
+			return n, nullable || schemaNullable(s)
+		}
+		n := g.unique(goName(ref[strings.LastIndex(ref, `/`)+1:]))
+		g.refs[ref] = n
+		g.define(n, s, sat)
+		return n, nullable || schemaNullable(s)
+	}
+	if schemaNamed(m) {
+		n := g.unique(name)
+		g.define(n, m, at)
+		return n, nullable
+	}
+	return g.inline(m, at, name), nullable
+}
+
+// schemaNullable returns true if the schema allows null.
+func schemaNullable(s map[string]any) bool {
+	if s[`nullable`] == true {
+		return true
+	}
+	for _, t := range schemaTypeList(s[`type`]) {
+		if t == `null` {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaNamed returns true if the schema needs a named type (struct or
+// enum).
+func schemaNamed(s map[string]any) bool {
+	if _, has := s[`properties`]; has {
+		return true
+	}
+	if _, has := s[`allOf`]; has {
+		return true
+	}
+	return len(schemaEnumStrings(s)) > 0
+}
+
+// schemaEnumStrings returns the enum values if all are strings.
+func schemaEnumStrings(s map[string]any) []string {
+	enum, _ := s[`enum`].([]any)
+	var out []string
+	for _, v := range enum {
+		str, is := v.(string)
+		if !is {
+			return nil
+		}
+		out = append(out, str)
+	}
+	return out
+}
+
+// inline returns the Go type of the schema when it needs no named type
+// of its own.
+func (g *typeGen) inline(s map[string]any, at Path, name string) string {
+	typ := ``
+	for _, t := range schemaTypeList(s[`type`]) {
+		if t == `null` {
+			continue
+		}
+		if typ != `` {
+			return `any`
+		}
+		typ = t
+	}
+	format, _ := s[`format`].(string)
+	switch typ {
+	case `string`:
+		if t, has := SchemaFormats[format]; has {
+			return g.qualified(t)
+		}
+		return `string`
+	case `integer`:
+		switch format {
+		case `int32`:
+			return `int32`
+		case `int64`:
+			return `int64`
+		}
+		return `int`
+	case `number`:
+		if format == `float` {
+			return `float32`
+		}
+		return `float64`
+	case `boolean`:
+		return `bool`
+	case `array`:
+		t, _ := g.goType(s[`items`], at.Key(`items`), name+`Item`)
+		return `[]` + t
+	case `object`, ``:
+		if add, is := s[`additionalProperties`].(map[string]any); is {
+			t, _ := g.goType(add, at.Key(`additionalProperties`), name+`Value`)
+			return `map[string]` + t
+		}
+		if typ == `object` {
+			return `map[string]any`
+		}
+	}
+	return `any`
+}
+
+// qualified returns the Go type (with the full import path if from
+// another package) as used in the code, adding its import.
+func (g *typeGen) qualified(t string) string {
+	dot := strings.LastIndex(t, `.`)
+	if dot < 0 {
+		return t
+	}
+	path := t[:dot]
+	g.imports[path] = true
+	return path[strings.LastIndex(path, `/`)+1:] + t[dot:]
+}
+
+// define adds the declaration of the named type for the (resolved)
+// schema at the Path.
+func (g *typeGen) define(name string, s map[string]any, at Path) {
+	i := len(g.decls)
+	g.decls = append(g.decls, ``)
+	var b strings.Builder
+	b.WriteString(typeComment(name, s, ``))
+
+	if enum := schemaEnumStrings(s); len(enum) > 0 {
+		fmt.Fprintf(&b, "type %s string\n\n", name)
+		fmt.Fprintf(&b, "// Values of %s.\nconst (\n", name)
+		for _, v := range enum {
+			fmt.Fprintf(&b, "\t%s %s = %q\n", g.unique(name+goName(v)), name, v)
+		}
+		b.WriteString(")\n")
+		g.decls[i] = b.String()
+		return
+	}
+
+	if !schemaNamed(s) {
+		fmt.Fprintf(&b, "type %s %s\n", name, g.inline(s, at, name))
+		g.decls[i] = b.String()
+		return
+	}
+
+	required := map[string]bool{}
+	var collect func(s map[string]any, at Path, depth int)
+	collect = func(s map[string]any, at Path, depth int) {
+		if s == nil || depth > maxDepth {
+			return
+		}
+		for _, k := range schemaStrings(s[`required`]) {
+			required[k] = true
+		}
+		all, _ := s[`allOf`].([]any)
+		for n, sub := range all {
+			rs, rp := g.a.resolve(sub, at.Key(`allOf`).Index(n))
+			collect(rs, rp, depth+1)
+		}
+	}
+	collect(s, at, 0)
+
+	keys, props, paths := g.a.properties(s, at)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	used := map[string]bool{}
+	for _, k := range keys {
+		field := goName(k)
+		for used[field] {
+			field += `_`
+		}
+		used[field] = true
+		t, nullable := g.goType(props[k], paths[k], name+field)
+		tag := k
+		if !required[k] {
+			tag += `,omitempty`
+		}
+		if (nullable || !required[k]) && pointerable(t) {
+			t = `*` + t
+		}
+		if ps, is := props[k].(map[string]any); is {
+			b.WriteString(typeComment(field, ps, "\t"))
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, t, tag)
+	}
+	b.WriteString("}\n")
+	g.decls[i] = b.String()
+}
+
+// pointerable returns true if the Go type should be a pointer when
+// optional (not a slice, map, or any).
+func pointerable(t string) bool {
+	return !strings.HasPrefix(t, `[]`) && !strings.HasPrefix(t, `map[`) && t != `any`
+}
+
+// typeComment returns the description of the schema (if any) as a Go
+// comment for the type (or field if indented).
+func typeComment(name string, s map[string]any, indent string) string {
+	desc, _ := s[`description`].(string)
+	desc = strings.Join(strings.Fields(desc), ` `)
+	switch {
+	case desc == ``:
+		return ``
+	case indent == ``:
+		desc = name + ` is ` + lowerFirst(desc)
+	}
+	return indent + `// ` + desc + "\n"
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 1 && unicode.IsUpper(r[0]) && !unicode.IsUpper(r[1]) {
+		r[0] = unicode.ToLower(r[0])
+	}
+	return string(r)
+}
+
+// goInitialisms are the words written in all capitals in Go names.
+var goInitialisms = map[string]bool{
+	`api`: true, `cpu`: true, `dns`: true, `html`: true, `http`: true,
+	`https`: true, `id`: true, `ip`: true, `json`: true, `sql`: true,
+	`tls`: true, `ttl`: true, `uri`: true, `url`: true, `uuid`: true,
+	`xml`: true,
+}
+
+// goName returns the exported Go name for the JSON name (for example,
+// user_id and user-id are both UserID).
+func goName(s string) string {
+	var words []string
+	var w []rune
+	flush := func() {
+		if len(w) > 0 {
+			words = append(words, string(w))
+			w = nil
+		}
+	}
+	prev := rune(0)
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && prev != 0 && unicode.IsLower(prev):
+			flush()
+			w = append(w, r)
+		default:
+			w = append(w, r)
+		}
+		prev = r
+	}
+	flush()
+	var b strings.Builder
+	for _, w := range words {
+		if goInitialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	n := b.String()
+	if n == `` || !unicode.IsLetter([]rune(n)[0]) {
+		n = `X` + n
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleGenerateTypes() {
+	schema := []byte(`{
+	  "description": "A user of the system.",
+	  "type": "object",
+	  "required": ["id", "name", "role", "created"],
+	  "properties": {
+	    "id":      {"type": "integer", "format": "int64"},
+	    "name":    {"type": "string", "description": "Full name."},
+	    "email":   {"type": "string", "nullable": true},
+	    "role":    {"enum": ["admin", "read-only"]},
+	    "created": {"type": "string", "format": "date-time"},
+	    "tags":    {"type": "array", "items": {"type": "string"}},
+	    "address": {
+	      "type": "object",
+	      "properties": {"city": {"type": "string"}}
+	    },
+	    "team":    {"$ref": "#/$defs/team"},
+	    "labels":  {"type": "object", "additionalProperties": {"type": "string"}}
+	  },
+	  "$defs": {
+	    "team": {
+	      "type": "object",
+	      "required": ["team_id"],
+	      "properties": {
+	        "team_id": {"type": "string", "format": "uuid"},
+	        "parent":  {"$ref": "#/$defs/team"}
+	      }
+	    }
+	  }
+	}`)
+
+	src, err := json.GenerateTypes(`api`, `User`, schema)
+	fmt.Println(string(src), err)
+
+	// Output:
+	// // Code generated by json.GenerateTypes; DO NOT EDIT.
+	//
+	// package api
+	//
+	// import (
+	// 	"github.com/google/uuid"
+	// 	"time"
+	// )
+	//
+	// // User is a user of the system.
+	// type User struct {
+	// 	ID int64 `json:"id"`
+	// 	// Full name.
+	// 	Name    string            `json:"name"`
+	// 	Email   *string           `json:"email,omitempty"`
+	// 	Role    UserRole          `json:"role"`
+	// 	Created time.Time         `json:"created"`
+	// 	Tags    []string          `json:"tags,omitempty"`
+	// 	Address *UserAddress      `json:"address,omitempty"`
+	// 	Team    *Team             `json:"team,omitempty"`
+	// 	Labels  map[string]string `json:"labels,omitempty"`
+	// }
+	//
+	// type UserRole string
+	//
+	// // Values of UserRole.
+	// const (
+	// 	UserRoleAdmin    UserRole = "admin"
+	// 	UserRoleReadOnly UserRole = "read-only"
+	// )
+	//
+	// type UserAddress struct {
+	// 	City *string `json:"city,omitempty"`
+	// }
+	//
+	// type Team struct {
+	// 	TeamID uuid.UUID `json:"team_id"`
+	// 	Parent *Team     `json:"parent,omitempty"`
+	// }
+	//  <nil>
+}
@@ -0,0 +1,258 @@
+package json
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnifiedOptions are the options for DiffUnified. The zero value has
+// 3 lines of context and no color.
+type UnifiedOptions struct {
+	Context int  // unchanged lines around changes (0 is 3, negative none)
+	Color   bool // ANSI terminal colors (red removed, green added)
+}
+
+const (
+	unifiedDel   = "\033[31m"
+	unifiedAdd   = "\033[32m"
+	unifiedHead  = "\033[36m"
+	unifiedReset = "\033[0m"
+)
+
+// DiffUnified returns a unified-style rendering of the differences
+// between two JSON documents for reviewing changes (to configuration,
+// for example) in a terminal. Both documents are indented (two spaces,
+// keys sorted, so key order never matters) and compared line by line
+// (ignoring trailing commas) with every group of changes (a hunk)
+// shown with lines of unchanged context (see UnifiedOptions) under a
+// header with the Path of the value containing every change of the
+// group:
+//
+//     @@ .spec @@
+//        "name": "web",
+//     -  "replicas": 2,
+//     +  "replicas": 3,
+//        "tags": [
+//
+// Identical documents return an empty string.
+func DiffUnified(a, b []byte, opt UnifiedOptions) (string, error) {
+	var av, bv any
+	if err := Unmarshal(a, &av); err != nil {
+		return "", err
+	}
+	if err := Unmarshal(b, &bv); err != nil {
+		return "", err
+	}
+	al := unifiedLines(av, nil, ``, 0, false, nil)
+	bl := unifiedLines(bv, nil, ``, 0, false, nil)
+	ops := lineDiff(al, bl)
+
+	ctx := opt.Context
+	switch {
+	case ctx == 0:
+		ctx = 3
+	case ctx < 0:
+		ctx = 0
+	}
+	color := func(c, s string) string {
+		if !opt.Color {
+			return s
+		}
+		return c + s + unifiedReset
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// the hunk runs until more than twice the context is unchanged
+		start := i - ctx
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for n := i; n < len(ops); n++ {
+			if ops[n].kind != ' ' {
+				end = n + 1
+				continue
+			}
+			if n-end >= 2*ctx {
+				break
+			}
+		}
+		stop := end + ctx
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		var common Path
+		first := true
+		for _, op := range ops[start:end] {
+			if op.kind == ' ' {
+				continue
+			}
+			if first {
+				common, first = op.line.path, false
+				continue
+			}
+			common = commonPath(common, op.line.path)
+		}
+		out.WriteString(color(unifiedHead, `@@ `+common.String()+` @@`) + "\n")
+		for _, op := range ops[start:stop] {
+			switch op.kind {
+			case '-':
+				out.WriteString(color(unifiedDel, `-`+op.line.text) + "\n")
+			case '+':
+				out.WriteString(color(unifiedAdd, `+`+op.line.text) + "\n")
+			default:
+				out.WriteString(` ` + op.line.text + "\n")
+			}
+		}
+		i = stop
+	}
+	return out.String(), nil
+}
+
+// unifiedLine is a single line of an indented document with the Path of
+// the value it belongs to.
+type unifiedLine struct {
+	text string
+	path Path
+}
+
+// key returns the text compared by lineDiff.
+func (l unifiedLine) key() string { return strings.TrimSuffix(l.text, `,`) }
+
+// unifiedLines appends the indented lines of the value (in generic
+// form) with the prefix (a quoted key and colon, if any).
+func unifiedLines(v any, p Path, prefix string, depth int, comma bool, out []unifiedLine) []unifiedLine {
+	indent := strings.Repeat(`  `, depth)
+	end := ``
+	if comma {
+		end = `,`
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			break
+		}
+		out = append(out, unifiedLine{indent + prefix + `{`, p})
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			out = unifiedLines(t[k], p.Key(k), quote(k)+`: `, depth+1, i < len(keys)-1, out)
+		}
+		return append(out, unifiedLine{indent + `}` + end, p})
+	case []any:
+		if len(t) == 0 {
+			break
+		}
+		out = append(out, unifiedLine{indent + prefix + `[`, p})
+		for i, item := range t {
+			out = unifiedLines(item, p.Index(i), ``, depth+1, i < len(t)-1, out)
+		}
+		return append(out, unifiedLine{indent + `]` + end, p})
+	}
+	return append(out, unifiedLine{indent + prefix + This{v}.String() + end, p})
+}
+
+// commonPath returns the longest beginning shared by both paths.
+func commonPath(a, b Path) Path {
+	n := 0
+	for n < len(a) && n < len(b) && reflect.DeepEqual(a[n], b[n]) {
+		n++
+	}
+	return a[:n]
+}
+
+// lineOp is a single line of a line diff: unchanged (space), removed
+// (-), or added (+).
+type lineOp struct {
+	kind byte
+	line unifiedLine
+}
+
+// lineDiff returns the shortest edit from the lines of a to those of b
+// (Myers' algorithm) after skipping their common beginning and end.
+// Unchanged lines are those of b.
+func lineDiff(a, b []unifiedLine) []lineOp {
+	var ops []lineOp
+	p := 0
+	for p < len(a) && p < len(b) && a[p].key() == b[p].key() {
+		ops = append(ops, lineOp{' ', b[p]})
+		p++
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s].key() == b[len(b)-1-s].key() {
+		s++
+	}
+	ma, mb := a[p:len(a)-s], b[p:len(b)-s]
+
+	n, m := len(ma), len(mb)
+	off := n + m + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+	var middle []lineOp
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && ma[x].key() == mb[y].key() {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var pk int
+		if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+		px := v[off+pk]
+		py := px - pk
+		for x > px && y > py {
+			middle = append(middle, lineOp{' ', mb[y-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == px {
+			middle = append(middle, lineOp{'+', mb[y-1]})
+			y--
+		} else {
+			middle = append(middle, lineOp{'-', ma[x-1]})
+			x--
+		}
+	}
+	for i := len(middle) - 1; i >= 0; i-- {
+		ops = append(ops, middle[i])
+	}
+	for _, l := range b[len(b)-s:] {
+		ops = append(ops, lineOp{' ', l})
+	}
+	return ops
+}
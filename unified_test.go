@@ -0,0 +1,32 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleDiffUnified() {
+	v1 := []byte(`{"name":"web","spec":{"replicas":2,"image":"web:1.0","ports":[80,443]},"labels":{"a":"1","b":"2","c":"3","d":"4","e":"5"}}`)
+	v2 := []byte(`{"name":"web","spec":{"replicas":3,"image":"web:1.0","ports":[80,443]},"labels":{"a":"1","b":"2","c":"3","d":"4","e":"5","f":"6"}}`)
+
+	out, err := json.DiffUnified(v1, v2, json.UnifiedOptions{Context: 1})
+	fmt.Print(out)
+	fmt.Println(err)
+
+	out, _ = json.DiffUnified(v1, v1, json.UnifiedOptions{})
+	fmt.Printf("%q\n", out)
+
+	// Output:
+	// @@ .labels.f @@
+	//      "e": "5",
+	// +    "f": "6"
+	//    },
+	// @@ .spec.replicas @@
+	//      ],
+	// -    "replicas": 2
+	// +    "replicas": 3
+	//    }
+	// <nil>
+	// ""
+}
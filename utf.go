@@ -0,0 +1,309 @@
+package json
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// BOM is the UTF-8 byte order mark that many Windows tools add to the
+// beginning of text files (including JSON).
+var BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// WithBOM returns the JSON with a UTF-8 BOM prepended (if it does not
+// already have one) for those Windows tools that still require it.
+func WithBOM(buf []byte) []byte {
+	if bytes.HasPrefix(buf, BOM) {
+		return buf
+	}
+	return append(append(make([]byte, 0, len(buf)+3), BOM...), buf...)
+}
+
+// ToUTF8 detects the encoding of the JSON passed, removes any byte
+// order mark (BOM), and converts UTF-16 and UTF-32 (big or little
+// endian, with or without a BOM) to UTF-8 using the detection from the
+// original RFC 4627 (since the first two characters of JSON text are
+// always ASCII). Plain UTF-8 is returned untouched (without copying).
+// Unmarshal calls this on all input.
+func ToUTF8(buf []byte) ([]byte, error) {
+	if len(buf) < 2 {
+		return buf, nil
+	}
+	switch {
+	case bytes.HasPrefix(buf, BOM):
+		return buf[3:], nil
+	case bytes.HasPrefix(buf, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return fromUTF32(buf[4:], binary.BigEndian)
+	case bytes.HasPrefix(buf, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return fromUTF32(buf[4:], binary.LittleEndian)
+	case bytes.HasPrefix(buf, []byte{0xFE, 0xFF}):
+		return fromUTF16(buf[2:], binary.BigEndian)
+	case bytes.HasPrefix(buf, []byte{0xFF, 0xFE}):
+		return fromUTF16(buf[2:], binary.LittleEndian)
+	case buf[0] != 0 && buf[1] != 0:
+		return buf, nil
+	case len(buf) >= 4 && buf[0] == 0 && buf[1] == 0 && buf[2] == 0:
+		return fromUTF32(buf, binary.BigEndian)
+	case len(buf) >= 4 && buf[1] == 0 && buf[2] == 0 && buf[3] == 0:
+		return fromUTF32(buf, binary.LittleEndian)
+	case buf[0] == 0:
+		return fromUTF16(buf, binary.BigEndian)
+	}
+	return fromUTF16(buf, binary.LittleEndian)
+}
+
+func fromUTF16(buf []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(buf)%2 != 0 {
+		return nil, fmt.Errorf(`invalid UTF-16: odd number of bytes`)
+	}
+	units := make([]uint16, len(buf)/2)
+	for i := range units {
+		units[i] = order.Uint16(buf[i*2:])
+	}
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out, nil
+}
+
+func fromUTF32(buf []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf(`invalid UTF-32: length not a multiple of 4`)
+	}
+	out := make([]byte, 0, len(buf)/4)
+	for i := 0; i < len(buf); i += 4 {
+		r := rune(order.Uint32(buf[i:]))
+		if !utf8.ValidRune(r) {
+			return nil, fmt.Errorf(`invalid UTF-32: %U`, r)
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	return out, nil
+}
+
+// UTF8Policy determines how invalid UTF-8 in strings is handled by
+// Marshal and Unmarshal (see InvalidUTF8).
+type UTF8Policy int
+
+const (
+	UTF8Replace UTF8Policy = iota // replace with U+FFFD (encoding/json)
+	UTF8Error                     // fail with an InvalidUTF8Error
+	UTF8Raw                       // pass the invalid bytes through (see RawRangeError)
+)
+
+// InvalidUTF8 is the package-wide policy for handling invalid UTF-8 in
+// strings when marshaling and unmarshaling. The default replaces each
+// invalid byte with the U+FFFD replacement character (just like
+// encoding/json) but data pipelines can deliberately choose failure
+// (UTF8Error) or to pass the raw bytes through untouched (UTF8Raw),
+// which is not valid JSON but preserves the original data exactly.
+// Note that the other policies marshal with the internal encoder (not
+// the Engine), are not applied to anything rendering itself
+// (MarshalJSON, MarshalText), and are ignored by MarshalHTMLSafe.
+var InvalidUTF8 = UTF8Replace
+
+// InvalidUTF8Error is returned when the UTF8Error policy is in effect
+// and invalid UTF-8 is encountered.
+type InvalidUTF8Error struct {
+	Offset int // byte offset into the JSON
+}
+
+// Error fulfills the error interface.
+func (e InvalidUTF8Error) Error() string {
+	return fmt.Sprintf(`invalid UTF-8 at offset %d`, e.Offset)
+}
+
+// invalidAt returns the byte index of the first invalid UTF-8 byte in
+// the string or -1 if there is none.
+func invalidAt(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// checkValid returns an InvalidUTF8Error if the JSON input is not
+// entirely valid UTF-8.
+func checkValid(buf []byte) error {
+	for i := 0; i < len(buf); {
+		r, size := utf8.DecodeRune(buf[i:])
+		if r == utf8.RuneError && size == 1 {
+			return InvalidUTF8Error{i}
+		}
+		i += size
+	}
+	return nil
+}
+
+// quoteRaw is the same as quote but passes any invalid UTF-8 bytes
+// through untouched.
+func quoteRaw(s string) string {
+	if utf8.ValidString(s) {
+		return quote(s)
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			q := quote(s[start:i])
+			b.WriteString(q[1 : len(q)-1])
+			b.WriteByte(s[i])
+			start = i + 1
+		}
+		i += size
+	}
+	q := quote(s[start:])
+	b.WriteString(q[1 : len(q)-1])
+	b.WriteByte('"')
+	return b.String()
+}
+
+// rawBase is the start of the private use runes that invalid bytes are
+// temporarily mapped to so they survive decoding with UTF8Raw. Input
+// with invalid bytes that also has any of these runes (literally or
+// escaped) is rejected (see rawRangeAt) since they could not be told
+// apart from the mapped bytes.
+const rawBase = 0x10FF00
+
+// RawRangeError is returned when the UTF8Raw policy is in effect and
+// JSON with invalid UTF-8 also has a rune from U+10FF00 to U+10FFFF
+// (which are reserved for passing the invalid bytes through).
+type RawRangeError struct {
+	Offset int // byte offset into the JSON
+}
+
+// Error fulfills the error interface.
+func (e RawRangeError) Error() string {
+	return fmt.Sprintf(`rune reserved for invalid UTF-8 (U+10FF00 to U+10FFFF) at offset %d`, e.Offset)
+}
+
+// rawRangeAt returns the byte index of the first rune of the JSON
+// within the range of rawBase (literal or as an escaped surrogate
+// pair) or -1 if there is none.
+func rawRangeAt(buf []byte) int {
+	for i := 0; i < len(buf); {
+		if buf[i] == '\\' && i+1 < len(buf) {
+			if buf[i+1] == 'u' && i+12 <= len(buf) && buf[i+6] == '\\' && buf[i+7] == 'u' {
+				hi, err1 := strconv.ParseUint(string(buf[i+2:i+6]), 16, 16)
+				lo, err2 := strconv.ParseUint(string(buf[i+8:i+12]), 16, 16)
+				if err1 == nil && err2 == nil && hi == 0xDBFF && lo >= 0xDF00 && lo <= 0xDFFF {
+					return i
+				}
+			}
+			i += 2
+			continue
+		}
+		r, size := utf8.DecodeRune(buf[i:])
+		if rawBase <= r && r <= rawBase+0xFF {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// escapeRaw maps every invalid UTF-8 byte to a private use rune (see
+// rawBase) returning nil if there were none.
+func escapeRaw(buf []byte) []byte {
+	if utf8.Valid(buf) {
+		return nil
+	}
+	out := make([]byte, 0, len(buf)+8)
+	for i := 0; i < len(buf); {
+		r, size := utf8.DecodeRune(buf[i:])
+		if r == utf8.RuneError && size == 1 {
+			out = utf8.AppendRune(out, rawBase+rune(buf[i]))
+		} else {
+			out = append(out, buf[i:i+size]...)
+		}
+		i += size
+	}
+	return out
+}
+
+// unescapeRawString reverses escapeRaw for a single decoded string.
+func unescapeRawString(s string) string {
+	isRaw := func(r rune) bool { return rawBase <= r && r <= rawBase+0xFF }
+	if strings.IndexFunc(s, isRaw) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if isRaw(r) {
+			b.WriteByte(byte(r - rawBase))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unescapeRaw walks the decoded value reversing escapeRaw for every
+// string found within it.
+func unescapeRaw(v reflect.Value) {
+	switch v.Kind() {
+
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(unescapeRawString(v.String()))
+		}
+
+	case reflect.Pointer:
+		if !v.IsNil() {
+			unescapeRaw(v.Elem())
+		}
+
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		c := reflect.New(v.Elem().Type()).Elem()
+		c.Set(v.Elem())
+		unescapeRaw(c)
+		v.Set(c)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			unescapeRaw(v.Index(i))
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				unescapeRaw(v.Field(i))
+			}
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		for _, k := range v.MapKeys() {
+			val := reflect.New(v.Type().Elem()).Elem()
+			val.Set(v.MapIndex(k))
+			unescapeRaw(val)
+			nk := k
+			if k.Kind() == reflect.String {
+				nk = reflect.New(k.Type()).Elem()
+				nk.SetString(unescapeRawString(k.String()))
+				if nk.String() != k.String() {
+					v.SetMapIndex(k, reflect.Value{})
+				}
+			}
+			v.SetMapIndex(nk, val)
+		}
+
+	}
+}
@@ -0,0 +1,92 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleToUTF8() {
+	for _, in := range [][]byte{
+		[]byte("\xEF\xBB\xBF{\"a\":\"д\"}"),
+		{0xFE, 0xFF, 0, '[', 0, '1', 0, ']'},
+		{'[', 0, '2', 0, ']', 0},
+		{0, 0, 0, '3'},
+		{'"', 0, 0, 0, 0x3B, 0x04, 0, 0, '"', 0, 0, 0},
+	} {
+		buf, err := json.ToUTF8(in)
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(string(buf))
+	}
+
+	// Output:
+	// {"a":"д"}
+	// [1]
+	// [2]
+	// 3
+	// "л"
+}
+
+func ExampleUnmarshal_bom() {
+	var m map[string]string
+	err := json.Unmarshal([]byte("\xEF\xBB\xBF{\"foo\":\"bar\"}"), &m)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(m)
+
+	// Output:
+	// map[foo:bar]
+}
+
+func ExampleWithBOM() {
+	fmt.Printf("%q\n", json.WithBOM([]byte(`{}`)))
+	fmt.Printf("%q\n", json.WithBOM(json.WithBOM([]byte(`{}`))))
+
+	// Output:
+	// "\ufeff{}"
+	// "\ufeff{}"
+}
+
+func ExampleInvalidUTF8() {
+	defer func() { json.InvalidUTF8 = json.UTF8Replace }()
+	bad := "a\xffb"
+
+	json.InvalidUTF8 = json.UTF8Replace
+	buf, _ := json.Marshal(bad)
+	var back string
+	json.Unmarshal(buf, &back)
+	fmt.Printf("%+q\n", back)
+
+	json.InvalidUTF8 = json.UTF8Error
+	_, err := json.Marshal(bad)
+	fmt.Println(err)
+	var s string
+	err = json.Unmarshal([]byte("\"a\xffb\""), &s)
+	fmt.Println(err)
+
+	json.InvalidUTF8 = json.UTF8Raw
+	buf, _ = json.Marshal(map[string]string{"k": bad})
+	fmt.Printf("%q\n", buf)
+	var m map[string]string
+	json.Unmarshal(buf, &m)
+	fmt.Println(m["k"] == bad)
+	err = json.Unmarshal([]byte("\"\xff\U0010FF41\""), &s)
+	fmt.Println(err)
+	err = json.Unmarshal([]byte(`"\udbff\udf41`+"\xff\""), &s)
+	fmt.Println(err)
+	err = json.Unmarshal([]byte("\"\U0010FF41\""), &s)
+	fmt.Printf("%+q %v\n", s, err)
+
+	// Output:
+	// "a\ufffdb"
+	// invalid UTF-8 at offset 2
+	// invalid UTF-8 at offset 2
+	// "{\"k\":\"a\xffb\"}"
+	// true
+	// rune reserved for invalid UTF-8 (U+10FF00 to U+10FFFF) at offset 2
+	// rune reserved for invalid UTF-8 (U+10FF00 to U+10FFFF) at offset 1
+	// "\U0010ff41" <nil>
+}
@@ -0,0 +1,110 @@
+package json
+
+// Value is an immutable (persistent) JSON value held in generic form.
+// Set and Delete never change the Value but return a new version that
+// shares every unchanged subtree with the original (only the objects
+// and arrays along the path are copied) making snapshots and undo
+// stacks cheap:
+//
+//     v1, _ := json.NewValue(state)
+//     v2, _ := v1.Set(`.items[0].done`, true)
+//     history := []json.Value{v1, v2}
+//
+// The zero value is null.
+type Value struct {
+	root any
+}
+
+// NewValue returns a new Value from any value that can be marshaled
+// into JSON (the value is converted into generic form).
+func NewValue(v any) (Value, error) {
+	root, err := generic(v)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{root}, nil
+}
+
+// Any returns the entire value in generic form. It must not be
+// modified since it is shared with other versions.
+func (v Value) Any() any { return v.root }
+
+// MarshalJSON fulfills the encoding/json.Marshaler interface.
+func (v Value) MarshalJSON() ([]byte, error) { return Marshal(v.root) }
+
+// String fulfills the fmt.Stringer interface as compact JSON.
+func (v Value) String() string { return This{v.root}.String() }
+
+// Get returns the single value at the path (see Document.Get). It must
+// not be modified.
+func (v Value) Get(path string) (any, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return getIn(v.root, p)
+}
+
+// Set returns a new version with the value at the path set (see
+// Document.Set).
+func (v Value) Set(path string, x any) (Value, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return v, err
+	}
+	val, err := generic(x)
+	if err != nil {
+		return v, err
+	}
+	root, _, err := setIn(clonePath(v.root, p), p, val, &Path{})
+	if err != nil {
+		return v, err
+	}
+	return Value{root}, nil
+}
+
+// Delete returns a new version with the value at the path removed
+// (see Document.Delete).
+func (v Value) Delete(path string) (Value, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return v, err
+	}
+	root, err := deleteIn(clonePath(v.root, p), p, &Path{})
+	if err != nil {
+		return v, err
+	}
+	return Value{root}, nil
+}
+
+// clonePath returns a shallow copy of every object and array along the
+// path (but not the value at the end of it) so that it can be changed
+// in place without changing cur. Arrays are copied with no extra
+// capacity so appending to them never writes into the original.
+func clonePath(cur any, p Path) any {
+	if len(p) == 0 {
+		return cur
+	}
+	step := p[0]
+	switch t := cur.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, c := range t {
+			m[k] = c
+		}
+		if c, has := m[step.Key]; has && !step.Each && step.Index == nil {
+			m[step.Key] = clonePath(c, p[1:])
+		}
+		return m
+	case []any:
+		a := make([]any, len(t))
+		copy(a, t)
+		if step.Index != nil {
+			if i := absIndex(*step.Index, len(a)); i >= 0 && i < len(a) {
+				a[i] = clonePath(a[i], p[1:])
+			}
+		}
+		return a
+	}
+	return cur
+}
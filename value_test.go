@@ -0,0 +1,30 @@
+package json_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+)
+
+func ExampleValue() {
+	v1, _ := json.NewValue(map[string]any{
+		"name":  "todo",
+		"items": []any{map[string]any{"done": false}},
+	})
+	v2, _ := v1.Set(`.items[0].done`, true)
+	v3, _ := v2.Set(`.items[1]`, map[string]any{"done": false})
+	v4, _ := v3.Delete(`.name`)
+	_, err := v4.Delete(`.missing`)
+
+	for _, v := range []json.Value{v1, v2, v3, v4} {
+		fmt.Println(v)
+	}
+	fmt.Println(err)
+
+	// Output:
+	// {"items":[{"done":false}],"name":"todo"}
+	// {"items":[{"done":true}],"name":"todo"}
+	// {"items":[{"done":true},{"done":false}],"name":"todo"}
+	// {"items":[{"done":true},{"done":false}]}
+	// no such key: .missing
+}
@@ -0,0 +1,145 @@
+/*
+Package yq provides the full yq engine (yqlib) for the queries of the
+rwxrob/json package. It is opt-in (from its own package) so that the
+huge transitive dependency tree of yq is never pulled into anything
+that does not import it:
+
+    json.DefaultQueryEngine = yq.Engine{}
+
+Sources may be YAML or JSON (one or more documents) and queries are
+any yq expression, including those that modify the (copied) documents.
+*/
+package yq
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	json "github.com/rwxrob/json"
+	logging "gopkg.in/op/go-logging.v1"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Engine is the json.QueryEngine using yqlib.
+type Engine struct{}
+
+var _ json.QueryEngine = Engine{}
+
+var quietQuery sync.Once
+
+// quiet silences the (very) noisy default logging of yqlib.
+func quiet() {
+	quietQuery.Do(func() {
+		be := logging.AddModuleLevel(logging.NewLogBackend(os.Stderr, "", 0))
+		be.SetLevel(logging.ERROR, "")
+		logging.SetBackend(be)
+	})
+}
+
+// Parse implements json.QueryEngine by parsing all the YAML (or JSON)
+// documents in the source.
+func (Engine) Parse(src string) (any, error) {
+	dec := yqlib.NewYamlDecoder()
+	dec.Init(strings.NewReader(src))
+	docs := list.New()
+	for i := uint(0); ; i++ {
+		node := new(yaml.Node)
+		err := dec.Decode(node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs.PushBack(&yqlib.CandidateNode{
+			Document:         i,
+			Node:             node,
+			EvaluateTogether: true,
+		})
+	}
+}
+
+// Query implements json.QueryEngine for yq expressions.
+func (Engine) Query(parsed any, q string, o json.QueryOptions) (string, error) {
+	quiet()
+	docs, err := copyDocs(parsed.(*list.List))
+	if err != nil {
+		return "", err
+	}
+	res, err := yqlib.NewAllAtOnceEvaluator().EvaluateCandidateNodes(q, docs)
+	if err != nil {
+		return "", err
+	}
+	if o.Format == json.QueryYAML {
+		for e := res.Front(); e != nil; e = e.Next() {
+			restyle(e.Value.(*yqlib.CandidateNode).Node, o.Quote)
+		}
+	}
+	buf := new(bytes.Buffer)
+	pr := yqlib.NewPrinter(encoder(o), yqlib.NewSinglePrinterWriter(buf))
+	if err := pr.PrintResults(res); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// copyDocs returns a copy of the parsed (and cached) documents since yq
+// queries may modify them.
+func copyDocs(docs *list.List) (*list.List, error) {
+	copies := list.New()
+	for e := docs.Front(); e != nil; e = e.Next() {
+		c, err := e.Value.(*yqlib.CandidateNode).Copy()
+		if err != nil {
+			return nil, err
+		}
+		copies.PushBack(c)
+	}
+	return copies, nil
+}
+
+// rawScalars is a yqlib.Encoder that writes scalars as they are.
+type rawScalars struct{ yqlib.Encoder }
+
+// Encode implements yqlib.Encoder.
+func (e rawScalars) Encode(w io.Writer, node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		_, err := io.WriteString(w, node.Value+"\n")
+		return err
+	}
+	return e.Encoder.Encode(w, node)
+}
+
+// encoder returns the yqlib.Encoder matching the options.
+func encoder(o json.QueryOptions) yqlib.Encoder {
+	switch o.Format {
+	case json.QueryJSON:
+		return yqlib.NewJONEncoder(o.Indent)
+	case json.QueryRaw:
+		return rawScalars{yqlib.NewJONEncoder(o.Indent)}
+	}
+	indent := o.Indent
+	if indent == 0 {
+		indent = 2
+	}
+	return yqlib.NewYamlEncoder(indent, false, true, !o.Quote)
+}
+
+// restyle resets the style of the node (which keeps the flow style of
+// any JSON source) so that YAML output is always block style (as with
+// json.ToYAML) with string values quoted only if needed (or always if
+// quote).
+func restyle(node *yaml.Node, quote bool) {
+	node.Style = 0
+	if quote && node.Kind == yaml.ScalarNode && node.ShortTag() == `!!str` {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	for i, n := range node.Content {
+		restyle(n, quote && (node.Kind != yaml.MappingNode || i%2 == 1))
+	}
+}
@@ -0,0 +1,106 @@
+package yq_test
+
+import (
+	"fmt"
+
+	json "github.com/rwxrob/json"
+	"github.com/rwxrob/json/yq"
+)
+
+func ExampleEngine() {
+	json.DefaultQueryEngine = yq.Engine{}
+
+	this := json.This{This: map[string]any{"name": "foo", "tags": []string{"a", "b"}}}
+
+	out, err := this.Query(".name")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(out)
+
+	out, err = this.Query(".tags[1]")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(out)
+
+	// strings are assumed to be YAML/JSON documents
+	this.This = `{"name":"bar"}`
+	if err := this.QueryPrint(".name"); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// foo
+	// b
+	// bar
+}
+
+func ExampleEngine_modifying() {
+	json.DefaultQueryEngine = yq.Engine{}
+
+	this := json.This{This: `{"count":1}`}
+
+	// changes are never made to the (cached) document itself
+	fmt.Print(this.MustQuery(".count = 2 | .count"))
+	fmt.Print(this.MustQuery(".count"))
+
+	// Output:
+	// 2
+	// 1
+}
+
+func ExampleEngine_options() {
+	json.DefaultQueryEngine = yq.Engine{}
+
+	this := json.This{This: `{"name":"foo","tags":["a","b"],"n":{"x":1}}`}
+
+	out, _ := this.QueryWith(".tags", json.QueryOptions{Format: json.QueryJSON})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".n", json.QueryOptions{
+		Format: json.QueryJSON,
+		Indent: 2,
+	})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".name", json.QueryOptions{Format: json.QueryJSON})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".name", json.QueryOptions{Format: json.QueryRaw})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".name", json.QueryOptions{Quote: true})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".n", json.QueryOptions{Indent: 4})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".", json.QueryOptions{})
+	fmt.Print(out)
+
+	out, _ = this.QueryWith(".", json.QueryOptions{Quote: true})
+	fmt.Print(out)
+
+	// Output:
+	// ["a","b"]
+	// {
+	//   "x": 1
+	// }
+	// "foo"
+	// foo
+	// "foo"
+	// x: 1
+	// name: foo
+	// tags:
+	//   - a
+	//   - b
+	// n:
+	//   x: 1
+	// name: "foo"
+	// tags:
+	//   - "a"
+	//   - "b"
+	// n:
+	//   x: 1
+}